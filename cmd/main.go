@@ -2,77 +2,361 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hisshihi/simple-bank/db/migration"
 	"github.com/hisshihi/simple-bank/db/sqlc"
 	_ "github.com/hisshihi/simple-bank/doc/statik"
 	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/event"
+	"github.com/hisshihi/simple-bank/internal/health"
+	"github.com/hisshihi/simple-bank/internal/i18n"
+	"github.com/hisshihi/simple-bank/internal/logging"
+	"github.com/hisshihi/simple-bank/internal/metrics"
+	"github.com/hisshihi/simple-bank/internal/ratelimit"
 	"github.com/hisshihi/simple-bank/internal/service/api"
 	"github.com/hisshihi/simple-bank/internal/service/gapi"
+	"github.com/hisshihi/simple-bank/internal/service/worker"
+	"github.com/hisshihi/simple-bank/internal/task"
+	"github.com/hisshihi/simple-bank/internal/tracing"
+	"github.com/hisshihi/simple-bank/internal/watch"
 	"github.com/hisshihi/simple-bank/pb"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rakyll/statik/fs"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+const defaultShutdownTimeout = 30 * time.Second
+
 func main() {
-	config, err := config.LoadConfig()
+	config, err := config.LoadConfig("")
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+
+	// go run ./cmd config print — итоговая конфигурация без запуска серверов
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		runConfigCommand(config, os.Args[2:])
+		return
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatal("invalid config:\n", err)
+	}
+
+	logger, err := logging.New(config, os.Stderr)
+	if err != nil {
+		log.Fatal("cannot create logger: ", err)
+	}
+	// log.Printf из библиотек тоже идёт через этот логгер
+	slog.SetDefault(logger)
+
+	// go run cmd/main.go reconcile — разовая сверка без запуска серверов
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconciliation(config)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, config); err != nil {
+		fatal("server stopped with error", err)
+	}
+}
+
+// run starts the configured components and blocks until ctx is cancelled or one of them fails,
+// then stops the rest and closes the database pool
+func run(ctx context.Context, config config.Config) error {
+	components, err := config.EnabledComponents()
+	if err != nil {
+		return err
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, config)
 	if err != nil {
-		log.Fatal("cannot load config", err)
+		return fmt.Errorf("cannot set up tracing: %w", err)
 	}
+	defer func() {
+		// ctx уже отменён, оставшиеся спаны выгружаются со своим таймаутом
+		flushCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("cannot flush traces", "error", err)
+		}
+	}()
 
-	if config.ENV == "production" {
-		conn, err := sql.Open(config.DBDriver, config.DBSouceProd)
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		return fmt.Errorf("cannot connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	registry := metrics.NewRegistry(conn)
+	appMetrics := metrics.New(registry)
+	store := appMetrics.InstrumentStore(sqlc.NewStore(conn, sqlc.WithRetryObserver(appMetrics.TxRetried)))
+
+	limiter, err := ratelimit.Setup(config, store)
+	if err != nil {
+		return fmt.Errorf("cannot set up rate limiting: %w", err)
+	}
+
+	checker, err := newHealthChecker(conn)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	group, ctx := errgroup.WithContext(ctx)
+
+	// компонент, который не смог запуститься, останавливает уже запущенные
+	fail := func(err error) error {
+		cancel()
+		group.Wait()
+		return err
+	}
+
+	// серверы останавливаются только после того, как readiness переключится в not serving
+	// и балансировщик успеет перестать слать им запросы
+	serveCtx, stopServing := context.WithCancel(context.Background())
+	defer stopServing()
+	group.Go(func() error {
+		checker.Run(ctx)
+		checker.Shutdown()
+		slog.Info("readiness is not serving, stopping servers after the drain delay", "drain_delay", config.ShutdownDrainDelay)
+
+		time.Sleep(config.ShutdownDrainDelay)
+		stopServing()
+		return nil
+	})
+
+	if components.Workers {
+		// воркеры останавливаются вместе с ctx, незавершённая транзакция откатывается и повторится при следующем запуске
+		runWorkers(ctx, group, config, store, checker, registry)
+	}
+	if components.MetricsServer() {
+		if err := runMetricsServer(serveCtx, group, config, checker, registry); err != nil {
+			return fail(err)
+		}
+	}
+
+	var hub *watch.Hub
+	if components.GRPC || components.Gateway {
+		hub = watch.NewHub()
+		group.Go(func() error {
+			slog.Info("start listening for account changes")
+			return hub.Listen(ctx, config.DBSource)
+		})
+	}
+
+	if components.GRPC {
+		if err := runGrpcServer(serveCtx, group, config, store, hub, checker, appMetrics, limiter); err != nil {
+			return fail(err)
+		}
+	}
+	if components.Gateway {
+		if err := runGatewayServer(serveCtx, group, config, store, hub, checker, appMetrics, limiter, registry); err != nil {
+			return fail(err)
+		}
+	}
+	if components.Gin {
+		if err := runGinServer(serveCtx, group, config, store, checker, appMetrics, limiter, registry); err != nil {
+			return fail(err)
+		}
+	}
+
+	err = group.Wait()
+	slog.Info("all components stopped")
+	return err
+}
+
+// newHealthChecker checks the database and that it is migrated to the schema the binary was built with
+func newHealthChecker(conn *sql.DB) (*health.Checker, error) {
+	latest, err := migration.Latest()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read embedded migrations: %w", err)
+	}
+
+	checker := health.NewChecker(pb.SimpleBank_ServiceDesc.ServiceName)
+	checker.AddCheck("database", health.DatabaseCheck(conn))
+	checker.AddCheck("migrations", health.MigrationCheck(conn, latest))
+	return checker, nil
+}
+
+func runWorkers(ctx context.Context, group *errgroup.Group, config config.Config, store sqlc.Store, checker *health.Checker, registry prometheus.Registerer) {
+	group.Go(func() error {
+		slog.Info("start scheduled transfer worker", "interval", config.ScheduledTransferInterval)
+		worker.NewScheduledTransferRunner(config, store).Start(ctx)
+		return nil
+	})
+
+	group.Go(func() error {
+		slog.Info("start hold sweeper", "interval", config.HoldSweepInterval)
+		worker.NewHoldSweeper(config, store).Start(ctx)
+		return nil
+	})
+
+	group.Go(func() error {
+		slog.Info("start overdraft interest job", "interval", config.OverdraftInterestInterval)
+		worker.NewOverdraftInterestJob(config, store).Start(ctx)
+		return nil
+	})
+
+	group.Go(func() error {
+		slog.Info("start interest accrual job", "interval", config.InterestAccrualInterval)
+		worker.NewInterestAccrualJob(config, store).Start(ctx)
+		return nil
+	})
+
+	reconciler := worker.NewReconciler(config, store, worker.WithDiscrepancyObserver(metrics.ReconciliationObserver(registry)))
+	group.Go(func() error {
+		slog.Info("start ledger reconciliation", "interval", config.ReconciliationInterval)
+		reconciler.Start(ctx)
+		return nil
+	})
+
+	group.Go(func() error {
+		publisher, err := event.NewPublisher(config)
 		if err != nil {
-			log.Fatal("не удалось подключится к базе данных")
+			return fmt.Errorf("cannot create event publisher: %w", err)
 		}
 
-		store := sqlc.NewStore(conn)
-		runGrpcServer(config, store)
-	} else {
-		conn, err := sql.Open(config.DBDriver, config.DBSource)
+		slog.Info("start outbox relay", "interval", config.OutboxRelayInterval)
+		worker.NewOutboxRelay(config, store, publisher).Start(ctx)
+		return nil
+	})
+
+	group.Go(func() error {
+		slog.Info("start webhook dispatcher", "interval", config.WebhookDeliveryInterval)
+		worker.NewWebhookDispatcher(config, store).Start(ctx)
+		return nil
+	})
+
+	group.Go(func() error {
+		// каждый воркер сам регистрирует обработчики своих задач
+		tasks := task.NewRegistry()
+		reconciler.RegisterTasks(tasks)
+
+		pool, err := worker.NewTaskPool(config, store, tasks)
 		if err != nil {
-			log.Fatal("не удалось подключится к базе данных")
+			return fmt.Errorf("cannot create task pool: %w", err)
 		}
+		pool.ReportTo(checker.Component("task_pool"))
 
-		store := sqlc.NewStore(conn)
-		go runGetwayServer(config, store)
-		runGrpcServer(config, store)
-	}
+		slog.Info("start task pool", "queues", config.TaskQueues)
+		pool.Start(ctx)
+		return nil
+	})
 }
 
-func runGrpcServer(config config.Config, store sqlc.Store) {
-	server, err := gapi.NewServer(config, store)
+func runGrpcServer(ctx context.Context, group *errgroup.Group, config config.Config, store sqlc.Store, hub *watch.Hub, checker *health.Checker, appMetrics *metrics.Metrics, limiter *ratelimit.Limiter) error {
+	server, err := gapi.NewServer(config, store, hub)
 	if err != nil {
-		log.Fatal("cannot create server", err)
+		return fmt.Errorf("cannot create gRPC server: %w", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	tlsConfig, err := config.GRPCServerTLS()
+	if err != nil {
+		return err
+	}
+	clientIPs, err := config.ClientIPResolver()
+	if err != nil {
+		return err
+	}
+	options := []grpc.ServerOption{
+		// продолжает трассу из traceparent в метаданных вызова
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.ChainUnaryInterceptor(
+			appMetrics.UnaryServerInterceptor(),
+			logging.UnaryServerInterceptor(),
+			i18n.UnaryServerInterceptor(),
+			clientIPs.UnaryServerInterceptor(),
+			server.RateLimitUnaryInterceptor(limiter),
+		),
+		grpc.ChainStreamInterceptor(
+			appMetrics.StreamServerInterceptor(),
+			logging.StreamServerInterceptor(),
+			i18n.StreamServerInterceptor(),
+			clientIPs.StreamServerInterceptor(),
+			server.RateLimitStreamInterceptor(limiter),
+		),
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	grpcServer := grpc.NewServer(options...)
 	pb.RegisterSimpleBankServer(grpcServer, server)
+	healthpb.RegisterHealthServer(grpcServer, checker.GRPC())
 	reflection.Register(grpcServer)
 
 	listener, err := net.Listen("tcp", config.GRPCServerAddress)
 	if err != nil {
-		log.Fatal("cannot create listener", err)
+		return fmt.Errorf("cannot create gRPC listener: %w", err)
 	}
 
-	log.Printf("start gRPC server at %s", listener.Addr().String())
-	err = grpcServer.Serve(listener)
-	if err != nil {
-		log.Fatal("cannot start gRPC server", err)
-	}
+	group.Go(func() error {
+		slog.Info("start gRPC server", "address", listener.Addr().String(), "tls", tlsConfig != nil, "client_auth", config.GRPCClientCAFile != "")
+		if err := grpcServer.Serve(listener); err != nil {
+			return fmt.Errorf("gRPC server failed: %w", err)
+		}
+		return nil
+	})
+
+	group.Go(func() error {
+		<-ctx.Done()
+		slog.Info("stopping gRPC server")
+
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(config.ShutdownTimeout):
+			slog.Warn("gRPC server did not stop in time, closing connections", "timeout", config.ShutdownTimeout)
+			grpcServer.Stop()
+		}
+		return nil
+	})
+
+	return nil
 }
 
-func runGetwayServer(config config.Config, store sqlc.Store) {
-	server, err := gapi.NewServer(config, store)
+func runGatewayServer(ctx context.Context, group *errgroup.Group, config config.Config, store sqlc.Store, hub *watch.Hub, checker *health.Checker, appMetrics *metrics.Metrics, limiter *ratelimit.Limiter, gatherer prometheus.Gatherer) error {
+	server, err := gapi.NewServer(config, store, hub)
 	if err != nil {
-		log.Fatal("cannot create server", err)
+		return fmt.Errorf("cannot create gateway server: %w", err)
+	}
+	clientIPs, err := config.ClientIPResolver()
+	if err != nil {
+		return err
 	}
 
 	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
@@ -84,47 +368,155 @@ func runGetwayServer(config config.Config, store sqlc.Store) {
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(jsonOption)
-	ctx, cancel := context.WithCancel(context.Background())
-
-	defer cancel()
+	grpcMux := runtime.NewServeMux(
+		jsonOption,
+		runtime.WithMiddlewares(
+			appMetrics.GatewayMiddleware(),
+			logging.GatewayMiddleware(),
+			i18n.GatewayMiddleware(),
+			clientIPs.GatewayMiddleware(),
+			server.RateLimitGatewayMiddleware(limiter),
+		),
+		runtime.WithMetadata(tracing.GatewayMethodAnnotator),
+		runtime.WithErrorHandler(gapi.ProblemErrorHandler),
+	)
 
 	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
 	if err != nil {
-		log.Fatal("cannot register handler server", err)
+		return fmt.Errorf("cannot register handler server: %w", err)
+	}
+
+	err = grpcMux.HandlePath(http.MethodGet, "/v1/watch_account", server.WatchAccountSSE)
+	if err != nil {
+		return fmt.Errorf("cannot register watch account handler: %w", err)
 	}
 
 	mux := http.NewServeMux()
-	mux.Handle("/", grpcMux)
+	mux.Handle("/", otelhttp.NewHandler(gapi.CORSHandler(config, grpcMux), "gateway"))
+	mux.Handle("/healthz", checker.LiveHandler())
+	mux.Handle("/readyz", checker.ReadyHandler())
+	mux.Handle("/metrics", metrics.Handler(gatherer))
 
 	statikFS, err := fs.New()
 	if err != nil {
-		log.Fatal("cannot create statik fs:", err)
+		return fmt.Errorf("cannot create statik fs: %w", err)
 	}
 
 	swaggerHandler := http.StripPrefix("/swagger/", http.FileServer(statikFS))
 	mux.Handle("/swagger/", swaggerHandler)
 
-	listener, err := net.Listen("tcp", config.HTTPServerAddress)
+	return serveHTTP(ctx, group, "HTTP gateway", config, config.HTTPServerAddress, mux)
+}
+
+func runGinServer(ctx context.Context, group *errgroup.Group, config config.Config, store sqlc.Store, checker *health.Checker, appMetrics *metrics.Metrics, limiter *ratelimit.Limiter, gatherer prometheus.Gatherer) error {
+	server, err := api.NewServer(config, store, appMetrics, limiter)
 	if err != nil {
-		log.Fatal("cannot create listener", err)
+		return fmt.Errorf("cannot create Gin server: %w", err)
 	}
 
-	log.Printf("start HTTP server at %s", listener.Addr().String())
-	err = http.Serve(listener, mux)
+	mux := http.NewServeMux()
+	mux.Handle("/", server.Handler())
+	mux.Handle("/healthz", checker.LiveHandler())
+	mux.Handle("/readyz", checker.ReadyHandler())
+	mux.Handle("/metrics", metrics.Handler(gatherer))
+
+	return serveHTTP(ctx, group, "Gin", config, config.GinServerAddress, mux)
+}
+
+// runMetricsServer serves the metrics and probes of a process whose only component is the workers
+func runMetricsServer(ctx context.Context, group *errgroup.Group, config config.Config, checker *health.Checker, gatherer prometheus.Gatherer) error {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", checker.LiveHandler())
+	mux.Handle("/readyz", checker.ReadyHandler())
+	mux.Handle("/metrics", metrics.Handler(gatherer))
+
+	return serveHTTP(ctx, group, "metrics", config, config.MetricsServerAddress, mux)
+}
+
+// serveHTTP serves handler in the group, with TLS when it is configured, and shuts the server
+// down when ctx is cancelled, giving in-flight requests up to the shutdown timeout to finish
+func serveHTTP(ctx context.Context, group *errgroup.Group, name string, config config.Config, address string, handler http.Handler) error {
+	timeout := config.ShutdownTimeout
+	tlsConfig, err := config.ServerTLS()
 	if err != nil {
-		log.Fatal("cannot start HTTP getway server", err)
+		return err
 	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot create %s listener: %w", name, err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	server := &http.Server{Handler: handler, TLSConfig: tlsConfig}
+
+	group.Go(func() error {
+		slog.Info("start "+name+" server", "address", listener.Addr().String(), "tls", tlsConfig != nil)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("%s server failed: %w", name, err)
+		}
+		return nil
+	})
+
+	group.Go(func() error {
+		<-ctx.Done()
+		slog.Info("stopping " + name + " server")
+
+		// ctx уже отменён, на завершение запросов нужен свой
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Warn(name+" server did not stop in time", "timeout", timeout, "error", err)
+			return server.Close()
+		}
+		return nil
+	})
+
+	return nil
 }
 
-func runGinServer(config config.Config, store sqlc.Store) {
-	server, err := api.NewServer(config, store)
+// runReconciliation reconciles the ledger once and exits with status 1 when it finds discrepancies
+func runReconciliation(config config.Config) {
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
-		log.Fatal("cannot create server", err)
+		fatal("cannot connect to database", err)
 	}
+	defer conn.Close()
 
-	err = server.Start(config.HTTPServerAddress)
+	run, err := worker.NewReconciler(config, sqlc.NewStore(conn)).Run(context.Background())
 	if err != nil {
-		log.Fatal("cannot start server", err)
+		fatal("cannot reconcile ledger", err)
+	}
+
+	slog.Info("reconciliation finished", "run_id", run.ID, "discrepancies", run.DiscrepancyCount)
+	if run.DiscrepancyCount > 0 {
+		os.Exit(1)
 	}
 }
+
+// runConfigCommand prints the effective configuration and exits with status 1 when it is invalid,
+// so operators can check what a deployment will run with
+func runConfigCommand(config config.Config, args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: main config print [--redacted|--show-secrets]")
+		os.Exit(2)
+	}
+
+	if err := config.PrintCommand(os.Stdout, os.Stderr, args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "usage: main config print [--redacted|--show-secrets]:", err)
+		os.Exit(2)
+	}
+	if err := config.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// fatal logs err and exits with status 1
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS "system_accounts";
+
+DROP TABLE IF EXISTS "fee_schedules";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bank_revenue');
+DELETE FROM "accounts" WHERE "owner" = 'bank_revenue';
+DELETE FROM "users" WHERE "username" = 'bank_revenue';
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "tier";
@@ -0,0 +1,43 @@
+ALTER TABLE "accounts" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "currency" varchar NOT NULL,
+  "tier" varchar NOT NULL DEFAULT 'standard',
+  "kind" varchar NOT NULL,
+  "flat_amount" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" bigint NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "system_accounts" (
+  "purpose" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  PRIMARY KEY ("purpose", "currency")
+);
+
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the sender on top of amount';
+COMMENT ON COLUMN "fee_schedules"."kind" IS 'flat or percentage';
+COMMENT ON COLUMN "fee_schedules"."percentage_bps" IS 'basis points of the transfer amount';
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS '0 means no upper bound';
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "currency_tier_key" UNIQUE ("currency", "tier");
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_kind_check" CHECK ("kind" IN ('flat', 'percentage'));
+
+ALTER TABLE "system_accounts"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- Банковский пользователь, на счета которого зачисляются комиссии
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bank_revenue', '', 'Simple Bank Revenue', 'revenue@simple-bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_revenue', 0, 'USD'), ('bank_revenue', 0, 'EUR'), ('bank_revenue', 0, 'RUB');
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'fee_revenue', "currency", "id" FROM "accounts" WHERE "owner" = 'bank_revenue';
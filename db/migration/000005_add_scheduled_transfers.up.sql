@@ -0,0 +1,48 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "recurrence" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "next_run_at" timestamptz NOT NULL,
+  "next_attempt_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "failure_count" bigint NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "occurrence_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL,
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "transfer_id" bigint,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+CREATE INDEX ON "scheduled_transfers" ("status", "next_attempt_at");
+CREATE UNIQUE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "occurrence_at");
+
+COMMENT ON COLUMN "scheduled_transfers"."recurrence" IS 'standard 5-field cron expression, UTC';
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'occurrence that has to be executed next';
+COMMENT ON COLUMN "scheduled_transfers"."next_attempt_at" IS 'when the worker picks the occurrence up, later than next_run_at while retrying';
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'consecutive occurrences that failed after all retries';
+
+ALTER TABLE "scheduled_transfers"
+ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+ALTER TABLE "scheduled_transfers"
+ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "scheduled_transfers"
+ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "scheduled_transfer_runs"
+ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+ALTER TABLE "scheduled_transfer_runs"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
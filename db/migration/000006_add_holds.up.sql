@@ -0,0 +1,34 @@
+ALTER TABLE "accounts" ADD COLUMN "held_amount" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint GENERATED ALWAYS AS ("balance" - "held_amount") STORED;
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'authorized',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "holds" ("account_id");
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+COMMENT ON COLUMN "accounts"."balance" IS 'ledger balance, includes money reserved by holds';
+COMMENT ON COLUMN "accounts"."held_amount" IS 'sum of authorized holds';
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance that can be spent: balance minus held_amount';
+COMMENT ON COLUMN "holds"."amount" IS 'must be positive';
+COMMENT ON COLUMN "holds"."status" IS 'authorized, captured, voided or expired';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "held_amount_check" CHECK ("held_amount" >= 0);
+ALTER TABLE "holds" ADD CONSTRAINT "hold_amount_check" CHECK ("amount" > 0 AND "captured_amount" BETWEEN 0 AND "amount");
+
+ALTER TABLE "holds"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "holds"
+ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "holds"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "reversal_requests";
+
+ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "refunded_amount_check";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "refunded_amount";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "reversal_of";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
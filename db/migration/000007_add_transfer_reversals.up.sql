@@ -0,0 +1,39 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint;
+ALTER TABLE "transfers" ADD COLUMN "refunded_amount" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "reversal_requests" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "transfer_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL DEFAULT '',
+  "requested_by" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "reviewed_by" varchar,
+  "reversal_transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfers" ("reversal_of");
+CREATE INDEX ON "reversal_requests" ("transfer_id");
+CREATE INDEX ON "reversal_requests" ("status");
+
+COMMENT ON COLUMN "users"."role" IS 'depositor or banker';
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'transfer this one compensates';
+COMMENT ON COLUMN "transfers"."refunded_amount" IS 'sum of the reversals of this transfer';
+COMMENT ON COLUMN "reversal_requests"."status" IS 'pending, completed or rejected';
+
+ALTER TABLE "transfers" ADD CONSTRAINT "refunded_amount_check" CHECK ("refunded_amount" BETWEEN 0 AND "amount");
+
+ALTER TABLE "transfers"
+ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+ALTER TABLE "reversal_requests"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+ALTER TABLE "reversal_requests"
+ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+ALTER TABLE "reversal_requests"
+ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+ALTER TABLE "reversal_requests"
+ADD FOREIGN KEY ("reversal_transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,5 @@
+DELETE FROM "system_accounts" WHERE "purpose" = 'interest_income';
+
+DROP TABLE IF EXISTS "overdraft_interest_postings";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "overdraft_limit";
@@ -0,0 +1,25 @@
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "overdraft_interest_postings" (
+  "account_id" bigint NOT NULL,
+  "posting_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "entry_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "posting_date")
+);
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the available balance may go';
+COMMENT ON COLUMN "overdraft_interest_postings"."balance" IS 'negative balance the interest was charged on';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "overdraft_limit_check" CHECK ("overdraft_limit" >= 0);
+
+ALTER TABLE "overdraft_interest_postings"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "overdraft_interest_postings"
+ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+-- Проценты по овердрафту зачисляются на те же банковские счета, что и комиссии
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'interest_income', "currency", "account_id" FROM "system_accounts" WHERE "purpose" = 'fee_revenue';
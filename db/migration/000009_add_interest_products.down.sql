@@ -0,0 +1,15 @@
+DELETE FROM "system_accounts" WHERE "purpose" = 'interest_expense';
+
+DROP TABLE IF EXISTS "interest_postings";
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bank_expense');
+DELETE FROM "accounts" WHERE "owner" = 'bank_expense';
+DELETE FROM "users" WHERE "username" = 'bank_expense';
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "accrued_interest_micros";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "matures_at";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "product";
+
+DROP TABLE IF EXISTS "account_products";
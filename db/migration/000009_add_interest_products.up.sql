@@ -0,0 +1,60 @@
+CREATE TABLE "account_products" (
+  "code" varchar PRIMARY KEY NOT NULL,
+  "kind" varchar NOT NULL,
+  "annual_rate_bps" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "accounts" ADD COLUMN "product" varchar NOT NULL DEFAULT 'checking';
+ALTER TABLE "accounts" ADD COLUMN "matures_at" timestamptz;
+ALTER TABLE "accounts" ADD COLUMN "accrued_interest_micros" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "interest_accruals" (
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate_bps" bigint NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "accrual_date")
+);
+
+CREATE TABLE "interest_postings" (
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "entry_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "period")
+);
+
+COMMENT ON COLUMN "account_products"."kind" IS 'checking, savings or term_deposit';
+COMMENT ON COLUMN "account_products"."annual_rate_bps" IS 'annual interest rate in basis points';
+COMMENT ON COLUMN "accounts"."matures_at" IS 'only set for term deposits, no interest accrues after it';
+COMMENT ON COLUMN "accounts"."accrued_interest_micros" IS 'interest accrued but not posted yet, in millionths of the minor unit';
+COMMENT ON COLUMN "interest_postings"."period" IS 'first day of the month the interest was accrued in';
+
+ALTER TABLE "account_products" ADD CONSTRAINT "product_kind_check" CHECK ("kind" IN ('checking', 'savings', 'term_deposit'));
+ALTER TABLE "account_products" ADD CONSTRAINT "annual_rate_check" CHECK ("annual_rate_bps" >= 0);
+
+ALTER TABLE "accounts"
+ADD FOREIGN KEY ("product") REFERENCES "account_products" ("code");
+ALTER TABLE "interest_accruals"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_postings"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_postings"
+ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+INSERT INTO "account_products" ("code", "kind", "annual_rate_bps")
+VALUES ('checking', 'checking', 0), ('savings', 'savings', 300), ('term_deposit', 'term_deposit', 600);
+
+-- Банковский пользователь, со счетов которого выплачиваются проценты
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bank_expense', '', 'Simple Bank Expense', 'expense@simple-bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_expense', 0, 'USD'), ('bank_expense', 0, 'EUR'), ('bank_expense', 0, 'RUB');
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'interest_expense', "currency", "id" FROM "accounts" WHERE "owner" = 'bank_expense';
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "postings";
+
+DROP FUNCTION IF EXISTS check_journal_entry_balanced();
+
+DROP TABLE IF EXISTS "journal_entries";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "gl_account";
+
+DROP TABLE IF EXISTS "gl_accounts";
@@ -0,0 +1,106 @@
+CREATE TABLE "gl_accounts" (
+  "code" varchar PRIMARY KEY NOT NULL,
+  "name" varchar NOT NULL,
+  "type" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "journal_entries" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "kind" varchar NOT NULL,
+  "transfer_id" bigint,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "postings" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "journal_entry_id" bigint NOT NULL,
+  "gl_account" varchar NOT NULL,
+  "account_id" bigint,
+  "currency" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "accounts" ADD COLUMN "gl_account" varchar NOT NULL DEFAULT 'customer_deposits';
+
+CREATE INDEX ON "journal_entries" ("transfer_id");
+CREATE INDEX ON "postings" ("journal_entry_id");
+CREATE INDEX ON "postings" ("gl_account", "currency");
+
+COMMENT ON COLUMN "gl_accounts"."type" IS 'asset, liability, equity, revenue or expense';
+COMMENT ON COLUMN "journal_entries"."kind" IS 'what produced the journal: transfer, interest, overdraft_interest or opening_balance';
+COMMENT ON COLUMN "postings"."account_id" IS 'customer or bank account the posting belongs to, null for pure GL postings';
+COMMENT ON COLUMN "postings"."amount" IS 'positive credits the GL account, negative debits it';
+COMMENT ON COLUMN "accounts"."gl_account" IS 'GL account the balance is carried in';
+
+ALTER TABLE "gl_accounts" ADD CONSTRAINT "gl_account_type_check" CHECK ("type" IN ('asset', 'liability', 'equity', 'revenue', 'expense'));
+ALTER TABLE "postings" ADD CONSTRAINT "posting_amount_check" CHECK ("amount" <> 0);
+
+ALTER TABLE "journal_entries"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+ALTER TABLE "postings"
+ADD FOREIGN KEY ("journal_entry_id") REFERENCES "journal_entries" ("id");
+ALTER TABLE "postings"
+ADD FOREIGN KEY ("gl_account") REFERENCES "gl_accounts" ("code");
+ALTER TABLE "postings"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- Проводки журнала в каждой валюте должны давать в сумме ноль.
+-- Триггер отложен до коммита, чтобы журнал можно было записывать построчно.
+CREATE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+  IF EXISTS (
+    SELECT 1
+    FROM "postings"
+    WHERE "journal_entry_id" = NEW."journal_entry_id"
+    GROUP BY "currency"
+    HAVING SUM("amount") <> 0
+  ) THEN
+    RAISE EXCEPTION 'journal entry % is not balanced', NEW."journal_entry_id"
+      USING ERRCODE = 'check_violation';
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "postings_balanced"
+AFTER INSERT OR UPDATE ON "postings"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+INSERT INTO "gl_accounts" ("code", "name", "type")
+VALUES
+  ('cash', 'Cash', 'asset'),
+  ('suspense', 'Suspense', 'asset'),
+  ('customer_deposits', 'Customer deposits', 'liability'),
+  ('revenue', 'Revenue', 'revenue'),
+  ('expense', 'Expense', 'expense');
+
+ALTER TABLE "accounts"
+ADD FOREIGN KEY ("gl_account") REFERENCES "gl_accounts" ("code");
+
+UPDATE "accounts" SET "gl_account" = 'revenue' WHERE "owner" = 'bank_revenue';
+UPDATE "accounts" SET "gl_account" = 'expense' WHERE "owner" = 'bank_expense';
+
+-- Остатки, накопленные до появления главной книги, заносятся вводным журналом
+-- с противоположной проводкой на счёт невыясненных сумм
+INSERT INTO "journal_entries" ("kind", "description")
+SELECT 'opening_balance', "currency"
+FROM "accounts"
+WHERE "balance" <> 0
+GROUP BY "currency";
+
+INSERT INTO "postings" ("journal_entry_id", "gl_account", "account_id", "currency", "amount")
+SELECT "journal_entries"."id", "accounts"."gl_account", "accounts"."id", "accounts"."currency", "accounts"."balance"
+FROM "accounts"
+JOIN "journal_entries" ON "journal_entries"."kind" = 'opening_balance'
+  AND "journal_entries"."description" = "accounts"."currency"
+WHERE "accounts"."balance" <> 0;
+
+INSERT INTO "postings" ("journal_entry_id", "gl_account", "currency", "amount")
+SELECT "journal_entry_id", 'suspense', "currency", -SUM("amount")
+FROM "postings"
+GROUP BY "journal_entry_id", "currency"
+HAVING SUM("amount") <> 0;
@@ -0,0 +1,45 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+CREATE TABLE "reconciliation_runs" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'running',
+  "discrepancy_count" bigint NOT NULL DEFAULT 0,
+  "error" varchar NOT NULL DEFAULT '',
+  "started_at" timestamptz NOT NULL DEFAULT (now()),
+  "finished_at" timestamptz
+);
+
+CREATE TABLE "reconciliation_discrepancies" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "run_id" bigint NOT NULL,
+  "kind" varchar NOT NULL,
+  "account_id" bigint,
+  "transfer_id" bigint,
+  "entry_id" bigint,
+  "currency" varchar NOT NULL DEFAULT '',
+  "expected" bigint NOT NULL DEFAULT 0,
+  "actual" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "entries" ("transfer_id");
+CREATE INDEX ON "reconciliation_discrepancies" ("run_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer the entry was booked for, null for interest';
+COMMENT ON COLUMN "reconciliation_runs"."status" IS 'running, completed or failed';
+COMMENT ON COLUMN "reconciliation_discrepancies"."kind" IS 'balance_mismatch, missing_transfer_entry, unbalanced_transfer or orphaned_entry';
+
+ALTER TABLE "entries"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+ALTER TABLE "reconciliation_discrepancies"
+ADD FOREIGN KEY ("run_id") REFERENCES "reconciliation_runs" ("id");
+
+-- Проводки перевода создаются в одной транзакции с ним, поэтому now() у них совпадает
+UPDATE "entries"
+SET "transfer_id" = "transfers"."id"
+FROM "transfers"
+WHERE "entries"."created_at" = "transfers"."created_at"
+  AND (
+    "entries"."account_id" IN ("transfers"."from_account_id", "transfers"."to_account_id")
+    OR "entries"."account_id" IN (SELECT "account_id" FROM "system_accounts" WHERE "purpose" = 'fee_revenue')
+  );
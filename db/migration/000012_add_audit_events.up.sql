@@ -0,0 +1,40 @@
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "target_type" varchar NOT NULL,
+  "target_id" varchar NOT NULL,
+  "before" json NOT NULL DEFAULT 'null',
+  "after" json NOT NULL DEFAULT 'null',
+  "request_id" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "prev_hash" varchar NOT NULL,
+  "hash" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "audit_events" ("actor");
+CREATE INDEX ON "audit_events" ("target_type", "target_id");
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'username that made the change';
+COMMENT ON COLUMN "audit_events"."before" IS 'json, not jsonb, so the hashed text is kept byte for byte';
+COMMENT ON COLUMN "audit_events"."prev_hash" IS 'hash of the previous event, empty for the first one';
+COMMENT ON COLUMN "audit_events"."hash" IS 'sha256 of prev_hash and the event fields';
+
+ALTER TABLE "audit_events" ADD CONSTRAINT "audit_hash_key" UNIQUE ("hash");
+
+-- Журнал аудита только дополняется: изменение и удаление записей запрещены
+CREATE FUNCTION reject_audit_event_change() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only' USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_append_only"
+BEFORE UPDATE OR DELETE ON "audit_events"
+FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
+
+CREATE TRIGGER "audit_events_no_truncate"
+BEFORE TRUNCATE ON "audit_events"
+FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_event_change();
@@ -0,0 +1,20 @@
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" bytea NOT NULL,
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "outbox" ("id") WHERE "published_at" IS NULL;
+CREATE INDEX ON "outbox" ("aggregate_type", "aggregate_id", "id") WHERE "published_at" IS NULL;
+
+COMMENT ON COLUMN "outbox"."aggregate_type" IS 'transfer, account or user';
+COMMENT ON COLUMN "outbox"."event_type" IS 'full protobuf message name of the payload, e.g. pb.TransferCreated';
+COMMENT ON COLUMN "outbox"."payload" IS 'protobuf encoded event';
+COMMENT ON COLUMN "outbox"."published_at" IS 'null until the relay has handed the event to the publisher';
@@ -0,0 +1,49 @@
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL,
+  "secret" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "failure_count" bigint NOT NULL DEFAULT 0,
+  "disabled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "subscription_id" bigint NOT NULL,
+  "event_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" json NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "response_status" bigint NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "replay_of" bigint,
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+CREATE INDEX ON "webhook_deliveries" ("subscription_id", "id");
+CREATE INDEX ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "webhook_subscriptions"."event_types" IS 'transfer.received, transfer.sent or account.created';
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'key of the HMAC-SHA256 payload signature';
+COMMENT ON COLUMN "webhook_subscriptions"."status" IS 'active or disabled';
+COMMENT ON COLUMN "webhook_subscriptions"."failure_count" IS 'failed attempts in a row, the endpoint is disabled when it reaches the limit';
+COMMENT ON COLUMN "webhook_deliveries"."event_id" IS 'same for every delivery and replay of one event, so receivers can drop duplicates';
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or failed';
+COMMENT ON COLUMN "webhook_deliveries"."response_status" IS 'HTTP status of the last attempt, 0 when no response was received';
+COMMENT ON COLUMN "webhook_deliveries"."replay_of" IS 'delivery this one replays';
+
+ALTER TABLE "webhook_subscriptions"
+ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+ALTER TABLE "webhook_deliveries"
+ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+ALTER TABLE "webhook_deliveries"
+ADD FOREIGN KEY ("replay_of") REFERENCES "webhook_deliveries" ("id") ON DELETE SET NULL;
@@ -0,0 +1,24 @@
+CREATE TABLE "tasks" (
+  "id" bigserial PRIMARY KEY NOT NULL,
+  "queue" varchar NOT NULL DEFAULT 'default',
+  "type" varchar NOT NULL,
+  "payload" jsonb NOT NULL DEFAULT '{}',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "max_attempts" bigint NOT NULL DEFAULT 10,
+  "run_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_until" timestamptz,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "completed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "tasks" ("queue", "run_at") WHERE "status" = 'pending';
+CREATE INDEX ON "tasks" ("queue", "locked_until") WHERE "status" = 'running';
+CREATE INDEX ON "tasks" ("status", "id");
+
+COMMENT ON COLUMN "tasks"."type" IS 'name the handler is registered under';
+COMMENT ON COLUMN "tasks"."status" IS 'pending, running, succeeded or dead';
+COMMENT ON COLUMN "tasks"."run_at" IS 'the task is not claimed before this time, also used for retry backoff';
+COMMENT ON COLUMN "tasks"."locked_until" IS 'lease of the worker running the task, an expired lease means the worker died';
@@ -0,0 +1,10 @@
+CREATE UNLOGGED TABLE "rate_limits" (
+  "key" varchar PRIMARY KEY NOT NULL,
+  "tat" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "rate_limits" ("tat");
+
+COMMENT ON TABLE "rate_limits" IS 'token buckets shared by all instances, unlogged because losing them on a crash only resets the limits';
+COMMENT ON COLUMN "rate_limits"."key" IS 'route and caller the bucket belongs to';
+COMMENT ON COLUMN "rate_limits"."tat" IS 'theoretical arrival time of the next request, the bucket is full once it is in the past';
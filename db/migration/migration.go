@@ -0,0 +1,36 @@
+// Package migration embeds the schema migrations, so the binary knows which schema version it needs
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.up.sql
+var files embed.FS
+
+// Latest returns the version of the newest up migration
+func Latest() (uint, error) {
+	names, err := fs.Glob(files, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, name := range names {
+		prefix, _, found := strings.Cut(name, "_")
+		if !found {
+			return 0, fmt.Errorf("migration %s has no version prefix", name)
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s has an invalid version: %w", name, err)
+		}
+		latest = max(latest, uint(version))
+	}
+	return latest, nil
+}
@@ -0,0 +1,13 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatest(t *testing.T) {
+	latest, err := Latest()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, latest, uint(15))
+}
@@ -1,6 +1,12 @@
 -- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, currency)
-VALUES ($1, $2, $3)
+INSERT INTO accounts (owner, balance, currency, product, matures_at)
+VALUES (
+        sqlc.arg(owner),
+        sqlc.arg(balance),
+        sqlc.arg(currency),
+        COALESCE(sqlc.narg(product), 'checking'),
+        sqlc.narg(matures_at)
+    )
 RETURNING *;
 -- name: GetAccount :one
 SELECT *
@@ -36,3 +42,55 @@ RETURNING *;
 -- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1;
+-- name: UpdateAccountTier :one
+UPDATE accounts
+SET tier = $2
+WHERE id = $1
+RETURNING *;
+-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING *;
+-- name: ListOverdrawnAccounts :many
+SELECT *
+FROM accounts
+WHERE balance < 0
+    AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+-- name: AddAccountAccruedInterest :one
+UPDATE accounts
+SET accrued_interest_micros = accrued_interest_micros + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+-- name: ListInterestBearingAccounts :many
+SELECT *
+FROM accounts
+WHERE balance > 0
+    AND product IN (
+        SELECT code
+        FROM account_products
+        WHERE annual_rate_bps > 0
+    )
+    AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+-- name: ListAccountsDueInterestPosting :many
+SELECT *
+FROM accounts
+WHERE accrued_interest_micros >= sqlc.arg(min_micros)
+    AND NOT EXISTS (
+        SELECT 1
+        FROM interest_postings
+        WHERE interest_postings.account_id = accounts.id
+            AND interest_postings.period = sqlc.arg(period)
+    )
+    AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
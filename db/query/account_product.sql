@@ -0,0 +1,14 @@
+-- name: GetAccountProduct :one
+SELECT *
+FROM account_products
+WHERE code = $1
+LIMIT 1;
+-- name: ListAccountProducts :many
+SELECT *
+FROM account_products
+ORDER BY code;
+-- name: UpdateAccountProductRate :one
+UPDATE account_products
+SET annual_rate_bps = $2
+WHERE code = $1
+RETURNING *;
@@ -0,0 +1,52 @@
+-- name: LockAuditChain :exec
+SELECT pg_advisory_xact_lock(sqlc.arg(key));
+-- name: GetLastAuditEvent :one
+SELECT *
+FROM audit_events
+ORDER BY id DESC
+LIMIT 1;
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+        actor,
+        action,
+        target_type,
+        target_id,
+        before,
+        after,
+        request_id,
+        client_ip,
+        user_agent,
+        prev_hash,
+        hash,
+        created_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+-- name: ListAuditEvents :many
+SELECT *
+FROM audit_events
+WHERE (
+        sqlc.narg(actor)::varchar IS NULL
+        OR actor = sqlc.narg(actor)
+    )
+    AND (
+        sqlc.narg(action)::varchar IS NULL
+        OR action = sqlc.narg(action)
+    )
+    AND (
+        sqlc.narg(target_type)::varchar IS NULL
+        OR target_type = sqlc.narg(target_type)
+    )
+    AND (
+        sqlc.narg(target_id)::varchar IS NULL
+        OR target_id = sqlc.narg(target_id)
+    )
+    AND id < sqlc.arg(before_id)
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit);
+-- name: ListAuditEventsAfter :many
+SELECT *
+FROM audit_events
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
@@ -1,9 +1,10 @@
 -- name: CreateEntry :one
 INSERT INTO entries(
   account_id,
-  amount
+  amount,
+  transfer_id
 ) VALUES (
-    $1, $2
+    $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +16,13 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+-- name: ListEntriesAfter :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: NotifyAccountChanged :exec
+SELECT pg_notify('account_changed', sqlc.arg(account_id)::bigint::text);
@@ -0,0 +1,35 @@
+-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (
+        currency,
+        tier,
+        kind,
+        flat_amount,
+        percentage_bps,
+        min_fee,
+        max_fee
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (currency, tier) DO
+UPDATE
+SET kind = EXCLUDED.kind,
+    flat_amount = EXCLUDED.flat_amount,
+    percentage_bps = EXCLUDED.percentage_bps,
+    min_fee = EXCLUDED.min_fee,
+    max_fee = EXCLUDED.max_fee
+RETURNING *;
+-- name: GetFeeSchedule :one
+SELECT *
+FROM fee_schedules
+WHERE currency = sqlc.arg(currency)
+    AND tier IN (sqlc.arg(tier), 'standard')
+ORDER BY tier = sqlc.arg(tier) DESC
+LIMIT 1;
+-- name: GetFeeScheduleByTier :one
+SELECT *
+FROM fee_schedules
+WHERE currency = $1
+    AND tier = $2;
+-- name: ListFeeSchedules :many
+SELECT *
+FROM fee_schedules
+ORDER BY currency,
+    tier;
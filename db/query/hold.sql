@@ -0,0 +1,36 @@
+-- name: CreateHold :one
+INSERT INTO holds (account_id, to_account_id, amount, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+-- name: GetHold :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1;
+-- name: GetHoldForUpdate :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1 FOR NO KEY
+UPDATE;
+-- name: ListHolds :many
+SELECT *
+FROM holds
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+-- name: ListExpiredHolds :many
+SELECT *
+FROM holds
+WHERE status = 'authorized'
+    AND expires_at <= sqlc.arg(expired_before)
+ORDER BY expires_at
+LIMIT sqlc.arg(row_limit);
+-- name: UpdateHold :one
+UPDATE holds
+SET status = $2,
+    captured_amount = $3,
+    transfer_id = $4,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,16 @@
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+        account_id,
+        accrual_date,
+        balance,
+        annual_rate_bps,
+        amount_micros
+    )
+VALUES ($1, $2, $3, $4, $5) ON CONFLICT (account_id, accrual_date) DO NOTHING
+RETURNING *;
+-- name: ListInterestAccruals :many
+SELECT *
+FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2 OFFSET $3;
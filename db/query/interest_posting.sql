@@ -0,0 +1,10 @@
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (account_id, period, amount, entry_id)
+VALUES ($1, $2, $3, $4) ON CONFLICT (account_id, period) DO NOTHING
+RETURNING *;
+-- name: ListInterestPostings :many
+SELECT *
+FROM interest_postings
+WHERE account_id = $1
+ORDER BY period DESC
+LIMIT $2 OFFSET $3;
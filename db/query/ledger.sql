@@ -0,0 +1,44 @@
+-- name: ListGLAccounts :many
+SELECT *
+FROM gl_accounts
+ORDER BY code;
+-- name: CreateJournalEntry :one
+INSERT INTO journal_entries (kind, transfer_id, description)
+VALUES ($1, $2, $3)
+RETURNING *;
+-- name: GetJournalEntryByTransfer :one
+SELECT *
+FROM journal_entries
+WHERE transfer_id = $1
+LIMIT 1;
+-- name: CreatePosting :one
+INSERT INTO postings (
+        journal_entry_id,
+        gl_account,
+        account_id,
+        currency,
+        amount
+    )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+-- name: ListPostingsByJournalEntry :many
+SELECT *
+FROM postings
+WHERE journal_entry_id = $1
+ORDER BY id;
+-- name: GetTrialBalance :many
+SELECT gl_accounts.code,
+    gl_accounts.name,
+    gl_accounts.type,
+    postings.currency,
+    COALESCE(SUM(- postings.amount) FILTER (WHERE postings.amount < 0), 0)::bigint AS debit,
+    COALESCE(SUM(postings.amount) FILTER (WHERE postings.amount > 0), 0)::bigint AS credit
+FROM postings
+    JOIN gl_accounts ON gl_accounts.code = postings.gl_account
+WHERE postings.created_at < sqlc.arg(as_of)
+GROUP BY gl_accounts.code,
+    gl_accounts.name,
+    gl_accounts.type,
+    postings.currency
+ORDER BY postings.currency,
+    gl_accounts.code;
@@ -0,0 +1,34 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+-- name: ListDueOutboxEvents :many
+SELECT *
+FROM outbox o
+WHERE o.published_at IS NULL
+    AND o.next_attempt_at <= sqlc.arg(now)
+    AND NOT EXISTS (
+        SELECT 1
+        FROM outbox earlier
+        WHERE earlier.aggregate_type = o.aggregate_type
+            AND earlier.aggregate_id = o.aggregate_id
+            AND earlier.published_at IS NULL
+            AND earlier.id < o.id
+            AND earlier.next_attempt_at > sqlc.arg(now)
+    )
+ORDER BY o.id
+LIMIT sqlc.arg(row_limit);
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox
+SET published_at = now(),
+    attempts = attempts + 1,
+    last_error = ''
+WHERE id = $1;
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox
+SET attempts = attempts + 1,
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id);
+-- name: TryLockOutboxRelay :one
+SELECT pg_try_advisory_xact_lock(sqlc.arg(key))::boolean AS locked;
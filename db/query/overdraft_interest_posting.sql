@@ -0,0 +1,16 @@
+-- name: CreateOverdraftInterestPosting :one
+INSERT INTO overdraft_interest_postings (
+        account_id,
+        posting_date,
+        balance,
+        amount,
+        entry_id
+    )
+VALUES ($1, $2, $3, $4, $5) ON CONFLICT (account_id, posting_date) DO NOTHING
+RETURNING *;
+-- name: ListOverdraftInterestPostings :many
+SELECT *
+FROM overdraft_interest_postings
+WHERE account_id = $1
+ORDER BY posting_date DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,11 @@
+-- name: TakeRateLimit :one
+INSERT INTO rate_limits AS r (key, tat)
+VALUES (sqlc.arg(key), now() + make_interval(secs => sqlc.arg(interval_seconds)::float8))
+ON CONFLICT (key) DO UPDATE
+SET tat = GREATEST(r.tat, now()) + make_interval(secs => sqlc.arg(interval_seconds)::float8)
+WHERE GREATEST(r.tat, now()) - now() <= make_interval(secs => sqlc.arg(tolerance_seconds)::float8)
+RETURNING tat;
+
+-- name: DeleteExpiredRateLimits :exec
+DELETE FROM rate_limits
+WHERE tat < now();
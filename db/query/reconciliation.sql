@@ -0,0 +1,128 @@
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs DEFAULT
+VALUES
+RETURNING *;
+-- name: FinishReconciliationRun :one
+UPDATE reconciliation_runs
+SET status = $2,
+    discrepancy_count = $3,
+    error = $4,
+    finished_at = now()
+WHERE id = $1
+RETURNING *;
+-- name: GetReconciliationRun :one
+SELECT *
+FROM reconciliation_runs
+WHERE id = $1
+LIMIT 1;
+-- name: ListReconciliationRuns :many
+SELECT *
+FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT $1 OFFSET $2;
+-- name: CreateReconciliationDiscrepancy :one
+INSERT INTO reconciliation_discrepancies (
+        run_id,
+        kind,
+        account_id,
+        transfer_id,
+        entry_id,
+        currency,
+        expected,
+        actual
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+-- name: ListReconciliationDiscrepancies :many
+SELECT *
+FROM reconciliation_discrepancies
+WHERE run_id = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+-- name: ListBalanceMismatches :many
+SELECT accounts.id AS account_id,
+    accounts.currency,
+    accounts.balance,
+    COALESCE(SUM(entries.amount), 0)::bigint AS entries_total
+FROM accounts
+    LEFT JOIN entries ON entries.account_id = accounts.id
+GROUP BY accounts.id
+HAVING accounts.balance <> COALESCE(SUM(entries.amount), 0)
+ORDER BY accounts.id;
+-- name: ListTransfersMissingEntries :many
+SELECT transfers.id AS transfer_id,
+    accounts.currency,
+    transfers.amount,
+    (
+        SELECT COUNT(*)
+        FROM entries
+        WHERE entries.transfer_id = transfers.id
+            AND (
+                (
+                    entries.account_id = transfers.from_account_id
+                    AND entries.amount = - transfers.amount
+                )
+                OR (
+                    entries.account_id = transfers.to_account_id
+                    AND entries.amount = transfers.amount
+                )
+            )
+    )::bigint AS matching_entries
+FROM transfers
+    JOIN accounts ON accounts.id = transfers.from_account_id
+WHERE NOT EXISTS (
+        SELECT 1
+        FROM entries
+        WHERE entries.transfer_id = transfers.id
+            AND entries.account_id = transfers.from_account_id
+            AND entries.amount = - transfers.amount
+    )
+    OR NOT EXISTS (
+        SELECT 1
+        FROM entries
+        WHERE entries.transfer_id = transfers.id
+            AND entries.account_id = transfers.to_account_id
+            AND entries.amount = transfers.amount
+    )
+ORDER BY transfers.id;
+-- name: ListUnbalancedTransfers :many
+SELECT entries.transfer_id,
+    accounts.currency,
+    SUM(entries.amount)::bigint AS total
+FROM entries
+    JOIN accounts ON accounts.id = entries.account_id
+WHERE entries.transfer_id IS NOT NULL
+GROUP BY entries.transfer_id,
+    accounts.currency
+HAVING SUM(entries.amount) <> 0
+ORDER BY entries.transfer_id;
+-- name: ListOrphanedEntries :many
+SELECT entries.id AS entry_id,
+    entries.account_id,
+    accounts.currency,
+    entries.amount
+FROM entries
+    JOIN accounts ON accounts.id = entries.account_id
+WHERE accounts.gl_account = 'customer_deposits'
+    AND (
+        (
+            entries.transfer_id IS NULL
+            AND NOT EXISTS (
+                SELECT 1
+                FROM interest_postings
+                WHERE interest_postings.entry_id = entries.id
+            )
+            AND NOT EXISTS (
+                SELECT 1
+                FROM overdraft_interest_postings
+                WHERE overdraft_interest_postings.entry_id = entries.id
+            )
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM transfers
+            WHERE transfers.id = entries.transfer_id
+                AND entries.account_id NOT IN (transfers.from_account_id, transfers.to_account_id)
+        )
+    )
+ORDER BY entries.id;
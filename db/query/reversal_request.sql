@@ -0,0 +1,38 @@
+-- name: CreateReversalRequest :one
+INSERT INTO reversal_requests (
+        transfer_id,
+        amount,
+        reason,
+        requested_by,
+        status,
+        reviewed_by,
+        reversal_transfer_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+-- name: GetReversalRequest :one
+SELECT *
+FROM reversal_requests
+WHERE id = $1
+LIMIT 1;
+-- name: GetReversalRequestForUpdate :one
+SELECT *
+FROM reversal_requests
+WHERE id = $1
+LIMIT 1 FOR NO KEY
+UPDATE;
+-- name: ListReversalRequests :many
+SELECT *
+FROM reversal_requests
+WHERE status = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+-- name: UpdateReversalRequest :one
+UPDATE reversal_requests
+SET status = $2,
+    reviewed_by = $3,
+    reversal_transfer_id = $4,
+    updated_at = now()
+WHERE id = $1
+    AND status = 'pending'
+RETURNING *;
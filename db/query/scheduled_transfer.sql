@@ -0,0 +1,93 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+        owner,
+        from_account_id,
+        to_account_id,
+        amount,
+        recurrence,
+        start_at,
+        end_at,
+        next_run_at,
+        next_attempt_at
+    )
+VALUES (
+        sqlc.arg(owner),
+        sqlc.arg(from_account_id),
+        sqlc.arg(to_account_id),
+        sqlc.arg(amount),
+        sqlc.arg(recurrence),
+        sqlc.arg(start_at),
+        sqlc.arg(end_at),
+        sqlc.arg(next_run_at),
+        sqlc.arg(next_run_at)
+    )
+RETURNING *;
+-- name: GetScheduledTransfer :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1;
+-- name: GetScheduledTransferForUpdate :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY
+UPDATE;
+-- name: ListScheduledTransfers :many
+SELECT *
+FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+-- name: ListDueScheduledTransfers :many
+SELECT *
+FROM scheduled_transfers
+WHERE status = 'active'
+    AND next_attempt_at <= sqlc.arg(due_before)
+ORDER BY next_attempt_at
+LIMIT sqlc.arg(row_limit);
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount = COALESCE(sqlc.narg(amount), amount),
+    recurrence = COALESCE(sqlc.narg(recurrence), recurrence),
+    end_at = COALESCE(sqlc.narg(end_at), end_at),
+    status = COALESCE(sqlc.narg(status), status),
+    next_run_at = COALESCE(sqlc.narg(next_run_at), next_run_at),
+    next_attempt_at = COALESCE(sqlc.narg(next_run_at), next_attempt_at)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+-- name: AdvanceScheduledTransfer :one
+UPDATE scheduled_transfers
+SET next_run_at = $2,
+    next_attempt_at = $3,
+    status = $4,
+    failure_count = $5,
+    last_error = $6
+WHERE id = $1
+RETURNING *;
+-- name: UpsertScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+        scheduled_transfer_id,
+        occurrence_at,
+        status,
+        attempts
+    )
+VALUES ($1, $2, 'pending', 1) ON CONFLICT (scheduled_transfer_id, occurrence_at) DO
+UPDATE
+SET attempts = scheduled_transfer_runs.attempts + 1,
+    updated_at = now()
+RETURNING *;
+-- name: UpdateScheduledTransferRun :one
+UPDATE scheduled_transfer_runs
+SET status = $2,
+    transfer_id = $3,
+    last_error = $4,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+-- name: ListScheduledTransferRuns :many
+SELECT *
+FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
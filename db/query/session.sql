@@ -14,4 +14,10 @@ RETURNING *;
 SELECT *
 FROM sessions
 WHERE id = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+-- name: ListSessions :many
+SELECT *
+FROM sessions
+WHERE username = $1
+  AND expires_at > now()
+ORDER BY created_at DESC;
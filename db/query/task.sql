@@ -0,0 +1,72 @@
+-- name: CreateTask :one
+INSERT INTO tasks (queue, type, payload, max_attempts, run_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+-- name: GetTask :one
+SELECT *
+FROM tasks
+WHERE id = $1
+LIMIT 1;
+-- name: ListTasks :many
+SELECT *
+FROM tasks
+WHERE status = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+-- name: ClaimTasks :many
+UPDATE tasks
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_until = sqlc.arg(locked_until),
+    updated_at = now()
+WHERE id IN (
+    SELECT id
+    FROM tasks
+    WHERE queue = sqlc.arg(queue)
+      AND (
+        (status = 'pending' AND run_at <= sqlc.arg(now))
+        OR (status = 'running' AND locked_until < sqlc.arg(now))
+      )
+    ORDER BY run_at, id
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE SKIP LOCKED
+  )
+RETURNING *;
+-- name: CompleteTask :execrows
+UPDATE tasks
+SET status = 'succeeded',
+    locked_until = NULL,
+    completed_at = now(),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND status = 'running'
+  AND attempts = sqlc.arg(attempts);
+-- name: RetryTask :execrows
+UPDATE tasks
+SET status = 'pending',
+    locked_until = NULL,
+    run_at = sqlc.arg(run_at),
+    last_error = sqlc.arg(last_error),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND status = 'running'
+  AND attempts = sqlc.arg(attempts);
+-- name: KillTask :execrows
+UPDATE tasks
+SET status = 'dead',
+    locked_until = NULL,
+    last_error = sqlc.arg(last_error),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND status = 'running'
+  AND attempts = sqlc.arg(attempts);
+-- name: RequeueDeadTask :one
+UPDATE tasks
+SET status = 'pending',
+    attempts = 0,
+    run_at = now(),
+    last_error = '',
+    updated_at = now()
+WHERE id = $1
+  AND status = 'dead'
+RETURNING *;
@@ -2,9 +2,11 @@
 INSERT INTO transfers (
         from_account_id,
         to_account_id,
-        amount
+        amount,
+        fee,
+        reversal_of
     )
-VALUES ($1, $2, $3)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 -- name: GetTransfer :one
 SELECT *
@@ -17,4 +19,15 @@ FROM transfers
 WHERE from_account_id = $1
     OR to_account_id = $2
 ORDER BY id
-LIMIT $3 OFFSET $4;
\ No newline at end of file
+LIMIT $3 OFFSET $4;
+-- name: GetTransferForUpdate :one
+SELECT *
+FROM transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY
+UPDATE;
+-- name: AddTransferRefundedAmount :one
+UPDATE transfers
+SET refunded_amount = refunded_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -11,4 +11,9 @@ RETURNING *;
 SELECT *
 FROM users
 WHERE username = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING *;
@@ -0,0 +1,121 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (owner, url, event_types, secret)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+-- name: GetWebhookSubscription :one
+SELECT *
+FROM webhook_subscriptions
+WHERE id = $1
+LIMIT 1;
+-- name: ListWebhookSubscriptions :many
+SELECT *
+FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id;
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = COALESCE(sqlc.narg(url), url),
+    event_types = COALESCE(sqlc.narg(event_types), event_types),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+-- name: EnableWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET status = 'active',
+    failure_count = 0,
+    disabled_at = NULL,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+-- name: RecordWebhookSuccess :exec
+UPDATE webhook_subscriptions
+SET failure_count = 0,
+    updated_at = now()
+WHERE id = $1;
+-- name: RecordWebhookFailure :one
+UPDATE webhook_subscriptions
+SET failure_count = failure_count + 1,
+    status = CASE
+        WHEN failure_count + 1 >= sqlc.arg(disable_after) THEN 'disabled'
+        ELSE status
+    END,
+    disabled_at = CASE
+        WHEN failure_count + 1 >= sqlc.arg(disable_after) THEN now()
+        ELSE disabled_at
+    END,
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+-- name: CreateWebhookDeliveries :many
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+SELECT id,
+    sqlc.arg(event_id),
+    sqlc.arg(event_type),
+    sqlc.arg(payload)
+FROM webhook_subscriptions
+WHERE owner = sqlc.arg(owner)
+    AND status = 'active'
+    AND sqlc.arg(event_type)::varchar = ANY(event_types)
+RETURNING *;
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+        subscription_id,
+        event_id,
+        event_type,
+        payload,
+        replay_of
+    )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+-- name: GetWebhookDelivery :one
+SELECT *
+FROM webhook_deliveries
+WHERE id = $1
+LIMIT 1;
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+-- name: ClaimDueWebhookDeliveries :many
+WITH due AS (
+    SELECT d.id
+    FROM webhook_deliveries d
+        JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    WHERE d.status = 'pending'
+        AND d.next_attempt_at <= sqlc.arg(now)
+        AND s.status = 'active'
+    ORDER BY d.id
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE OF d SKIP LOCKED
+)
+UPDATE webhook_deliveries d
+SET next_attempt_at = sqlc.arg(lease_until),
+    updated_at = now()
+FROM due,
+    webhook_subscriptions s
+WHERE d.id = due.id
+    AND s.id = d.subscription_id
+RETURNING d.id,
+    d.subscription_id,
+    d.event_id,
+    d.event_type,
+    d.payload,
+    d.attempts,
+    s.url,
+    s.secret;
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status),
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    response_status = sqlc.arg(response_status),
+    last_error = sqlc.arg(last_error),
+    delivered_at = sqlc.narg(delivered_at),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
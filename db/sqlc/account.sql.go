@@ -7,13 +7,48 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const addAccountAccruedInterest = `-- name: AddAccountAccruedInterest :one
+UPDATE accounts
+SET accrued_interest_micros = accrued_interest_micros + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
+`
+
+type AddAccountAccruedInterestParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountAccruedInterest(ctx context.Context, arg AddAccountAccruedInterestParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountAccruedInterest, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
+	)
+	return i, err
+}
+
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts 
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
 `
 
 type AddAccountBalanceParams struct {
@@ -30,24 +65,79 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
+	)
+	return i, err
+}
+
+const addAccountHeldAmount = `-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
+`
+
+type AddAccountHeldAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldAmount, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, currency)
-VALUES ($1, $2, $3)
-RETURNING id, owner, balance, currency, created_at
+INSERT INTO accounts (owner, balance, currency, product, matures_at)
+VALUES (
+        $1,
+        $2,
+        $3,
+        COALESCE($4, 'checking'),
+        $5
+    )
+RETURNING id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner     string         `json:"owner"`
+	Balance   int64          `json:"balance"`
+	Currency  string         `json:"currency"`
+	Product   sql.NullString `json:"product"`
+	MaturesAt sql.NullTime   `json:"matures_at"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Product,
+		arg.MaturesAt,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -55,6 +145,14 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
 	)
 	return i, err
 }
@@ -70,7 +168,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
 FROM accounts
 WHERE id = $1
 LIMIT 1
@@ -85,12 +183,20 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
 	)
 	return i, err
 }
 
 const getAccountByOwner = `-- name: GetAccountByOwner :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account FROM accounts
 WHERE owner = $1
 LIMIT 1
 `
@@ -104,12 +210,20 @@ func (q *Queries) GetAccountByOwner(ctx context.Context, owner string) (Account,
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
 FROM accounts
 WHERE id = $1
 LIMIT 1
@@ -125,12 +239,20 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
 FROM accounts
 WHERE owner = $1
 ORDER BY id
@@ -158,6 +280,185 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.Tier,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.OverdraftLimit,
+			&i.Product,
+			&i.MaturesAt,
+			&i.AccruedInterestMicros,
+			&i.GlAccount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsDueInterestPosting = `-- name: ListAccountsDueInterestPosting :many
+SELECT id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
+FROM accounts
+WHERE accrued_interest_micros >= $1
+    AND NOT EXISTS (
+        SELECT 1
+        FROM interest_postings
+        WHERE interest_postings.account_id = accounts.id
+            AND interest_postings.period = $2
+    )
+    AND id > $3
+ORDER BY id
+LIMIT $4
+`
+
+type ListAccountsDueInterestPostingParams struct {
+	MinMicros int64     `json:"min_micros"`
+	Period    time.Time `json:"period"`
+	AfterID   int64     `json:"after_id"`
+	RowLimit  int64     `json:"row_limit"`
+}
+
+func (q *Queries) ListAccountsDueInterestPosting(ctx context.Context, arg ListAccountsDueInterestPostingParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsDueInterestPosting,
+		arg.MinMicros,
+		arg.Period,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.Tier,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.OverdraftLimit,
+			&i.Product,
+			&i.MaturesAt,
+			&i.AccruedInterestMicros,
+			&i.GlAccount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestBearingAccounts = `-- name: ListInterestBearingAccounts :many
+SELECT id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
+FROM accounts
+WHERE balance > 0
+    AND product IN (
+        SELECT code
+        FROM account_products
+        WHERE annual_rate_bps > 0
+    )
+    AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListInterestBearingAccountsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int64 `json:"row_limit"`
+}
+
+func (q *Queries) ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestBearingAccounts, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.Tier,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.OverdraftLimit,
+			&i.Product,
+			&i.MaturesAt,
+			&i.AccruedInterestMicros,
+			&i.GlAccount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdrawnAccounts = `-- name: ListOverdrawnAccounts :many
+SELECT id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
+FROM accounts
+WHERE balance < 0
+    AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListOverdrawnAccountsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int64 `json:"row_limit"`
+}
+
+func (q *Queries) ListOverdrawnAccounts(ctx context.Context, arg ListOverdrawnAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listOverdrawnAccounts, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.Tier,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.OverdraftLimit,
+			&i.Product,
+			&i.MaturesAt,
+			&i.AccruedInterestMicros,
+			&i.GlAccount,
 		); err != nil {
 			return nil, err
 		}
@@ -176,7 +477,7 @@ const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts 
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
 `
 
 type UpdateAccountParams struct {
@@ -193,6 +494,80 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
+	)
+	return i, err
+}
+
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
+`
+
+type UpdateAccountOverdraftLimitParams struct {
+	ID             int64 `json:"id"`
+	OverdraftLimit int64 `json:"overdraft_limit"`
+}
+
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountOverdraftLimit, arg.ID, arg.OverdraftLimit)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
+	)
+	return i, err
+}
+
+const updateAccountTier = `-- name: UpdateAccountTier :one
+UPDATE accounts
+SET tier = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, tier, held_amount, available_balance, overdraft_limit, product, matures_at, accrued_interest_micros, gl_account
+`
+
+type UpdateAccountTierParams struct {
+	ID   int64  `json:"id"`
+	Tier string `json:"tier"`
+}
+
+func (q *Queries) UpdateAccountTier(ctx context.Context, arg UpdateAccountTierParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountTier, arg.ID, arg.Tier)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.Product,
+		&i.MaturesAt,
+		&i.AccruedInterestMicros,
+		&i.GlAccount,
 	)
 	return i, err
 }
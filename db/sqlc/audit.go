@@ -0,0 +1,235 @@
+package sqlc
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// auditChainLockKey is the advisory lock serializing appends to the audit hash chain
+const auditChainLockKey = 0x61756469
+
+const auditVerifyBatchSize = 500
+
+const (
+	AuditTargetAccount           = "account"
+	AuditTargetAccountProduct    = "account_product"
+	AuditTargetFeeSchedule       = "fee_schedule"
+	AuditTargetUser              = "user"
+	AuditTargetSession           = "session"
+	AuditTargetTransfer          = "transfer"
+	AuditTargetHold              = "hold"
+	AuditTargetReversalRequest   = "reversal_request"
+	AuditTargetScheduledTransfer = "scheduled_transfer"
+	AuditTargetWebhook           = "webhook_subscription"
+	AuditTargetTask              = "task"
+)
+
+// SystemActor is the audit actor of changes made by background jobs
+const SystemActor = "system"
+
+// AuditRecord describes who is making a change and from where.
+// It travels in the context, so the store can log the change in the same transaction.
+type AuditRecord struct {
+	Actor     string
+	Action    string
+	RequestID string
+	ClientIP  string
+	UserAgent string
+}
+
+// AuditChange is what a change did to its target
+type AuditChange struct {
+	TargetType string
+	TargetID   string
+	Before     any
+	After      any
+}
+
+// UserAuditChange logs a new user without the password hash
+func UserAuditChange(user User) AuditChange {
+	return AuditChange{
+		TargetType: AuditTargetUser,
+		TargetID:   user.Username,
+		After: map[string]any{
+			"username":   user.Username,
+			"full_name":  user.FullName,
+			"email":      user.Email,
+			"role":       user.Role,
+			"created_at": user.CreatedAt,
+		},
+	}
+}
+
+// SessionAuditChange logs a new session without the refresh token
+func SessionAuditChange(session Session) AuditChange {
+	return AuditChange{
+		TargetType: AuditTargetSession,
+		TargetID:   session.ID.String(),
+		After: map[string]any{
+			"username":   session.Username,
+			"user_agent": session.UserAgent,
+			"client_ip":  session.ClientIp,
+			"expires_at": session.ExpiresAt,
+		},
+	}
+}
+
+type auditRecordKey struct{}
+
+// WithAudit returns a context whose store transactions append record to the audit log
+func WithAudit(ctx context.Context, record AuditRecord) context.Context {
+	return context.WithValue(ctx, auditRecordKey{}, record)
+}
+
+// withSystemAudit makes the changes of a background job logged under SystemActor,
+// a record already in ctx is kept, so a person triggering the job stays the actor
+func withSystemAudit(ctx context.Context, action string) context.Context {
+	if _, ok := auditRecordFromContext(ctx); ok {
+		return ctx
+	}
+	return WithAudit(ctx, AuditRecord{Actor: SystemActor, Action: action})
+}
+
+func auditRecordFromContext(ctx context.Context) (AuditRecord, bool) {
+	record, ok := ctx.Value(auditRecordKey{}).(AuditRecord)
+	return record, ok
+}
+
+// AuditTx runs fn in a transaction and appends the change it returns to the audit log before committing
+func (store *SQLStore) AuditTx(ctx context.Context, fn func(q Querier) (AuditChange, error)) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		change, err := fn(q)
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, change)
+		return err
+	})
+}
+
+// recordAudit appends the change to the audit log when ctx carries an audit record.
+// Background jobs that move money log under SystemActor, see withSystemAudit.
+func recordAudit(ctx context.Context, q *Queries, change AuditChange) (AuditEvent, error) {
+	record, ok := auditRecordFromContext(ctx)
+	if !ok {
+		return AuditEvent{}, nil
+	}
+
+	before, err := json.Marshal(change.Before)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+	after, err := json.Marshal(change.After)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+
+	// держим блокировку до конца транзакции, чтобы цепочка не разветвилась
+	if err := q.LockAuditChain(ctx, auditChainLockKey); err != nil {
+		return AuditEvent{}, err
+	}
+
+	var prevHash string
+	last, err := q.GetLastAuditEvent(ctx)
+	switch {
+	case err == nil:
+		prevHash = last.Hash
+	case err != sql.ErrNoRows:
+		return AuditEvent{}, err
+	}
+
+	event := AuditEvent{
+		Actor:      record.Actor,
+		Action:     record.Action,
+		TargetType: change.TargetType,
+		TargetID:   change.TargetID,
+		Before:     before,
+		After:      after,
+		RequestID:  record.RequestID,
+		ClientIp:   record.ClientIP,
+		UserAgent:  record.UserAgent,
+		PrevHash:   prevHash,
+		// Postgres хранит время с точностью до микросекунды
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	return q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		Actor:      event.Actor,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Before:     event.Before,
+		After:      event.After,
+		RequestID:  event.RequestID,
+		ClientIp:   event.ClientIp,
+		UserAgent:  event.UserAgent,
+		PrevHash:   event.PrevHash,
+		Hash:       AuditEventHash(event),
+		CreatedAt:  event.CreatedAt,
+	})
+}
+
+// AuditEventHash returns the chain hash of the event, which covers the previous event's hash
+func AuditEventHash(event AuditEvent) string {
+	data, _ := json.Marshal([]any{
+		event.PrevHash,
+		event.Actor,
+		event.Action,
+		event.TargetType,
+		event.TargetID,
+		event.Before,
+		event.After,
+		event.RequestID,
+		event.ClientIp,
+		event.UserAgent,
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditChainStatus is the result of checking the audit hash chain
+type AuditChainStatus struct {
+	Checked int64 `json:"checked"`
+	Valid   bool  `json:"valid"`
+	// BrokenAt is the first event whose hash or link doesn't match, zero when the chain is valid
+	BrokenAt int64 `json:"broken_at"`
+}
+
+// VerifyAuditChain recomputes every event's hash and checks it links to the previous event
+func VerifyAuditChain(ctx context.Context, q Querier) (AuditChainStatus, error) {
+	status := AuditChainStatus{Valid: true}
+
+	var afterID int64
+	var prevHash string
+	for {
+		events, err := q.ListAuditEventsAfter(ctx, ListAuditEventsAfterParams{
+			AfterID:  afterID,
+			RowLimit: auditVerifyBatchSize,
+		})
+		if err != nil {
+			return status, err
+		}
+
+		for _, event := range events {
+			status.Checked++
+			if event.PrevHash != prevHash || AuditEventHash(event) != event.Hash {
+				status.Valid = false
+				status.BrokenAt = event.ID
+				return status, nil
+			}
+			prevHash = event.Hash
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			return status, nil
+		}
+		afterID = events[len(events)-1].ID
+	}
+}
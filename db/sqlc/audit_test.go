@@ -0,0 +1,131 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAuditRecord() AuditRecord {
+	return AuditRecord{
+		Actor:     gofakeit.Username(),
+		Action:    "test." + gofakeit.Word(),
+		RequestID: gofakeit.UUID(),
+		ClientIP:  gofakeit.IPv4Address(),
+		UserAgent: gofakeit.UserAgent(),
+	}
+}
+
+// requireSystemAuditEvent checks that a background job logged its change of the target under SystemActor
+func requireSystemAuditEvent(t *testing.T, action, targetType, targetID string) AuditEvent {
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor:      sql.NullString{String: SystemActor, Valid: true},
+		TargetType: sql.NullString{String: targetType, Valid: true},
+		TargetID:   sql.NullString{String: targetID, Valid: true},
+		BeforeID:   1 << 62,
+		RowLimit:   10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, action, events[0].Action)
+	return events[0]
+}
+
+func TestTransferTxWritesAuditEvent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	record := randomAuditRecord()
+	result, err := store.TransferTx(WithAudit(context.Background(), record), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor:    sql.NullString{String: record.Actor, Valid: true},
+		BeforeID: 1 << 62,
+		RowLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	event := events[0]
+	require.Equal(t, record.Action, event.Action)
+	require.Equal(t, AuditTargetTransfer, event.TargetType)
+	require.Equal(t, strconv.FormatInt(result.Transfer.ID, 10), event.TargetID)
+	require.Equal(t, record.RequestID, event.RequestID)
+	require.Equal(t, record.ClientIP, event.ClientIp)
+	require.Equal(t, AuditEventHash(event), event.Hash)
+}
+
+func TestAuditTxRollsBackWithChange(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	record := randomAuditRecord()
+	errFailed := errors.New("failed")
+	err := store.AuditTx(WithAudit(context.Background(), record), func(q Querier) (AuditChange, error) {
+		_, err := q.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: account.Balance + 1})
+		require.NoError(t, err)
+		return AuditChange{TargetType: AuditTargetAccount}, errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor:    sql.NullString{String: record.Actor, Valid: true},
+		BeforeID: 1 << 62,
+		RowLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	err := store.AuditTx(WithAudit(context.Background(), randomAuditRecord()), func(q Querier) (AuditChange, error) {
+		return AuditChange{TargetType: AuditTargetAccount, TargetID: strconv.FormatInt(account.ID, 10), After: account}, nil
+	})
+	require.NoError(t, err)
+
+	_, err = testDB.Exec("UPDATE audit_events SET actor = 'intruder'")
+	require.Error(t, err)
+
+	_, err = testDB.Exec("DELETE FROM audit_events")
+	require.Error(t, err)
+
+	status, err := VerifyAuditChain(context.Background(), testQueries)
+	require.NoError(t, err)
+	require.True(t, status.Valid)
+	require.Positive(t, status.Checked)
+}
+
+func TestAuditEventHashCoversFields(t *testing.T) {
+	event := AuditEvent{
+		Actor:      "admin",
+		Action:     "account.update_overdraft_limit",
+		TargetType: AuditTargetAccount,
+		TargetID:   "1",
+		After:      []byte(`{"overdraft_limit":500}`),
+		PrevHash:   "abc",
+	}
+	hash := AuditEventHash(event)
+
+	tampered := event
+	tampered.After = []byte(`{"overdraft_limit":5000}`)
+	require.NotEqual(t, hash, AuditEventHash(tampered))
+
+	relinked := event
+	relinked.PrevHash = "abd"
+	require.NotEqual(t, hash, AuditEventHash(relinked))
+}
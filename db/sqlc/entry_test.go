@@ -83,3 +83,21 @@ func TestListEntry(t *testing.T) {
 		require.NotEmpty(t, entry)
 	}
 }
+
+func TestListEntriesAfter(t *testing.T) {
+	account := createRandomAccount(t)
+	var entries []Entry
+	for range 5 {
+		entries = append(entries, createRandomEntryWithAccountID(t, account.ID))
+	}
+
+	after, err := testQueries.ListEntriesAfter(context.Background(), ListEntriesAfterParams{
+		AccountID: account.ID,
+		AfterID:   entries[1].ID,
+		RowLimit:  2,
+	})
+	require.NoError(t, err)
+	require.Len(t, after, 2)
+	require.Equal(t, entries[2].ID, after[0].ID)
+	require.Equal(t, entries[3].ID, after[1].ID)
+}
@@ -0,0 +1,73 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"math/big"
+)
+
+const (
+	FeeKindFlat       = "flat"
+	FeeKindPercentage = "percentage"
+)
+
+const (
+	// DefaultAccountTier is the tier every account starts with and the fallback fee schedule tier
+	DefaultAccountTier = "standard"
+	// SystemAccountFeeRevenue is the purpose of the bank accounts collecting transfer fees
+	SystemAccountFeeRevenue = "fee_revenue"
+)
+
+// Calculate returns the fee charged for a transfer of the given amount
+func (schedule FeeSchedule) Calculate(amount int64) int64 {
+	var fee int64
+	switch schedule.Kind {
+	case FeeKindFlat:
+		fee = schedule.FlatAmount
+	case FeeKindPercentage:
+		fee = percentageFee(amount, schedule.PercentageBps)
+	}
+
+	if fee < schedule.MinFee {
+		fee = schedule.MinFee
+	}
+	if schedule.MaxFee > 0 && fee > schedule.MaxFee {
+		fee = schedule.MaxFee
+	}
+	return fee
+}
+
+// percentageFee returns bps basis points of amount rounded half up to the minor unit.
+// The product does not fit in int64 for large amounts, so it is computed in big.Int
+func percentageFee(amount int64, bps int64) int64 {
+	if amount <= 0 || bps <= 0 {
+		return 0
+	}
+
+	num := new(big.Int).Mul(big.NewInt(amount), big.NewInt(bps))
+	num.Add(num, big.NewInt(5000))
+	fee := num.Quo(num, big.NewInt(10000))
+	// больше 100% от суммы: такую комиссию списание всё равно не пропустит
+	if !fee.IsInt64() {
+		return math.MaxInt64
+	}
+	return fee.Int64()
+}
+
+// TransferFee looks up the fee schedule for the account's currency and tier
+// and returns the fee for transferring amount from it
+func TransferFee(ctx context.Context, q Querier, account Account, amount int64) (int64, error) {
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		Currency: account.Currency,
+		Tier:     account.Tier,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return schedule.Calculate(amount), nil
+}
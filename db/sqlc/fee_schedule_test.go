@@ -0,0 +1,171 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFeeSchedule(t *testing.T, currency, tier string) FeeSchedule {
+	arg := UpsertFeeScheduleParams{
+		Currency:      currency,
+		Tier:          tier,
+		Kind:          FeeKindPercentage,
+		PercentageBps: int64(gofakeit.Number(10, 500)),
+		MinFee:        1,
+		MaxFee:        int64(gofakeit.Number(50, 100)),
+	}
+
+	schedule, err := testQueries.UpsertFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, schedule)
+
+	require.Equal(t, arg.Currency, schedule.Currency)
+	require.Equal(t, arg.Tier, schedule.Tier)
+	require.Equal(t, arg.Kind, schedule.Kind)
+	require.Equal(t, arg.PercentageBps, schedule.PercentageBps)
+	require.Equal(t, arg.MinFee, schedule.MinFee)
+	require.Equal(t, arg.MaxFee, schedule.MaxFee)
+	require.NotZero(t, schedule.ID)
+	require.NotZero(t, schedule.CreatedAt)
+
+	return schedule
+}
+
+func createRandomAccountWithTier(t *testing.T, tier string) Account {
+	account := createRandomAccount(t)
+
+	account, err := testQueries.UpdateAccountTier(context.Background(), UpdateAccountTierParams{
+		ID:   account.ID,
+		Tier: tier,
+	})
+	require.NoError(t, err)
+	require.Equal(t, tier, account.Tier)
+
+	return account
+}
+
+func TestUpsertFeeSchedule(t *testing.T) {
+	tier := gofakeit.LetterN(12)
+	schedule1 := createRandomFeeSchedule(t, util.RandomCurrency(), tier)
+
+	arg := UpsertFeeScheduleParams{
+		Currency:   schedule1.Currency,
+		Tier:       schedule1.Tier,
+		Kind:       FeeKindFlat,
+		FlatAmount: int64(gofakeit.Number(1, 10)),
+	}
+
+	schedule2, err := testQueries.UpsertFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, schedule1.ID, schedule2.ID)
+	require.Equal(t, FeeKindFlat, schedule2.Kind)
+	require.Equal(t, arg.FlatAmount, schedule2.FlatAmount)
+	require.Zero(t, schedule2.PercentageBps)
+}
+
+func TestGetFeeSchedule(t *testing.T) {
+	tier := gofakeit.LetterN(12)
+	schedule1 := createRandomFeeSchedule(t, util.RandomCurrency(), tier)
+
+	schedule2, err := testQueries.GetFeeSchedule(context.Background(), GetFeeScheduleParams{
+		Currency: schedule1.Currency,
+		Tier:     tier,
+	})
+	require.NoError(t, err)
+	require.Equal(t, schedule1, schedule2)
+}
+
+func TestGetFeeScheduleByTier(t *testing.T) {
+	tier := gofakeit.LetterN(12)
+	schedule1 := createRandomFeeSchedule(t, util.RandomCurrency(), tier)
+
+	schedule2, err := testQueries.GetFeeScheduleByTier(context.Background(), GetFeeScheduleByTierParams{
+		Currency: schedule1.Currency,
+		Tier:     tier,
+	})
+	require.NoError(t, err)
+	require.Equal(t, schedule1, schedule2)
+
+	// в отличие от GetFeeSchedule, без отката на standard
+	_, err = testQueries.GetFeeScheduleByTier(context.Background(), GetFeeScheduleByTierParams{
+		Currency: schedule1.Currency,
+		Tier:     gofakeit.LetterN(12),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTransferFee(t *testing.T) {
+	tier := gofakeit.LetterN(12)
+	account := createRandomAccountWithTier(t, tier)
+	schedule := createRandomFeeSchedule(t, account.Currency, tier)
+
+	amount := int64(gofakeit.Number(100, 100000))
+	fee, err := TransferFee(context.Background(), testQueries, account, amount)
+	require.NoError(t, err)
+	require.Equal(t, schedule.Calculate(amount), fee)
+}
+
+func TestFeeScheduleCalculate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule FeeSchedule
+		amount   int64
+		fee      int64
+	}{
+		{
+			name:     "Flat",
+			schedule: FeeSchedule{Kind: FeeKindFlat, FlatAmount: 30},
+			amount:   1000,
+			fee:      30,
+		},
+		{
+			name:     "Percentage",
+			schedule: FeeSchedule{Kind: FeeKindPercentage, PercentageBps: 150},
+			amount:   10000,
+			fee:      150,
+		},
+		{
+			name:     "PercentageRounding",
+			schedule: FeeSchedule{Kind: FeeKindPercentage, PercentageBps: 150},
+			amount:   1033,
+			fee:      15,
+		},
+		{
+			name:     "PercentageOfMaxAmount",
+			schedule: FeeSchedule{Kind: FeeKindPercentage, PercentageBps: 150},
+			amount:   math.MaxInt64,
+			fee:      138350580552821637,
+		},
+		{
+			name:     "MinFee",
+			schedule: FeeSchedule{Kind: FeeKindPercentage, PercentageBps: 100, MinFee: 50},
+			amount:   1000,
+			fee:      50,
+		},
+		{
+			name:     "MaxFee",
+			schedule: FeeSchedule{Kind: FeeKindPercentage, PercentageBps: 100, MaxFee: 500},
+			amount:   1000000,
+			fee:      500,
+		},
+		{
+			name:     "NoMaxFee",
+			schedule: FeeSchedule{Kind: FeeKindPercentage, PercentageBps: 100},
+			amount:   1000000,
+			fee:      10000,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.fee, tc.schedule.Calculate(tc.amount))
+		})
+	}
+}
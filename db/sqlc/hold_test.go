@@ -0,0 +1,146 @@
+package sqlc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeRandomHold(t *testing.T, store Store, account, to Account, amount int64, expiresAt time.Time) HoldTxResult {
+	result, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID:   account.ID,
+		ToAccountID: to.ID,
+		Amount:      amount,
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, HoldAuthorized, result.Hold.Status)
+	require.Equal(t, amount, result.Hold.Amount)
+	require.Equal(t, account.Balance, result.Account.Balance)
+	require.Equal(t, account.HeldAmount+amount, result.Account.HeldAmount)
+	require.Equal(t, account.AvailableBalance-amount, result.Account.AvailableBalance)
+
+	return result
+}
+
+func TestAuthorizeHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	authorizeRandomHold(t, store, account1, account2, 5, time.Now().Add(time.Hour))
+
+	_, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      account1.Balance,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureHoldTxPartial(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, store, account1, account2, 8, time.Now().Add(time.Hour)).Hold
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: 5,
+		Now:    time.Now(),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.Equal(t, int64(5), result.Hold.CapturedAmount)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Hold.TransferID.Int64)
+	require.Equal(t, int64(0), result.Transfer.FromAccount.HeldAmount)
+	require.Equal(t, account1.Balance-5-result.Transfer.Transfer.Fee, result.Transfer.FromAccount.Balance)
+	require.Equal(t, account2.Balance+5, result.Transfer.ToAccount.Balance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Now:    time.Now(),
+	})
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+}
+
+func TestCaptureHoldTxExceedsHold(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, store, account1, account2, 5, time.Now().Add(time.Hour)).Hold
+
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: 6,
+		Now:    time.Now(),
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, store, account1, account2, 5, time.Now().Add(time.Hour)).Hold
+
+	result, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, result.Hold.Status)
+	require.Equal(t, account1.AvailableBalance, result.Account.AvailableBalance)
+	require.Equal(t, account1.Balance, result.Account.Balance)
+}
+
+func TestExpireHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	expiresAt := time.Now().Add(time.Hour)
+	hold := authorizeRandomHold(t, store, account1, account2, 5, expiresAt).Hold
+
+	_, err := store.ExpireHoldTx(context.Background(), ExpireHoldTxParams{
+		HoldID: hold.ID,
+		Now:    time.Now(),
+	})
+	require.ErrorIs(t, err, ErrHoldNotExpired)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Now:    expiresAt,
+	})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	result, err := store.ExpireHoldTx(context.Background(), ExpireHoldTxParams{
+		HoldID: hold.ID,
+		Now:    expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldExpired, result.Hold.Status)
+	require.Equal(t, account1.AvailableBalance, result.Account.AvailableBalance)
+	requireSystemAuditEvent(t, "hold.expire", AuditTargetHold, strconv.FormatInt(hold.ID, 10))
+}
+
+func TestTransferTxRespectsHolds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	authorizeRandomHold(t, store, account1, account2, account1.Balance-1, time.Now().Add(time.Hour))
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        2,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
@@ -0,0 +1,221 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	ProductChecking    = "checking"
+	ProductSavings     = "savings"
+	ProductTermDeposit = "term_deposit"
+
+	// SystemAccountInterestExpense is the purpose of the bank accounts paying interest to depositors
+	SystemAccountInterestExpense = "interest_expense"
+
+	// MicrosPerUnit is how many accrual micro-units make one minor unit of the currency
+	MicrosPerUnit = 1_000_000
+)
+
+var (
+	// ErrInterestAlreadyAccrued is returned when the account already accrued interest for the date
+	ErrInterestAlreadyAccrued = errors.New("interest already accrued for the date")
+	// ErrInterestPeriodPosted is returned when the account's interest for the month was already paid out
+	ErrInterestPeriodPosted = errors.New("interest already posted for the period")
+)
+
+// DailyInterestMicros returns one day of interest on a positive balance at the given annual rate
+// in basis points, in micro-units rounded half to even
+func DailyInterestMicros(balance int64, annualRateBps int64) int64 {
+	if balance <= 0 || annualRateBps <= 0 {
+		return 0
+	}
+
+	num := new(big.Int).Mul(big.NewInt(balance), big.NewInt(annualRateBps))
+	num.Mul(num, big.NewInt(MicrosPerUnit))
+	return roundHalfEven(num, big.NewInt(10000*365)).Int64()
+}
+
+// roundHalfEven divides non-negative num by den rounding ties to the even quotient
+func roundHalfEven(num, den *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	switch rem.Lsh(rem, 1).Cmp(den) {
+	case 1:
+		quo.Add(quo, big.NewInt(1))
+	case 0:
+		if quo.Bit(0) == 1 {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
+
+// InterestPeriod returns the first day of the month t is in, UTC
+func InterestPeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+type AccrueInterestTxParams struct {
+	AccountID int64     `json:"account_id"`
+	Date      time.Time `json:"date"`
+}
+
+type AccrueInterestTxResult struct {
+	Accrual InterestAccrual `json:"accrual"`
+	Account Account         `json:"account"`
+}
+
+// AccrueInterestTx adds one day of interest to the account's accrued amount without paying it out.
+// Accruals are keyed by account and date, so a re-run for the same date accrues nothing.
+// Nothing accrues for empty accounts, products without a rate and matured term deposits.
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (AccrueInterestTxResult, error) {
+	var result AccrueInterestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		result.Account = account
+
+		if account.MaturesAt.Valid && !arg.Date.Before(account.MaturesAt.Time) {
+			return nil
+		}
+
+		product, err := q.GetAccountProduct(ctx, account.Product)
+		if err != nil {
+			return err
+		}
+
+		amount := DailyInterestMicros(account.Balance, product.AnnualRateBps)
+		if amount == 0 {
+			return nil
+		}
+
+		result.Accrual, err = q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+			AccountID:     account.ID,
+			AccrualDate:   arg.Date,
+			Balance:       account.Balance,
+			AnnualRateBps: product.AnnualRateBps,
+			AmountMicros:  amount,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInterestAlreadyAccrued
+			}
+			return err
+		}
+
+		result.Account, err = q.AddAccountAccruedInterest(ctx, AddAccountAccruedInterestParams{
+			Amount: amount,
+			ID:     account.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type PostInterestTxParams struct {
+	AccountID int64     `json:"account_id"`
+	Period    time.Time `json:"period"`
+}
+
+type PostInterestTxResult struct {
+	Posting      InterestPosting `json:"posting"`
+	Account      Account         `json:"account"`
+	Entry        Entry           `json:"entry"`
+	JournalEntry JournalEntry    `json:"journal_entry"`
+}
+
+// PostInterestTx pays the whole minor units of the accrued interest out of the bank's expense account.
+// The fraction below one minor unit stays accrued for the next period.
+// Postings are keyed by account and period, so each month is paid once.
+func (store *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		result.Account = account
+
+		amount := account.AccruedInterestMicros / MicrosPerUnit
+		if amount == 0 {
+			return nil
+		}
+
+		expense, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Purpose:  SystemAccountInterestExpense,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("no interest expense account for currency %s", account.Currency)
+			}
+			return err
+		}
+
+		_, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: expense.AccountID,
+			Amount:    -amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Posting, err = q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID: account.ID,
+			Period:    arg.Period,
+			Amount:    amount,
+			EntryID:   result.Entry.ID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInterestPeriodPosted
+			}
+			return err
+		}
+
+		accounts, err := addMoney(ctx, q, map[int64]int64{
+			expense.AccountID: -amount,
+			account.ID:        amount,
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		result.JournalEntry, err = postJournal(ctx, q, CreateJournalEntryParams{
+			Kind: JournalInterest,
+		}, []PostingLine{
+			accountPosting(accounts[expense.AccountID], -amount),
+			accountPosting(accounts[account.ID], amount),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountAccruedInterest(ctx, AddAccountAccruedInterestParams{
+			Amount: -amount * MicrosPerUnit,
+			ID:     account.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
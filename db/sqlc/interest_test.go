@@ -0,0 +1,140 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundHalfEven(t *testing.T) {
+	testCases := []struct {
+		num, den, want int64
+	}{
+		{num: 5, den: 2, want: 2},
+		{num: 7, den: 2, want: 4},
+		{num: 5, den: 4, want: 1},
+		{num: 7, den: 4, want: 2},
+		{num: 6, den: 3, want: 2},
+		{num: 0, den: 3, want: 0},
+	}
+
+	for _, tc := range testCases {
+		got := roundHalfEven(big.NewInt(tc.num), big.NewInt(tc.den))
+		require.Equal(t, tc.want, got.Int64(), "%d/%d", tc.num, tc.den)
+	}
+}
+
+func TestDailyInterestMicros(t *testing.T) {
+	require.Zero(t, DailyInterestMicros(0, 300))
+	require.Zero(t, DailyInterestMicros(-100, 300))
+	require.Zero(t, DailyInterestMicros(100, 0))
+	// 365 * 100% / 365 = 1
+	require.Equal(t, int64(MicrosPerUnit), DailyInterestMicros(365, 10000))
+	// 1000 * 3% / 365 = 0.08219178...
+	require.Equal(t, int64(82192), DailyInterestMicros(1000, 300))
+}
+
+func TestInterestPeriod(t *testing.T) {
+	got := InterestPeriod(time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), got)
+}
+
+func createRandomSavingsAccount(t *testing.T, maturesAt sql.NullTime) Account {
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     user.Username,
+		Balance:   3650000,
+		Currency:  "USD",
+		Product:   sql.NullString{String: ProductSavings, Valid: true},
+		MaturesAt: maturesAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ProductSavings, account.Product)
+
+	return account
+}
+
+func TestAccrueInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	product, err := testQueries.GetAccountProduct(context.Background(), ProductSavings)
+	require.NoError(t, err)
+
+	account := createRandomSavingsAccount(t, sql.NullTime{})
+	arg := AccrueInterestTxParams{
+		AccountID: account.ID,
+		Date:      time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	want := DailyInterestMicros(account.Balance, product.AnnualRateBps)
+
+	result, err := store.AccrueInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, want, result.Accrual.AmountMicros)
+	require.Equal(t, want, result.Account.AccruedInterestMicros)
+	require.Equal(t, account.Balance, result.Account.Balance)
+
+	// повторный запуск за ту же дату ничего не начисляет
+	_, err = store.AccrueInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInterestAlreadyAccrued)
+
+	account, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, want, account.AccruedInterestMicros)
+}
+
+func TestAccrueInterestTxMatured(t *testing.T) {
+	store := NewStore(testDB)
+
+	maturesAt := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	account := createRandomSavingsAccount(t, sql.NullTime{Time: maturesAt, Valid: true})
+
+	result, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+		AccountID: account.ID,
+		Date:      maturesAt,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Accrual.AmountMicros)
+	require.Zero(t, result.Account.AccruedInterestMicros)
+}
+
+func TestPostInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomSavingsAccount(t, sql.NullTime{})
+	account, err := testQueries.AddAccountAccruedInterest(context.Background(), AddAccountAccruedInterestParams{
+		Amount: 12*MicrosPerUnit + 345,
+		ID:     account.ID,
+	})
+	require.NoError(t, err)
+
+	arg := PostInterestTxParams{
+		AccountID: account.ID,
+		Period:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	result, err := store.PostInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(12), result.Posting.Amount)
+	require.Equal(t, int64(12), result.Entry.Amount)
+	require.Equal(t, account.Balance+12, result.Account.Balance)
+	// доля меньше минимальной единицы остаётся на следующий месяц
+	require.Equal(t, int64(345), result.Account.AccruedInterestMicros)
+
+	_, err = testQueries.AddAccountAccruedInterest(context.Background(), AddAccountAccruedInterestParams{
+		Amount: MicrosPerUnit,
+		ID:     account.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = store.PostInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInterestPeriodPosted)
+
+	account, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Account.Balance, account.Balance)
+}
@@ -0,0 +1,91 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Internal GL accounts of the chart of accounts
+const (
+	GLCash             = "cash"
+	GLSuspense         = "suspense"
+	GLCustomerDeposits = "customer_deposits"
+	GLRevenue          = "revenue"
+	GLExpense          = "expense"
+)
+
+const (
+	JournalTransfer          = "transfer"
+	JournalInterest          = "interest"
+	JournalOverdraftInterest = "overdraft_interest"
+	JournalOpeningBalance    = "opening_balance"
+)
+
+// ErrUnbalancedJournal is returned when the postings of a journal don't sum to zero in every currency
+var ErrUnbalancedJournal = errors.New("journal postings are not balanced")
+
+// PostingLine is one side of a journal entry before it is written
+type PostingLine struct {
+	GLAccount string
+	AccountID sql.NullInt64
+	Currency  string
+	// Amount credits the GL account when positive and debits it when negative
+	Amount int64
+}
+
+// accountPosting books amount on the GL account the account's balance is carried in
+func accountPosting(account Account, amount int64) PostingLine {
+	return PostingLine{
+		GLAccount: account.GlAccount,
+		AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+		Currency:  account.Currency,
+		Amount:    amount,
+	}
+}
+
+// Balanced reports whether the lines sum to zero in every currency
+func Balanced(lines []PostingLine) bool {
+	sums := make(map[string]int64)
+	for _, line := range lines {
+		sums[line.Currency] += line.Amount
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// postJournal writes a journal entry with its postings. Zero lines are skipped.
+// The database checks the balance again when the transaction commits.
+func postJournal(ctx context.Context, q *Queries, arg CreateJournalEntryParams, lines []PostingLine) (JournalEntry, error) {
+	if !Balanced(lines) {
+		return JournalEntry{}, ErrUnbalancedJournal
+	}
+
+	journal, err := q.CreateJournalEntry(ctx, arg)
+	if err != nil {
+		return journal, err
+	}
+
+	for _, line := range lines {
+		if line.Amount == 0 {
+			continue
+		}
+
+		_, err = q.CreatePosting(ctx, CreatePostingParams{
+			JournalEntryID: journal.ID,
+			GlAccount:      line.GLAccount,
+			AccountID:      line.AccountID,
+			Currency:       line.Currency,
+			Amount:         line.Amount,
+		})
+		if err != nil {
+			return journal, err
+		}
+	}
+
+	return journal, nil
+}
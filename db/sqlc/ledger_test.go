@@ -0,0 +1,90 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanced(t *testing.T) {
+	require.True(t, Balanced([]PostingLine{
+		{Currency: "USD", Amount: -10},
+		{Currency: "USD", Amount: 10},
+		{Currency: "EUR", Amount: 5},
+		{Currency: "EUR", Amount: -5},
+	}))
+	require.False(t, Balanced([]PostingLine{
+		{Currency: "USD", Amount: -10},
+		{Currency: "EUR", Amount: 10},
+	}))
+}
+
+func TestTransferTxPostsJournal(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, JournalTransfer, result.JournalEntry.Kind)
+	require.Equal(t, result.Transfer.ID, result.JournalEntry.TransferID.Int64)
+
+	postings, err := testQueries.ListPostingsByJournalEntry(context.Background(), result.JournalEntry.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(postings), 2)
+
+	var sum int64
+	for _, posting := range postings {
+		require.Equal(t, GLCustomerDeposits, posting.GlAccount)
+		sum += posting.Amount
+	}
+	require.Zero(t, sum)
+}
+
+func TestUnbalancedJournalRejected(t *testing.T) {
+	account := createRandomAccount(t)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	q := New(tx)
+
+	_, err = postJournal(context.Background(), q, CreateJournalEntryParams{Kind: JournalTransfer}, []PostingLine{
+		accountPosting(account, 10),
+	})
+	require.ErrorIs(t, err, ErrUnbalancedJournal)
+
+	// запись в обход postJournal отклоняет отложенный триггер при коммите
+	journal, err := q.CreateJournalEntry(context.Background(), CreateJournalEntryParams{Kind: JournalTransfer})
+	require.NoError(t, err)
+	_, err = q.CreatePosting(context.Background(), CreatePostingParams{
+		JournalEntryID: journal.ID,
+		GlAccount:      GLSuspense,
+		AccountID:      sql.NullInt64{},
+		Currency:       account.Currency,
+		Amount:         10,
+	})
+	require.NoError(t, err)
+
+	require.Error(t, tx.Commit())
+}
+
+func TestGetTrialBalance(t *testing.T) {
+	rows, err := testQueries.GetTrialBalance(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	debits := make(map[string]int64)
+	credits := make(map[string]int64)
+	for _, row := range rows {
+		debits[row.Currency] += row.Debit
+		credits[row.Currency] += row.Credit
+	}
+	require.Equal(t, debits, credits)
+}
@@ -16,7 +16,7 @@ var (
 )
 
 func TestMain(m *testing.M) {
-	config, err := config.LoadConfig()
+	config, err := config.LoadConfig("../..")
 	if err != nil {
 		log.Fatal("cannot load config", err)
 	}
@@ -5,17 +5,63 @@
 package sqlc
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// ledger balance, includes money reserved by holds
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	Tier      string    `json:"tier"`
+	// sum of authorized holds
+	HeldAmount int64 `json:"held_amount"`
+	// balance that can be spent: balance minus held_amount
+	AvailableBalance int64 `json:"available_balance"`
+	// how far below zero the available balance may go
+	OverdraftLimit int64  `json:"overdraft_limit"`
+	Product        string `json:"product"`
+	// only set for term deposits, no interest accrues after it
+	MaturesAt sql.NullTime `json:"matures_at"`
+	// interest accrued but not posted yet, in millionths of the minor unit
+	AccruedInterestMicros int64 `json:"accrued_interest_micros"`
+	// GL account the balance is carried in
+	GlAccount string `json:"gl_account"`
+}
+
+type AccountProduct struct {
+	Code string `json:"code"`
+	// checking, savings or term_deposit
+	Kind string `json:"kind"`
+	// annual interest rate in basis points
+	AnnualRateBps int64     `json:"annual_rate_bps"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// username that made the change
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	// json, not jsonb, so the hashed text is kept byte for byte
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	ClientIp  string          `json:"client_ip"`
+	UserAgent string          `json:"user_agent"`
+	// hash of the previous event, empty for the first one
+	PrevHash string `json:"prev_hash"`
+	// sha256 of prev_hash and the event fields
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -24,6 +70,191 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer the entry was booked for, null for interest
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type FeeSchedule struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	Tier     string `json:"tier"`
+	// flat or percentage
+	Kind       string `json:"kind"`
+	FlatAmount int64  `json:"flat_amount"`
+	// basis points of the transfer amount
+	PercentageBps int64 `json:"percentage_bps"`
+	MinFee        int64 `json:"min_fee"`
+	// 0 means no upper bound
+	MaxFee    int64     `json:"max_fee"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GlAccount struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// asset, liability, equity, revenue or expense
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Hold struct {
+	ID          int64 `json:"id"`
+	AccountID   int64 `json:"account_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	// must be positive
+	Amount         int64 `json:"amount"`
+	CapturedAmount int64 `json:"captured_amount"`
+	// authorized, captured, voided or expired
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+type InterestAccrual struct {
+	AccountID     int64     `json:"account_id"`
+	AccrualDate   time.Time `json:"accrual_date"`
+	Balance       int64     `json:"balance"`
+	AnnualRateBps int64     `json:"annual_rate_bps"`
+	AmountMicros  int64     `json:"amount_micros"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type InterestPosting struct {
+	AccountID int64 `json:"account_id"`
+	// first day of the month the interest was accrued in
+	Period    time.Time `json:"period"`
+	Amount    int64     `json:"amount"`
+	EntryID   int64     `json:"entry_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type JournalEntry struct {
+	ID int64 `json:"id"`
+	// what produced the journal: transfer, interest, overdraft_interest or opening_balance
+	Kind        string        `json:"kind"`
+	TransferID  sql.NullInt64 `json:"transfer_id"`
+	Description string        `json:"description"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type Outbox struct {
+	ID int64 `json:"id"`
+	// transfer, account or user
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   string `json:"aggregate_id"`
+	// full protobuf message name of the payload, e.g. pb.TransferCreated
+	EventType string `json:"event_type"`
+	// protobuf encoded event
+	Payload       []byte    `json:"payload"`
+	Attempts      int64     `json:"attempts"`
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// null until the relay has handed the event to the publisher
+	PublishedAt sql.NullTime `json:"published_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type OverdraftInterestPosting struct {
+	AccountID   int64     `json:"account_id"`
+	PostingDate time.Time `json:"posting_date"`
+	// negative balance the interest was charged on
+	Balance   int64     `json:"balance"`
+	Amount    int64     `json:"amount"`
+	EntryID   int64     `json:"entry_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Posting struct {
+	ID             int64  `json:"id"`
+	JournalEntryID int64  `json:"journal_entry_id"`
+	GlAccount      string `json:"gl_account"`
+	// customer or bank account the posting belongs to, null for pure GL postings
+	AccountID sql.NullInt64 `json:"account_id"`
+	Currency  string        `json:"currency"`
+	// positive credits the GL account, negative debits it
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// token buckets shared by all instances, unlogged because losing them on a crash only resets the limits
+type RateLimit struct {
+	// route and caller the bucket belongs to
+	Key string `json:"key"`
+	// theoretical arrival time of the next request, the bucket is full once it is in the past
+	Tat time.Time `json:"tat"`
+}
+
+type ReconciliationDiscrepancy struct {
+	ID    int64 `json:"id"`
+	RunID int64 `json:"run_id"`
+	// balance_mismatch, missing_transfer_entry, unbalanced_transfer or orphaned_entry
+	Kind       string        `json:"kind"`
+	AccountID  sql.NullInt64 `json:"account_id"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	EntryID    sql.NullInt64 `json:"entry_id"`
+	Currency   string        `json:"currency"`
+	Expected   int64         `json:"expected"`
+	Actual     int64         `json:"actual"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type ReconciliationRun struct {
+	ID int64 `json:"id"`
+	// running, completed or failed
+	Status           string       `json:"status"`
+	DiscrepancyCount int64        `json:"discrepancy_count"`
+	Error            string       `json:"error"`
+	StartedAt        time.Time    `json:"started_at"`
+	FinishedAt       sql.NullTime `json:"finished_at"`
+}
+
+type ReversalRequest struct {
+	ID          int64  `json:"id"`
+	TransferID  int64  `json:"transfer_id"`
+	Amount      int64  `json:"amount"`
+	Reason      string `json:"reason"`
+	RequestedBy string `json:"requested_by"`
+	// pending, completed or rejected
+	Status             string         `json:"status"`
+	ReviewedBy         sql.NullString `json:"reviewed_by"`
+	ReversalTransferID sql.NullInt64  `json:"reversal_transfer_id"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// standard 5-field cron expression, UTC
+	Recurrence string       `json:"recurrence"`
+	StartAt    time.Time    `json:"start_at"`
+	EndAt      sql.NullTime `json:"end_at"`
+	// occurrence that has to be executed next
+	NextRunAt time.Time `json:"next_run_at"`
+	// when the worker picks the occurrence up, later than next_run_at while retrying
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	Status        string    `json:"status"`
+	// consecutive occurrences that failed after all retries
+	FailureCount int64     `json:"failure_count"`
+	LastError    string    `json:"last_error"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64         `json:"id"`
+	ScheduledTransferID int64         `json:"scheduled_transfer_id"`
+	OccurrenceAt        time.Time     `json:"occurrence_at"`
+	Status              string        `json:"status"`
+	Attempts            int64         `json:"attempts"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	LastError           string        `json:"last_error"`
+	CreatedAt           time.Time     `json:"created_at"`
+	UpdatedAt           time.Time     `json:"updated_at"`
 }
 
 type Session struct {
@@ -37,6 +268,32 @@ type Session struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+type SystemAccount struct {
+	Purpose   string `json:"purpose"`
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
+}
+
+type Task struct {
+	ID    int64  `json:"id"`
+	Queue string `json:"queue"`
+	// name the handler is registered under
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	// pending, running, succeeded or dead
+	Status      string `json:"status"`
+	Attempts    int64  `json:"attempts"`
+	MaxAttempts int64  `json:"max_attempts"`
+	// the task is not claimed before this time, also used for retry backoff
+	RunAt time.Time `json:"run_at"`
+	// lease of the worker running the task, an expired lease means the worker died
+	LockedUntil sql.NullTime `json:"locked_until"`
+	LastError   string       `json:"last_error"`
+	CompletedAt sql.NullTime `json:"completed_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
@@ -44,6 +301,12 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// charged to the sender on top of amount
+	Fee int64 `json:"fee"`
+	// transfer this one compensates
+	ReversalOf sql.NullInt64 `json:"reversal_of"`
+	// sum of the reversals of this transfer
+	RefundedAmount int64 `json:"refunded_amount"`
 }
 
 type User struct {
@@ -53,4 +316,44 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// depositor or banker
+	Role string `json:"role"`
+}
+
+type WebhookDelivery struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	// same for every delivery and replay of one event, so receivers can drop duplicates
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	// pending, succeeded or failed
+	Status        string    `json:"status"`
+	Attempts      int64     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// HTTP status of the last attempt, 0 when no response was received
+	ResponseStatus int64  `json:"response_status"`
+	LastError      string `json:"last_error"`
+	// delivery this one replays
+	ReplayOf    sql.NullInt64 `json:"replay_of"`
+	DeliveredAt sql.NullTime  `json:"delivered_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+type WebhookSubscription struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// transfer.received, transfer.sent or account.created
+	EventTypes []string `json:"event_types"`
+	// key of the HMAC-SHA256 payload signature
+	Secret string `json:"secret"`
+	// active or disabled
+	Status string `json:"status"`
+	// failed attempts in a row, the endpoint is disabled when it reaches the limit
+	FailureCount int64        `json:"failure_count"`
+	DisabledAt   sql.NullTime `json:"disabled_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
 }
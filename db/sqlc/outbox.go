@@ -0,0 +1,145 @@
+package sqlc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hisshihi/simple-bank/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	AggregateTransfer = "transfer"
+	AggregateAccount  = "account"
+	AggregateUser     = "user"
+)
+
+// outboxRelayLockKey is the advisory lock held by the outbox relay publishing a batch
+const outboxRelayLockKey = 0x6f757462
+
+// OutboxRelayTx runs fn in a transaction holding the outbox relay lock, so relays of several
+// instances never publish the same events. When another relay holds the lock fn is not run
+// and OutboxRelayTx returns false
+func (store *SQLStore) OutboxRelayTx(ctx context.Context, fn func(q Querier) error) (bool, error) {
+	var locked bool
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		locked, err = q.TryLockOutboxRelay(ctx, outboxRelayLockKey)
+		if err != nil || !locked {
+			return err
+		}
+		return fn(q)
+	})
+	return locked, err
+}
+
+// enqueueEvent writes the event to the outbox within the caller's transaction,
+// so it is published only if the change that produced it commits
+func enqueueEvent(ctx context.Context, q *Queries, aggregateType, aggregateID string, event proto.Message) error {
+	payload, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     string(event.ProtoReflect().Descriptor().FullName()),
+		Payload:       payload,
+	})
+	return err
+}
+
+// enqueueTransferCreated publishes the transfer under the aggregate of the transfer it reverses,
+// so consumers see a reversal after the original transfer
+func enqueueTransferCreated(ctx context.Context, q *Queries, transfer Transfer, currency string) error {
+	aggregateID := transfer.ID
+	if transfer.ReversalOf.Valid {
+		aggregateID = transfer.ReversalOf.Int64
+	}
+
+	return enqueueEvent(ctx, q, AggregateTransfer, strconv.FormatInt(aggregateID, 10), &pb.TransferCreated{
+		TransferId:    transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Fee:           transfer.Fee,
+		Currency:      currency,
+		ReversalOf:    transfer.ReversalOf.Int64,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	})
+}
+
+// CreateAccountTx creates the account, publishes AccountCreated and notifies the owner's webhooks
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		accountID := strconv.FormatInt(account.ID, 10)
+		err = enqueueEvent(ctx, q, AggregateAccount, accountID, &pb.AccountCreated{
+			AccountId: account.ID,
+			Owner:     account.Owner,
+			Currency:  account.Currency,
+			Product:   account.Product,
+			CreatedAt: timestamppb.New(account.CreatedAt),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = enqueueWebhooks(ctx, q, account.Owner, NewWebhookEvent(WebhookAccountCreated, WebhookAccount{
+			AccountID: account.ID,
+			Currency:  account.Currency,
+			Product:   account.Product,
+			CreatedAt: account.CreatedAt,
+		}))
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, AuditChange{
+			TargetType: AuditTargetAccount,
+			TargetID:   accountID,
+			After:      account,
+		})
+		return err
+	})
+
+	return account, err
+}
+
+// CreateUserTx creates the user and publishes UserCreated
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		err = enqueueEvent(ctx, q, AggregateUser, user.Username, &pb.UserCreated{
+			Username:  user.Username,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			Role:      user.Role,
+			CreatedAt: timestamppb.New(user.CreatedAt),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, UserAuditChange(user))
+		return err
+	})
+
+	return user, err
+}
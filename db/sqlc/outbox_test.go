@@ -0,0 +1,95 @@
+package sqlc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hisshihi/simple-bank/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// findOutboxEvent returns the pending outbox event of the aggregate
+func findOutboxEvent(t *testing.T, aggregateType, aggregateID string) Outbox {
+	events, err := testQueries.ListDueOutboxEvents(context.Background(), ListDueOutboxEventsParams{
+		Now:      time.Now().Add(time.Minute),
+		RowLimit: 1 << 20,
+	})
+	require.NoError(t, err)
+
+	for _, event := range events {
+		if event.AggregateType == aggregateType && event.AggregateID == aggregateID {
+			return event
+		}
+	}
+	t.Fatalf("no outbox event for %s %s", aggregateType, aggregateID)
+	return Outbox{}
+}
+
+func TestTransferTxWritesOutboxEvent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	outbox := findOutboxEvent(t, AggregateTransfer, strconv.FormatInt(result.Transfer.ID, 10))
+	require.Equal(t, "pb.TransferCreated", outbox.EventType)
+
+	var event pb.TransferCreated
+	require.NoError(t, proto.Unmarshal(outbox.Payload, &event))
+	require.Equal(t, result.Transfer.ID, event.GetTransferId())
+	require.Equal(t, int64(5), event.GetAmount())
+	require.Equal(t, account1.Currency, event.GetCurrency())
+}
+
+func TestCreateAccountTxWritesOutboxEvent(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	outbox := findOutboxEvent(t, AggregateAccount, strconv.FormatInt(account.ID, 10))
+
+	var event pb.AccountCreated
+	require.NoError(t, proto.Unmarshal(outbox.Payload, &event))
+	require.Equal(t, user.Username, event.GetOwner())
+	require.Equal(t, ProductChecking, event.GetProduct())
+
+	err = testQueries.MarkOutboxEventPublished(context.Background(), outbox.ID)
+	require.NoError(t, err)
+}
+
+func TestOutboxRelayTxIsExclusive(t *testing.T) {
+	store := NewStore(testDB)
+
+	locked, err := store.OutboxRelayTx(context.Background(), func(q Querier) error {
+		// второе реле берёт другое соединение из пула и не получает блокировку
+		locked, err := store.OutboxRelayTx(context.Background(), func(q Querier) error {
+			t.Fatal("second relay must not run while the first holds the lock")
+			return nil
+		})
+		require.NoError(t, err)
+		require.False(t, locked)
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	// блокировка снимается вместе с транзакцией
+	locked, err = store.OutboxRelayTx(context.Background(), func(q Querier) error { return nil })
+	require.NoError(t, err)
+	require.True(t, locked)
+}
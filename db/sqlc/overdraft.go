@@ -0,0 +1,129 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SystemAccountInterestIncome is the purpose of the bank accounts collecting overdraft interest
+const SystemAccountInterestIncome = "interest_income"
+
+// ErrInterestAlreadyPosted is returned when the account was already charged interest for the day
+var ErrInterestAlreadyPosted = errors.New("overdraft interest already posted for the day")
+
+// SpendableBalance returns how much can leave the account, including its overdraft
+func (account Account) SpendableBalance() int64 {
+	return account.AvailableBalance + account.OverdraftLimit
+}
+
+// Overdrawn reports whether the account went past its overdraft limit
+func (account Account) Overdrawn() bool {
+	return account.SpendableBalance() < 0
+}
+
+// OverdraftInterest returns one day of interest on a negative balance at the given annual rate in basis points
+func OverdraftInterest(balance int64, annualRateBps int64) int64 {
+	if balance >= 0 || annualRateBps <= 0 {
+		return 0
+	}
+	// округляем до ближайшей минимальной единицы валюты
+	const denominator = 10000 * 365
+	return (-balance*annualRateBps + denominator/2) / denominator
+}
+
+type PostOverdraftInterestTxParams struct {
+	AccountID     int64     `json:"account_id"`
+	Date          time.Time `json:"date"`
+	AnnualRateBps int64     `json:"annual_rate_bps"`
+}
+
+type PostOverdraftInterestTxResult struct {
+	Posting      OverdraftInterestPosting `json:"posting"`
+	Account      Account                  `json:"account"`
+	Entry        Entry                    `json:"entry"`
+	JournalEntry JournalEntry             `json:"journal_entry"`
+}
+
+// PostOverdraftInterestTx charges one day of interest on the account's negative balance.
+// Postings are keyed by account and date, so running the job twice a day charges once.
+// Interest is charged even when it takes the account past its overdraft limit.
+func (store *SQLStore) PostOverdraftInterestTx(ctx context.Context, arg PostOverdraftInterestTxParams) (PostOverdraftInterestTxResult, error) {
+	var result PostOverdraftInterestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		interest := OverdraftInterest(account.Balance, arg.AnnualRateBps)
+		if interest == 0 {
+			result.Account = account
+			return nil
+		}
+
+		income, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Purpose:  SystemAccountInterestIncome,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("no interest income account for currency %s", account.Currency)
+			}
+			return err
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    -interest,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: income.AccountID,
+			Amount:    interest,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Posting, err = q.CreateOverdraftInterestPosting(ctx, CreateOverdraftInterestPostingParams{
+			AccountID:   account.ID,
+			PostingDate: arg.Date,
+			Balance:     account.Balance,
+			Amount:      interest,
+			EntryID:     result.Entry.ID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInterestAlreadyPosted
+			}
+			return err
+		}
+
+		accounts, err := addMoney(ctx, q, map[int64]int64{
+			account.ID:       -interest,
+			income.AccountID: interest,
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		result.Account = accounts[account.ID]
+
+		result.JournalEntry, err = postJournal(ctx, q, CreateJournalEntryParams{
+			Kind: JournalOverdraftInterest,
+		}, []PostingLine{
+			accountPosting(accounts[account.ID], -interest),
+			accountPosting(accounts[income.AccountID], interest),
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,79 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverdraftInterest(t *testing.T) {
+	require.Zero(t, OverdraftInterest(100, 2000))
+	require.Zero(t, OverdraftInterest(-100, 0))
+	// 365000 * 20% / 365 = 200
+	require.Equal(t, int64(200), OverdraftInterest(-365000, 2000))
+	// 0.5 округляется вверх
+	require.Equal(t, int64(1), OverdraftInterest(-1825, 1000))
+	require.Zero(t, OverdraftInterest(-1824, 1000))
+}
+
+func TestTransferTxWithinOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	account1, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 50,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-50), result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestPostOverdraftInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	account, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: -365000,
+	})
+	require.NoError(t, err)
+
+	arg := PostOverdraftInterestTxParams{
+		AccountID:     account.ID,
+		Date:          time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+		AnnualRateBps: 2000,
+	}
+
+	result, err := store.PostOverdraftInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(200), result.Posting.Amount)
+	require.Equal(t, int64(-365000), result.Posting.Balance)
+	require.Equal(t, int64(-200), result.Entry.Amount)
+	require.Equal(t, int64(-365200), result.Account.Balance)
+
+	// повторный запуск в тот же день ничего не списывает
+	_, err = store.PostOverdraftInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInterestAlreadyPosted)
+
+	account, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-365200), account.Balance)
+}
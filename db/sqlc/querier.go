@@ -6,29 +6,129 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddAccountAccruedInterest(ctx context.Context, arg AddAccountAccruedInterestParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	AddTransferRefundedAmount(ctx context.Context, arg AddTransferRefundedAmountParams) (Transfer, error)
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error)
+	ClaimTasks(ctx context.Context, arg ClaimTasksParams) ([]Task, error)
+	CompleteTask(ctx context.Context, arg CompleteTaskParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateJournalEntry(ctx context.Context, arg CreateJournalEntryParams) (JournalEntry, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreateOverdraftInterestPosting(ctx context.Context, arg CreateOverdraftInterestPostingParams) (OverdraftInterestPosting, error)
+	CreatePosting(ctx context.Context, arg CreatePostingParams) (Posting, error)
+	CreateReconciliationDiscrepancy(ctx context.Context, arg CreateReconciliationDiscrepancyParams) (ReconciliationDiscrepancy, error)
+	CreateReconciliationRun(ctx context.Context) (ReconciliationRun, error)
+	CreateReversalRequest(ctx context.Context, arg CreateReversalRequestParams) (ReversalRequest, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookDeliveries(ctx context.Context, arg CreateWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredRateLimits(ctx context.Context) error
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
+	EnableWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	FinishReconciliationRun(ctx context.Context, arg FinishReconciliationRunParams) (ReconciliationRun, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountByOwner(ctx context.Context, owner string) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountProduct(ctx context.Context, code string) (AccountProduct, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetFeeScheduleByTier(ctx context.Context, arg GetFeeScheduleByTierParams) (FeeSchedule, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetJournalEntryByTransfer(ctx context.Context, transferID sql.NullInt64) (JournalEntry, error)
+	GetLastAuditEvent(ctx context.Context) (AuditEvent, error)
+	GetReconciliationRun(ctx context.Context, id int64) (ReconciliationRun, error)
+	GetReversalRequest(ctx context.Context, id int64) (ReversalRequest, error)
+	GetReversalRequestForUpdate(ctx context.Context, id int64) (ReversalRequest, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (SystemAccount, error)
+	GetTask(ctx context.Context, id int64) (Task, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTrialBalance(ctx context.Context, asOf time.Time) ([]GetTrialBalanceRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	KillTask(ctx context.Context, arg KillTaskParams) (int64, error)
+	ListAccountProducts(ctx context.Context) ([]AccountProduct, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsDueInterestPosting(ctx context.Context, arg ListAccountsDueInterestPostingParams) ([]Account, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error)
+	ListBalanceMismatches(ctx context.Context) ([]ListBalanceMismatchesRow, error)
+	ListDueOutboxEvents(ctx context.Context, arg ListDueOutboxEventsParams) ([]Outbox, error)
+	ListDueScheduledTransfers(ctx context.Context, arg ListDueScheduledTransfersParams) ([]ScheduledTransfer, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error)
+	ListExpiredHolds(ctx context.Context, arg ListExpiredHoldsParams) ([]Hold, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListGLAccounts(ctx context.Context) ([]GlAccount, error)
+	ListHolds(ctx context.Context, arg ListHoldsParams) ([]Hold, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]Account, error)
+	ListInterestPostings(ctx context.Context, arg ListInterestPostingsParams) ([]InterestPosting, error)
+	ListOrphanedEntries(ctx context.Context) ([]ListOrphanedEntriesRow, error)
+	ListOverdraftInterestPostings(ctx context.Context, arg ListOverdraftInterestPostingsParams) ([]OverdraftInterestPosting, error)
+	ListOverdrawnAccounts(ctx context.Context, arg ListOverdrawnAccountsParams) ([]Account, error)
+	ListPostingsByJournalEntry(ctx context.Context, journalEntryID int64) ([]Posting, error)
+	ListReconciliationDiscrepancies(ctx context.Context, arg ListReconciliationDiscrepanciesParams) ([]ReconciliationDiscrepancy, error)
+	ListReconciliationRuns(ctx context.Context, arg ListReconciliationRunsParams) ([]ReconciliationRun, error)
+	ListReversalRequests(ctx context.Context, arg ListReversalRequestsParams) ([]ReversalRequest, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListSessions(ctx context.Context, username string) ([]Session, error)
+	ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListTransfersMissingEntries(ctx context.Context) ([]ListTransfersMissingEntriesRow, error)
+	ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, owner string) ([]WebhookSubscription, error)
+	LockAuditChain(ctx context.Context, key int64) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	NotifyAccountChanged(ctx context.Context, accountID int64) error
+	RecordWebhookFailure(ctx context.Context, arg RecordWebhookFailureParams) (WebhookSubscription, error)
+	RecordWebhookSuccess(ctx context.Context, id int64) error
+	RequeueDeadTask(ctx context.Context, id int64) (Task, error)
+	RetryTask(ctx context.Context, arg RetryTaskParams) (int64, error)
+	TakeRateLimit(ctx context.Context, arg TakeRateLimitParams) (time.Time, error)
+	TryLockOutboxRelay(ctx context.Context, key int64) (bool, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateAccountProductRate(ctx context.Context, arg UpdateAccountProductRateParams) (AccountProduct, error)
+	UpdateAccountTier(ctx context.Context, arg UpdateAccountTierParams) (Account, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdateReversalRequest(ctx context.Context, arg UpdateReversalRequestParams) (ReversalRequest, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferRun(ctx context.Context, arg UpdateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error)
+	UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error)
+	UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error)
+	UpsertScheduledTransferRun(ctx context.Context, arg UpsertScheduledTransferRunParams) (ScheduledTransferRun, error)
 }
 
 var _ Querier = (*Queries)(nil)
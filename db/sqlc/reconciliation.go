@@ -0,0 +1,19 @@
+package sqlc
+
+const (
+	ReconciliationRunning   = "running"
+	ReconciliationCompleted = "completed"
+	ReconciliationFailed    = "failed"
+)
+
+// Kinds of ledger invariant violations found by reconciliation
+const (
+	// DiscrepancyBalanceMismatch is an account whose balance differs from the sum of its entries
+	DiscrepancyBalanceMismatch = "balance_mismatch"
+	// DiscrepancyMissingTransferEntry is a transfer without its debit or credit entry
+	DiscrepancyMissingTransferEntry = "missing_transfer_entry"
+	// DiscrepancyUnbalancedTransfer is a transfer whose entries don't sum to zero in a currency
+	DiscrepancyUnbalancedTransfer = "unbalanced_transfer"
+	// DiscrepancyOrphanedEntry is a customer entry that no transfer or interest posting explains
+	DiscrepancyOrphanedEntry = "orphaned_entry"
+)
@@ -0,0 +1,71 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBalanceMismatches(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// счёт создан с начальным балансом без проводок, а UpdateAccount проводок не создаёт
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	mismatches, err := testQueries.ListBalanceMismatches(context.Background())
+	require.NoError(t, err)
+
+	var found bool
+	for _, mismatch := range mismatches {
+		if mismatch.AccountID == account1.ID {
+			found = true
+			require.Equal(t, account1.Balance-5, mismatch.Balance)
+			require.Equal(t, int64(-5), mismatch.EntriesTotal)
+		}
+	}
+	require.True(t, found)
+}
+
+func TestTransferTxEntriesReconcile(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, result.FromEntry.TransferID.Int64)
+	require.Equal(t, result.Transfer.ID, result.ToEntry.TransferID.Int64)
+
+	missing, err := testQueries.ListTransfersMissingEntries(context.Background())
+	require.NoError(t, err)
+	for _, transfer := range missing {
+		require.NotEqual(t, result.Transfer.ID, transfer.TransferID)
+	}
+
+	unbalanced, err := testQueries.ListUnbalancedTransfers(context.Background())
+	require.NoError(t, err)
+	for _, transfer := range unbalanced {
+		require.NotEqual(t, result.Transfer.ID, transfer.TransferID.Int64)
+	}
+
+	orphaned, err := testQueries.ListOrphanedEntries(context.Background())
+	require.NoError(t, err)
+	for _, entry := range orphaned {
+		require.NotEqual(t, result.FromEntry.ID, entry.EntryID)
+		require.NotEqual(t, result.ToEntry.ID, entry.EntryID)
+	}
+}
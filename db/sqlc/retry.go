@@ -0,0 +1,62 @@
+package sqlc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTxAttempts bounds how many times a transaction that lost a serialization conflict or a deadlock is run
+const maxTxAttempts = 3
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// StoreOption configures a SQLStore
+type StoreOption func(*SQLStore)
+
+// WithRetryObserver calls observe with the name of the operation every time its transaction is retried
+func WithRetryObserver(observe func(op string)) StoreOption {
+	return func(store *SQLStore) {
+		store.onRetry = observe
+	}
+}
+
+// IsRetryable reports whether err is a serialization failure or a deadlock,
+// which Postgres resolves by aborting one of the transactions, so running it again can succeed
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
+
+// retryTx runs fn in a transaction and runs it again in a new one while it fails with a retryable error.
+// fn must not keep state between attempts
+func (store *SQLStore) retryTx(ctx context.Context, op string, fn func(*Queries) error) error {
+	ctx, span := startTxSpan(ctx, op)
+	defer span.End()
+
+	for attempt := 1; ; attempt++ {
+		err := store.execTx(ctx, fn)
+		if err == nil || attempt >= maxTxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+			span.SetAttributes(attribute.Int("db.transaction.attempts", attempt))
+			recordError(span, err)
+			return err
+		}
+
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.String("error", err.Error()),
+		))
+		if store.onRetry != nil {
+			store.onRetry(op)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package sqlc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryable(t *testing.T) {
+	require.True(t, IsRetryable(&pq.Error{Code: serializationFailure}))
+	require.True(t, IsRetryable(fmt.Errorf("transfer: %w", &pq.Error{Code: deadlockDetected})))
+	require.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+	require.False(t, IsRetryable(ErrInsufficientFunds))
+}
+
+func TestRetryTx(t *testing.T) {
+	var retried []string
+	store := NewStore(testDB, WithRetryObserver(func(op string) {
+		retried = append(retried, op)
+	})).(*SQLStore)
+
+	attempts := 0
+	err := store.retryTx(context.Background(), "test", func(q *Queries) error {
+		attempts++
+		if attempts == 1 {
+			return &pq.Error{Code: deadlockDetected}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, []string{"test"}, retried)
+
+	// после maxTxAttempts ошибка возвращается вызывающему
+	attempts = 0
+	err = store.retryTx(context.Background(), "test", func(q *Queries) error {
+		attempts++
+		return &pq.Error{Code: serializationFailure}
+	})
+	require.True(t, IsRetryable(err))
+	require.Equal(t, maxTxAttempts, attempts)
+
+	// остальные ошибки не повторяются
+	attempts = 0
+	err = store.retryTx(context.Background(), "test", func(q *Queries) error {
+		attempts++
+		return ErrInsufficientFunds
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Equal(t, 1, attempts)
+}
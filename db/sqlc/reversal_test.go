@@ -0,0 +1,125 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferTxPartial(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        8,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID:  original.Transfer.ID,
+		Amount:      5,
+		Reason:      "wrong amount",
+		RequestedBy: account2.Owner,
+	})
+	require.NoError(t, err)
+
+	reversal := result.Reversal.Transfer
+	require.Equal(t, account2.ID, reversal.FromAccountID)
+	require.Equal(t, account1.ID, reversal.ToAccountID)
+	require.Equal(t, int64(5), reversal.Amount)
+	require.Zero(t, reversal.Fee)
+	require.Equal(t, original.Transfer.ID, reversal.ReversalOf.Int64)
+	require.Equal(t, int64(5), result.OriginalTransfer.RefundedAmount)
+
+	require.Equal(t, ReversalCompleted, result.Request.Status)
+	require.Equal(t, reversal.ID, result.Request.ReversalTransferID.Int64)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID:  original.Transfer.ID,
+		Amount:      4,
+		RequestedBy: account2.Owner,
+	})
+	require.ErrorIs(t, err, ErrRefundExceedsTransfer)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID:  reversal.ID,
+		Amount:      1,
+		RequestedBy: account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrReversalOfReversal)
+}
+
+func TestReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	// получатель уже потратил деньги
+	_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account2.ID,
+		Balance: 0,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID:  original.Transfer.ID,
+		Amount:      5,
+		RequestedBy: account2.Owner,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Zero(t, transfer.RefundedAmount)
+}
+
+func TestApproveReversalTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	banker := createRandomUser(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	request, err := testQueries.CreateReversalRequest(context.Background(), CreateReversalRequestParams{
+		TransferID:  original.Transfer.ID,
+		Amount:      5,
+		RequestedBy: account1.Owner,
+		Status:      ReversalPending,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ApproveReversalTx(context.Background(), ApproveReversalTxParams{
+		RequestID:  request.ID,
+		ReviewedBy: banker.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReversalCompleted, result.Request.Status)
+	require.Equal(t, banker.Username, result.Request.ReviewedBy.String)
+	require.Equal(t, int64(5), result.OriginalTransfer.RefundedAmount)
+
+	_, err = store.ApproveReversalTx(context.Background(), ApproveReversalTxParams{
+		RequestID:  request.ID,
+		ReviewedBy: banker.Username,
+	})
+	require.ErrorIs(t, err, ErrReversalNotPending)
+}
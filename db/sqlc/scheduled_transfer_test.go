@@ -0,0 +1,128 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, from, to Account, amount int64) ScheduledTransfer {
+	nextRunAt := time.Now().UTC().Truncate(time.Minute).Add(-time.Minute)
+	arg := CreateScheduledTransferParams{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Recurrence:    "* * * * *",
+		StartAt:       nextRunAt,
+		NextRunAt:     nextRunAt,
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, scheduled)
+
+	require.Equal(t, arg.Owner, scheduled.Owner)
+	require.Equal(t, arg.FromAccountID, scheduled.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduled.ToAccountID)
+	require.Equal(t, arg.Amount, scheduled.Amount)
+	require.Equal(t, ScheduledTransferActive, scheduled.Status)
+	require.WithinDuration(t, arg.NextRunAt, scheduled.NextAttemptAt, time.Second)
+	require.False(t, scheduled.EndAt.Valid)
+
+	return scheduled
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), 10)
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	scheduled := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), 10)
+
+	updated, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     scheduled.ID,
+		Status: sql.NullString{String: ScheduledTransferPaused, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferPaused, updated.Status)
+	require.Equal(t, scheduled.Amount, updated.Amount)
+	require.Equal(t, scheduled.Recurrence, updated.Recurrence)
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := createRandomAccount(t)
+	scheduled := createRandomScheduledTransfer(t, account1, account2, 10)
+
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ScheduledTransferID: scheduled.ID,
+		Now:                 time.Now(),
+		MaxAttempts:         3,
+		RetryDelay:          time.Minute,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, ScheduledRunSucceeded, result.Run.Status)
+	require.Equal(t, int64(1), result.Run.Attempts)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Run.TransferID.Int64)
+	require.Equal(t, account1.Balance-10, result.Transfer.FromAccount.Balance)
+	require.WithinDuration(t, scheduled.NextRunAt.Add(time.Minute), result.ScheduledTransfer.NextRunAt, time.Second)
+	require.Equal(t, result.ScheduledTransfer.NextRunAt, result.ScheduledTransfer.NextAttemptAt)
+	requireSystemAuditEvent(t, "scheduled_transfer.execute", AuditTargetTransfer, strconv.FormatInt(result.Transfer.Transfer.ID, 10))
+
+	// следующее повторение ещё не наступило
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ScheduledTransferID: scheduled.ID,
+		Now:                 scheduled.NextRunAt,
+		MaxAttempts:         3,
+		RetryDelay:          time.Minute,
+	})
+	require.ErrorIs(t, err, ErrScheduledTransferNotDue)
+}
+
+func TestExecuteScheduledTransferTxRetry(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduled := createRandomScheduledTransfer(t, account1, account2, account1.Balance+1)
+
+	now := time.Now()
+	maxAttempts := 2
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+			ScheduledTransferID: scheduled.ID,
+			Now:                 now,
+			MaxAttempts:         int64(maxAttempts),
+			RetryDelay:          time.Minute,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(attempt), result.Run.Attempts)
+		require.Equal(t, ErrInsufficientFunds.Error(), result.Run.LastError)
+		require.ErrorIs(t, result.TransferErr, ErrInsufficientFunds)
+		require.False(t, result.Run.TransferID.Valid)
+
+		if attempt < maxAttempts {
+			require.Equal(t, ScheduledRunRetrying, result.Run.Status)
+			require.WithinDuration(t, scheduled.NextRunAt, result.ScheduledTransfer.NextRunAt, time.Second)
+			require.WithinDuration(t, now.Add(time.Minute), result.ScheduledTransfer.NextAttemptAt, time.Second)
+			now = result.ScheduledTransfer.NextAttemptAt
+			continue
+		}
+
+		require.Equal(t, ScheduledRunFailed, result.Run.Status)
+		require.Equal(t, int64(1), result.ScheduledTransfer.FailureCount)
+		require.True(t, result.ScheduledTransfer.NextRunAt.After(scheduled.NextRunAt))
+	}
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
@@ -0,0 +1,38 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, username string, expiresAt time.Time) Session {
+	session, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     username,
+		RefreshToken: uuid.NewString(),
+		UserAgent:    "curl/8.5.0",
+		ClientIp:     "198.51.100.1",
+		ExpiresAt:    expiresAt,
+	})
+	require.NoError(t, err)
+	return session
+}
+
+func TestListSessions(t *testing.T) {
+	user := createRandomUser(t)
+	older := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+	newer := createRandomSession(t, user.Username, time.Now().Add(time.Hour))
+	createRandomSession(t, user.Username, time.Now().Add(-time.Hour))
+	createRandomSession(t, createRandomUser(t).Username, time.Now().Add(time.Hour))
+
+	// истёкшие и чужие сессии не попадают в список, новые идут первыми
+	sessions, err := testQueries.ListSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, newer.ID, sessions[0].ID)
+	require.Equal(t, older.ID, sessions[1].ID)
+}
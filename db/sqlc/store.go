@@ -3,24 +3,50 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (HoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	ExpireHoldTx(ctx context.Context, arg ExpireHoldTxParams) (HoldTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	ApproveReversalTx(ctx context.Context, arg ApproveReversalTxParams) (ReverseTransferTxResult, error)
+	PostOverdraftInterestTx(ctx context.Context, arg PostOverdraftInterestTxParams) (PostOverdraftInterestTxResult, error)
+	AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (AccrueInterestTxResult, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	AuditTx(ctx context.Context, fn func(q Querier) (AuditChange, error)) error
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	OutboxRelayTx(ctx context.Context, fn func(q Querier) error) (bool, error)
 }
 
+// ErrInsufficientFunds is returned when a transfer would take the sender past its overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
 type SQLStore struct {
 	db *sql.DB
 	*Queries
+	// onRetry is called when a transaction is run again after a serialization failure or deadlock
+	onRetry func(op string)
 }
 
-func NewStore(db *sql.DB) Store {
-	return &SQLStore{
+func NewStore(db *sql.DB, opts ...StoreOption) Store {
+	store := &SQLStore{
 		db:      db,
-		Queries: New(db),
+		Queries: New(traceDB(db)),
+	}
+	for _, opt := range opts {
+		opt(store)
 	}
+	return store
 }
 
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
@@ -29,7 +55,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 		return err
 	}
 
-	q := New(tx)
+	q := New(traceDB(tx))
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -41,6 +67,24 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	return tx.Commit()
 }
 
+// savepoint runs fn inside a SAVEPOINT, so a failed fn is undone
+// without aborting the surrounding transaction
+func savepoint(ctx context.Context, q *Queries, name string, fn func() error) error {
+	if _, err := q.db.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rbErr := q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("savepoint err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	_, err := q.db.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
@@ -53,65 +97,222 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// FeeEntry is the fee line item debited from the sender, empty when no fee is charged
+	FeeEntry Entry `json:"fee_entry"`
+	// JournalEntry is the balanced GL journal of the transfer and its fee
+	JournalEntry JournalEntry `json:"journal_entry"`
 }
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.retryTx(ctx, "transfer", func(q *Queries) error {
 		var err error
-
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+		result, err = transfer(ctx, q, arg, transferOptions{})
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+		_, err = recordAudit(ctx, q, AuditChange{
+			TargetType: AuditTargetTransfer,
+			TargetID:   strconv.FormatInt(result.Transfer.ID, 10),
+			After:      result.Transfer,
 		})
+		return err
+	})
+
+	return result, err
+}
+
+// transferOptions adjusts how transfer books a transfer
+type transferOptions struct {
+	// released is taken off the sender's held amount under the same row lock, which is how a hold is captured
+	released int64
+	// reversalOf links a compensating transfer to the transfer it reverses, reversals are free of charge
+	reversalOf sql.NullInt64
+}
+
+// transfer moves money between two accounts and charges the fee within an already open transaction
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams, opts transferOptions) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	var fee int64
+	if !opts.reversalOf.Valid {
+		fee, err = TransferFee(ctx, q, fromAccount, arg.Amount)
 		if err != nil {
-			return err
+			return result, err
 		}
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Fee:           fee,
+		ReversalOf:    opts.reversalOf,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+
+	changes := map[int64]int64{
+		arg.FromAccountID: -arg.Amount - fee,
+	}
+	changes[arg.ToAccountID] += arg.Amount
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+	var revenueAccountID int64
+	if fee > 0 {
+		revenue, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Purpose:  SystemAccountFeeRevenue,
+			Currency: fromAccount.Currency,
 		})
 		if err != nil {
-			return err
-		}
-
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMouney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-			if err != nil {
-				return err
-			}
-		} else {
-			result.ToAccount, result.FromAccount, err = addMouney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-			if err != nil {
-				return err
+			if err == sql.ErrNoRows {
+				return result, fmt.Errorf("no fee revenue account for currency %s", fromAccount.Currency)
 			}
+			return result, err
 		}
 
-		return nil
+		changes[revenue.AccountID] += fee
+		revenueAccountID = revenue.AccountID
+	}
+
+	accounts, err := addMoney(ctx, q, changes, map[int64]int64{
+		arg.FromAccountID: -opts.released,
 	})
+	if err != nil {
+		return result, err
+	}
 
-	return result, err
-}
+	result.FromAccount = accounts[arg.FromAccountID]
+	result.ToAccount = accounts[arg.ToAccountID]
+
+	// баланс проверяется после блокировки строк, поэтому параллельные переводы не уведут счёт в минус
+	if result.FromAccount.Overdrawn() {
+		return result, ErrInsufficientFunds
+	}
 
-func addMouney(ctx context.Context, q *Queries, accountID1 int64, amount1 int64, accountID2 int64, amount2 int64) (account1, account2 Account, err error) {
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		Amount: amount1,
-		ID:     accountID1,
+	// записи создаются под блокировкой строк счетов, поэтому их id растут в порядке коммитов
+	// и подписчики WatchAccount могут продолжать с последней увиденной записи
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		TransferID: transferID,
 	})
 	if err != nil {
-		return
+		return result, err
 	}
 
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		Amount: amount2,
-		ID:     accountID2,
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.Amount,
+		TransferID: transferID,
 	})
-	return
+	if err != nil {
+		return result, err
+	}
+
+	if fee > 0 {
+		result.FeeEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  arg.FromAccountID,
+			Amount:     -fee,
+			TransferID: transferID,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		_, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  revenueAccountID,
+			Amount:     fee,
+			TransferID: transferID,
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	lines := []PostingLine{
+		accountPosting(result.FromAccount, -arg.Amount),
+		accountPosting(result.ToAccount, arg.Amount),
+	}
+	if fee > 0 {
+		lines = append(lines,
+			accountPosting(result.FromAccount, -fee),
+			accountPosting(accounts[revenueAccountID], fee),
+		)
+	}
+
+	result.JournalEntry, err = postJournal(ctx, q, CreateJournalEntryParams{
+		Kind:       JournalTransfer,
+		TransferID: transferID,
+	}, lines)
+	if err != nil {
+		return result, err
+	}
+
+	err = enqueueTransferCreated(ctx, q, result.Transfer, fromAccount.Currency)
+	if err != nil {
+		return result, err
+	}
+
+	err = enqueueTransferWebhooks(ctx, q, result)
+	if err != nil {
+		return result, err
+	}
+
+	// уведомления уходят слушателям только после коммита транзакции
+	for _, accountID := range []int64{arg.FromAccountID, arg.ToAccountID} {
+		if err := q.NotifyAccountChanged(ctx, accountID); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// addMoney applies balance and held amount changes in ascending account ID order,
+// so concurrent transfers always lock the same rows in the same order and never deadlock
+func addMoney(ctx context.Context, q *Queries, changes map[int64]int64, held map[int64]int64) (map[int64]Account, error) {
+	ids := make([]int64, 0, len(changes)+len(held))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+	for id := range held {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		if amount := held[id]; amount != 0 {
+			account, err := q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+				Amount: amount,
+				ID:     id,
+			})
+			if err != nil {
+				return nil, err
+			}
+			accounts[id] = account
+		}
+
+		if amount, ok := changes[id]; ok {
+			account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				Amount: amount,
+				ID:     id,
+			})
+			if err != nil {
+				return nil, err
+			}
+			accounts[id] = account
+		}
+	}
+	return accounts, nil
 }
@@ -4,13 +4,24 @@ import (
 	"context"
 	"testing"
 
+	"github.com/brianvoe/gofakeit/v7"
 	"github.com/stretchr/testify/require"
 )
 
+// fundAccount sets the balance high enough for the concurrent transfers below to never overdraw
+func fundAccount(t *testing.T, account Account) Account {
+	account, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: 1000,
+	})
+	require.NoError(t, err)
+	return account
+}
+
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
+	account1 := fundAccount(t, createRandomAccount(t))
 	account2 := createRandomAccount(t)
 
 	n := 5
@@ -103,8 +114,8 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := fundAccount(t, createRandomAccount(t))
+	account2 := fundAccount(t, createRandomAccount(t))
 
 	n := 20
 	amount := int64(10)
@@ -143,3 +154,65 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	tier := gofakeit.LetterN(12)
+	account1 := createRandomAccountWithTier(t, tier)
+	account2 := createRandomAccount(t)
+
+	_, err := testQueries.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		Currency:   account1.Currency,
+		Tier:       tier,
+		Kind:       FeeKindFlat,
+		FlatAmount: 3,
+	})
+	require.NoError(t, err)
+
+	revenue, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Purpose:  SystemAccountFeeRevenue,
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+
+	amount := int64(5)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(3), result.Transfer.Fee)
+	require.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-3), result.FeeEntry.Amount)
+	require.Equal(t, account1.Balance-amount-3, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+
+	entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{
+		AccountID: revenue.AccountID,
+		Limit:     1,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
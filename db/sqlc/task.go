@@ -0,0 +1,59 @@
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	TaskPending   = "pending"
+	TaskRunning   = "running"
+	TaskSucceeded = "succeeded"
+	// TaskDead is the dead-letter state: the task ran out of attempts and waits for an operator
+	TaskDead = "dead"
+)
+
+const (
+	DefaultTaskQueue       = "default"
+	defaultTaskMaxAttempts = 10
+)
+
+type EnqueueTaskParams struct {
+	// Queue defaults to DefaultTaskQueue
+	Queue string
+	Type  string
+	// Payload is stored as JSON
+	Payload any
+	// MaxAttempts defaults to 10
+	MaxAttempts int64
+	// RunAt delays the task, zero means as soon as possible
+	RunAt time.Time
+}
+
+// EnqueueTask adds a task through q. Given the Queries of an open transaction,
+// the task is only visible to workers once the transaction commits and is dropped if it rolls back.
+func EnqueueTask(ctx context.Context, q Querier, arg EnqueueTaskParams) (Task, error) {
+	payload, err := json.Marshal(arg.Payload)
+	if err != nil {
+		return Task{}, err
+	}
+
+	if arg.Queue == "" {
+		arg.Queue = DefaultTaskQueue
+	}
+	if arg.MaxAttempts <= 0 {
+		arg.MaxAttempts = defaultTaskMaxAttempts
+	}
+	if arg.RunAt.IsZero() {
+		arg.RunAt = time.Now()
+	}
+
+	return q.CreateTask(ctx, CreateTaskParams{
+		Queue:       arg.Queue,
+		Type:        arg.Type,
+		Payload:     payload,
+		MaxAttempts: arg.MaxAttempts,
+		RunAt:       arg.RunAt,
+	})
+}
@@ -0,0 +1,147 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnqueueTaskFollowsTransaction(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	queue := "test-" + uuid.NewString()
+
+	rollback := errors.New("rollback")
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		_, err := EnqueueTask(context.Background(), q, EnqueueTaskParams{Queue: queue, Type: "noop"})
+		require.NoError(t, err)
+		return rollback
+	})
+	require.ErrorIs(t, err, rollback)
+
+	var enqueued Task
+	err = store.execTx(context.Background(), func(q *Queries) error {
+		var err error
+		enqueued, err = EnqueueTask(context.Background(), q, EnqueueTaskParams{
+			Queue:   queue,
+			Type:    "noop",
+			Payload: map[string]int{"n": 1},
+		})
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, TaskPending, enqueued.Status)
+	require.JSONEq(t, `{"n": 1}`, string(enqueued.Payload))
+
+	now := time.Now()
+	claimed, err := testQueries.ClaimTasks(context.Background(), ClaimTasksParams{
+		Queue:       queue,
+		Now:         now.Add(time.Second),
+		LockedUntil: sql.NullTime{Time: now.Add(time.Minute), Valid: true},
+		RowLimit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, enqueued.ID, claimed[0].ID)
+	require.Equal(t, TaskRunning, claimed[0].Status)
+	require.Equal(t, int64(1), claimed[0].Attempts)
+
+	// аренда ещё не истекла, повторно задачу не взять
+	claimed, err = testQueries.ClaimTasks(context.Background(), ClaimTasksParams{
+		Queue:       queue,
+		Now:         now.Add(time.Second),
+		LockedUntil: sql.NullTime{Time: now.Add(time.Minute), Valid: true},
+		RowLimit:    10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	claimed, err = testQueries.ClaimTasks(context.Background(), ClaimTasksParams{
+		Queue:       queue,
+		Now:         now.Add(2 * time.Minute),
+		LockedUntil: sql.NullTime{Time: now.Add(3 * time.Minute), Valid: true},
+		RowLimit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, int64(2), claimed[0].Attempts)
+}
+
+func TestClaimTasksSkipsLocked(t *testing.T) {
+	queue := "test-" + uuid.NewString()
+	for range 2 {
+		_, err := EnqueueTask(context.Background(), testQueries, EnqueueTaskParams{Queue: queue, Type: "noop"})
+		require.NoError(t, err)
+	}
+
+	arg := ClaimTasksParams{
+		Queue:       queue,
+		Now:         time.Now().Add(time.Second),
+		LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+		RowLimit:    1,
+	}
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	first, err := New(tx).ClaimTasks(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+
+	// первая задача заблокирована открытой транзакцией, второй воркер берёт следующую
+	second, err := testQueries.ClaimTasks(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	require.NotEqual(t, first[0].ID, second[0].ID)
+}
+
+func TestCompleteTaskIsFenced(t *testing.T) {
+	queue := "test-" + uuid.NewString()
+	enqueued, err := EnqueueTask(context.Background(), testQueries, EnqueueTaskParams{Queue: queue, Type: "noop"})
+	require.NoError(t, err)
+
+	claim := func(now time.Time) Task {
+		claimed, err := testQueries.ClaimTasks(context.Background(), ClaimTasksParams{
+			Queue:       queue,
+			Now:         now,
+			LockedUntil: sql.NullTime{Time: now.Add(time.Minute), Valid: true},
+			RowLimit:    1,
+		})
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		return claimed[0]
+	}
+
+	now := time.Now().Add(time.Second)
+	first := claim(now)
+	// аренда первого воркера истекла, задачу взял второй
+	second := claim(now.Add(2 * time.Minute))
+	require.Equal(t, enqueued.ID, second.ID)
+
+	rows, err := testQueries.CompleteTask(context.Background(), CompleteTaskParams{ID: first.ID, Attempts: first.Attempts})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	rows, err = testQueries.KillTask(context.Background(), KillTaskParams{ID: first.ID, Attempts: first.Attempts, LastError: "late"})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	rows, err = testQueries.RetryTask(context.Background(), RetryTaskParams{ID: second.ID, Attempts: second.Attempts, RunAt: now})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	task, err := testQueries.GetTask(context.Background(), enqueued.ID)
+	require.NoError(t, err)
+	require.Equal(t, TaskPending, task.Status)
+	require.Empty(t, task.LastError)
+
+	// задача уже не выполняется, её итог тоже не записать
+	rows, err = testQueries.CompleteTask(context.Background(), CompleteTaskParams{ID: second.ID, Attempts: second.Attempts})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
@@ -0,0 +1,13 @@
+package sqlc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetAccount", queryName(getAccount))
+	require.Equal(t, "SAVEPOINT", queryName("SAVEPOINT transfer"))
+	require.Equal(t, "query", queryName(" "))
+}
@@ -7,26 +7,65 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 )
 
+const addTransferRefundedAmount = `-- name: AddTransferRefundedAmount :one
+UPDATE transfers
+SET refunded_amount = refunded_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, reversal_of, refunded_amount
+`
+
+type AddTransferRefundedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddTransferRefundedAmount(ctx context.Context, arg AddTransferRefundedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferRefundedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.ReversalOf,
+		&i.RefundedAmount,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
         from_account_id,
         to_account_id,
-        amount
+        amount,
+        fee,
+        reversal_of
     )
-VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, reversal_of, refunded_amount
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	Fee           int64         `json:"fee"`
+	ReversalOf    sql.NullInt64 `json:"reversal_of"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.ReversalOf,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +73,15 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.ReversalOf,
+		&i.RefundedAmount,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, reversal_of, refunded_amount
 FROM transfers
 WHERE id = $1
 LIMIT 1
@@ -54,12 +96,39 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.ReversalOf,
+		&i.RefundedAmount,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, reversal_of, refunded_amount
+FROM transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY
+UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.ReversalOf,
+		&i.RefundedAmount,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, reversal_of, refunded_amount
 FROM transfers
 WHERE from_account_id = $1
     OR to_account_id = $2
@@ -94,6 +163,9 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Fee,
+			&i.ReversalOf,
+			&i.RefundedAmount,
 		); err != nil {
 			return nil, err
 		}
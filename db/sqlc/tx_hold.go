@@ -0,0 +1,232 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	HoldAuthorized = "authorized"
+	HoldCaptured   = "captured"
+	HoldVoided     = "voided"
+	HoldExpired    = "expired"
+)
+
+var (
+	// ErrHoldNotAuthorized is returned when the hold was already captured, voided or expired
+	ErrHoldNotAuthorized = errors.New("hold is not authorized")
+	// ErrHoldExpired is returned when capturing a hold after its expiry
+	ErrHoldExpired = errors.New("hold has expired")
+	// ErrHoldNotExpired is returned when expiring a hold that is still valid
+	ErrHoldNotExpired = errors.New("hold has not expired yet")
+	// ErrCaptureExceedsHold is returned when capturing more than was authorized
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the hold")
+)
+
+type AuthorizeHoldTxParams struct {
+	AccountID   int64     `json:"account_id"`
+	ToAccountID int64     `json:"to_account_id"`
+	Amount      int64     `json:"amount"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type HoldTxResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+// AuthorizeHoldTx reserves amount on the account until the hold is captured, voided or expires
+func (store *SQLStore) AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Account, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			Amount: arg.Amount,
+			ID:     arg.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if result.Account.Overdrawn() {
+			return ErrInsufficientFunds
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   arg.AccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount:      arg.Amount,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, holdChange(Hold{}, result.Hold))
+		return err
+	})
+
+	return result, err
+}
+
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount is the part of the hold to transfer, the whole hold when zero
+	Amount int64     `json:"amount"`
+	Now    time.Time `json:"now"`
+}
+
+type CaptureHoldTxResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureHoldTx transfers the captured amount to the hold's recipient and releases the whole hold.
+// A partial capture gives the rest of the reserved money back to the account.
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HoldAuthorized {
+			return ErrHoldNotAuthorized
+		}
+		if !hold.ExpiresAt.After(arg.Now) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+		}, transferOptions{released: hold.Amount})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			ID:             hold.ID,
+			Status:         HoldCaptured,
+			CapturedAmount: amount,
+			TransferID: sql.NullInt64{
+				Int64: result.Transfer.Transfer.ID,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, holdChange(hold, result.Hold))
+		return err
+	})
+
+	return result, err
+}
+
+// VoidHoldTx cancels an authorized hold and makes the money available again
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldVoided)
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, holdChange(hold, result.Hold))
+		return err
+	})
+
+	return result, err
+}
+
+type ExpireHoldTxParams struct {
+	HoldID int64     `json:"hold_id"`
+	Now    time.Time `json:"now"`
+}
+
+// ExpireHoldTx releases an authorized hold whose expiry has passed
+func (store *SQLStore) ExpireHoldTx(ctx context.Context, arg ExpireHoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+	ctx = withSystemAudit(ctx, "hold.expire")
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status == HoldAuthorized && hold.ExpiresAt.After(arg.Now) {
+			return ErrHoldNotExpired
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldExpired)
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, holdChange(hold, result.Hold))
+		return err
+	})
+
+	return result, err
+}
+
+// releaseHold gives the reserved money back to the account and closes the hold with the given status
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	if hold.Status != HoldAuthorized {
+		return result, ErrHoldNotAuthorized
+	}
+
+	var err error
+	result.Account, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+		Amount: -hold.Amount,
+		ID:     hold.AccountID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	return result, err
+}
+
+// holdChange describes a hold's status change for the audit log, before is empty for a new hold
+func holdChange(before Hold, after Hold) AuditChange {
+	change := AuditChange{
+		TargetType: AuditTargetHold,
+		TargetID:   strconv.FormatInt(after.ID, 10),
+		After:      after,
+	}
+	if before.ID != 0 {
+		change.Before = before
+	}
+	return change
+}
@@ -0,0 +1,159 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+)
+
+const (
+	ReversalPending   = "pending"
+	ReversalCompleted = "completed"
+	ReversalRejected  = "rejected"
+)
+
+var (
+	// ErrReversalOfReversal is returned when reversing a transfer that is itself a reversal
+	ErrReversalOfReversal = errors.New("cannot reverse a reversal")
+	// ErrRefundExceedsTransfer is returned when the refund is more than what is left of the transfer
+	ErrRefundExceedsTransfer = errors.New("refund exceeds the remaining transfer amount")
+	// ErrReversalNotPending is returned when approving or rejecting an already reviewed request
+	ErrReversalNotPending = errors.New("reversal request is not pending")
+)
+
+type ReverseTransferTxParams struct {
+	TransferID  int64  `json:"transfer_id"`
+	Amount      int64  `json:"amount"`
+	Reason      string `json:"reason"`
+	RequestedBy string `json:"requested_by"`
+}
+
+type ReverseTransferTxResult struct {
+	Request          ReversalRequest  `json:"request"`
+	OriginalTransfer Transfer         `json:"original_transfer"`
+	Reversal         TransferTxResult `json:"reversal"`
+}
+
+// ReverseTransferTx refunds amount of a transfer right away and records the completed request.
+// Callers decide whether the requester may do that without a banker's approval.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.OriginalTransfer, result.Reversal, err = reverse(ctx, q, arg.TransferID, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.Request, err = q.CreateReversalRequest(ctx, CreateReversalRequestParams{
+			TransferID:  arg.TransferID,
+			Amount:      arg.Amount,
+			Reason:      arg.Reason,
+			RequestedBy: arg.RequestedBy,
+			Status:      ReversalCompleted,
+			ReversalTransferID: sql.NullInt64{
+				Int64: result.Reversal.Transfer.ID,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, AuditChange{
+			TargetType: AuditTargetReversalRequest,
+			TargetID:   strconv.FormatInt(result.Request.ID, 10),
+			After:      result.Request,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type ApproveReversalTxParams struct {
+	RequestID  int64  `json:"request_id"`
+	ReviewedBy string `json:"reviewed_by"`
+}
+
+// ApproveReversalTx executes a pending reversal request on behalf of the reviewing banker
+func (store *SQLStore) ApproveReversalTx(ctx context.Context, arg ApproveReversalTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		request, err := q.GetReversalRequestForUpdate(ctx, arg.RequestID)
+		if err != nil {
+			return err
+		}
+
+		if request.Status != ReversalPending {
+			return ErrReversalNotPending
+		}
+
+		result.OriginalTransfer, result.Reversal, err = reverse(ctx, q, request.TransferID, request.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.Request, err = q.UpdateReversalRequest(ctx, UpdateReversalRequestParams{
+			ID:     request.ID,
+			Status: ReversalCompleted,
+			ReviewedBy: sql.NullString{
+				String: arg.ReviewedBy,
+				Valid:  true,
+			},
+			ReversalTransferID: sql.NullInt64{
+				Int64: result.Reversal.Transfer.ID,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = recordAudit(ctx, q, AuditChange{
+			TargetType: AuditTargetReversalRequest,
+			TargetID:   strconv.FormatInt(result.Request.ID, 10),
+			Before:     request,
+			After:      result.Request,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// reverse books a compensating transfer from the recipient back to the sender and
+// adds it to the original transfer's refunded amount. The original fee is not refunded.
+func reverse(ctx context.Context, q *Queries, transferID int64, amount int64) (Transfer, TransferTxResult, error) {
+	original, err := q.GetTransferForUpdate(ctx, transferID)
+	if err != nil {
+		return original, TransferTxResult{}, err
+	}
+
+	if original.ReversalOf.Valid {
+		return original, TransferTxResult{}, ErrReversalOfReversal
+	}
+	if amount <= 0 || amount > original.Amount-original.RefundedAmount {
+		return original, TransferTxResult{}, ErrRefundExceedsTransfer
+	}
+
+	reversal, err := transfer(ctx, q, TransferTxParams{
+		FromAccountID: original.ToAccountID,
+		ToAccountID:   original.FromAccountID,
+		Amount:        amount,
+	}, transferOptions{
+		reversalOf: sql.NullInt64{Int64: original.ID, Valid: true},
+	})
+	if err != nil {
+		return original, reversal, err
+	}
+
+	original, err = q.AddTransferRefundedAmount(ctx, AddTransferRefundedAmountParams{
+		Amount: amount,
+		ID:     original.ID,
+	})
+	return original, reversal, err
+}
@@ -0,0 +1,154 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+const (
+	ScheduledTransferActive    = "active"
+	ScheduledTransferPaused    = "paused"
+	ScheduledTransferCompleted = "completed"
+	ScheduledTransferCancelled = "cancelled"
+)
+
+const (
+	ScheduledRunPending   = "pending"
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunRetrying  = "retrying"
+	ScheduledRunFailed    = "failed"
+)
+
+// ErrScheduledTransferNotDue is returned when the scheduled transfer was paused,
+// cancelled or already executed by another worker since it was listed as due
+var ErrScheduledTransferNotDue = errors.New("scheduled transfer is not due")
+
+type ExecuteScheduledTransferTxParams struct {
+	ScheduledTransferID int64         `json:"scheduled_transfer_id"`
+	Now                 time.Time     `json:"now"`
+	MaxAttempts         int64         `json:"max_attempts"`
+	RetryDelay          time.Duration `json:"retry_delay"`
+}
+
+type ExecuteScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer    `json:"scheduled_transfer"`
+	Run               ScheduledTransferRun `json:"run"`
+	// Transfer is empty when the occurrence failed
+	Transfer TransferTxResult `json:"transfer"`
+	// TransferErr is why the occurrence failed this time, nil when it succeeded or had already succeeded
+	TransferErr error `json:"-"`
+}
+
+// ExecuteScheduledTransferTx runs the pending occurrence of a scheduled transfer.
+// The occurrence is recorded in scheduled_transfer_runs in the same transaction as the transfer,
+// so re-running it after a crash never transfers twice. A failed transfer is rolled back to a savepoint,
+// recorded on the run and retried with exponential backoff until MaxAttempts is reached.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+	ctx = withSystemAudit(ctx, "scheduled_transfer.execute")
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduled, err := q.GetScheduledTransferForUpdate(ctx, arg.ScheduledTransferID)
+		if err != nil {
+			return err
+		}
+
+		if scheduled.Status != ScheduledTransferActive || scheduled.NextAttemptAt.After(arg.Now) {
+			return ErrScheduledTransferNotDue
+		}
+
+		result.Run, err = q.UpsertScheduledTransferRun(ctx, UpsertScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			OccurrenceAt:        scheduled.NextRunAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if result.Run.Status != ScheduledRunSucceeded {
+			transferErr := savepoint(ctx, q, "scheduled_transfer", func() error {
+				var err error
+				result.Transfer, err = transfer(ctx, q, TransferTxParams{
+					FromAccountID: scheduled.FromAccountID,
+					ToAccountID:   scheduled.ToAccountID,
+					Amount:        scheduled.Amount,
+				}, transferOptions{})
+				return err
+			})
+
+			runArg := UpdateScheduledTransferRunParams{
+				ID:     result.Run.ID,
+				Status: ScheduledRunSucceeded,
+				TransferID: sql.NullInt64{
+					Int64: result.Transfer.Transfer.ID,
+					Valid: true,
+				},
+			}
+			if transferErr != nil {
+				result.Transfer = TransferTxResult{}
+				result.TransferErr = transferErr
+				runArg.Status = ScheduledRunRetrying
+				if result.Run.Attempts >= max(arg.MaxAttempts, 1) {
+					runArg.Status = ScheduledRunFailed
+				}
+				runArg.TransferID = sql.NullInt64{}
+				runArg.LastError = transferErr.Error()
+			}
+
+			result.Run, err = q.UpdateScheduledTransferRun(ctx, runArg)
+			if err != nil {
+				return err
+			}
+
+			if transferErr == nil {
+				_, err = recordAudit(ctx, q, AuditChange{
+					TargetType: AuditTargetTransfer,
+					TargetID:   strconv.FormatInt(result.Transfer.Transfer.ID, 10),
+					After:      result.Transfer.Transfer,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		advance := AdvanceScheduledTransferParams{
+			ID:            scheduled.ID,
+			NextRunAt:     scheduled.NextRunAt,
+			NextAttemptAt: scheduled.NextAttemptAt,
+			Status:        scheduled.Status,
+			FailureCount:  scheduled.FailureCount,
+			LastError:     result.Run.LastError,
+		}
+
+		if result.Run.Status == ScheduledRunRetrying {
+			advance.NextAttemptAt = arg.Now.Add(arg.RetryDelay << (result.Run.Attempts - 1))
+		} else {
+			advance.FailureCount = 0
+			if result.Run.Status == ScheduledRunFailed {
+				advance.FailureCount = scheduled.FailureCount + 1
+			}
+
+			// пропущенные из-за простоя повторения выполняются по очереди, а не пропускаются
+			next, err := util.NextOccurrence(scheduled.Recurrence, scheduled.NextRunAt)
+			if err != nil {
+				return err
+			}
+			advance.NextRunAt = next
+			advance.NextAttemptAt = next
+			if scheduled.EndAt.Valid && next.After(scheduled.EndAt.Time) {
+				advance.Status = ScheduledTransferCompleted
+			}
+		}
+
+		result.ScheduledTransfer, err = q.AdvanceScheduledTransfer(ctx, advance)
+		return err
+	})
+
+	return result, err
+}
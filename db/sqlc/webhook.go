@@ -0,0 +1,117 @@
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookTransferReceived = "transfer.received"
+	WebhookTransferSent     = "transfer.sent"
+	WebhookAccountCreated   = "account.created"
+	// WebhookTest is only sent on request, nobody can subscribe to it
+	WebhookTest = "webhook.test"
+)
+
+// WebhookEventTypes are the events a subscription can ask for
+var WebhookEventTypes = []string{WebhookTransferReceived, WebhookTransferSent, WebhookAccountCreated}
+
+const (
+	WebhookActive   = "active"
+	WebhookDisabled = "disabled"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEvent is the JSON body posted to subscribers
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// WebhookTransfer is the data of the transfer events, seen from the subscriber's account
+type WebhookTransfer struct {
+	TransferID    int64  `json:"transfer_id"`
+	AccountID     int64  `json:"account_id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Fee           int64  `json:"fee"`
+	Currency      string `json:"currency"`
+	// Balance is the account balance right after the transfer
+	Balance int64 `json:"balance"`
+}
+
+// WebhookAccount is the data of account.created
+type WebhookAccount struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Product   string    `json:"product"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewWebhookEvent wraps data into an event with a new id
+func NewWebhookEvent(eventType string, data any) WebhookEvent {
+	return WebhookEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// enqueueWebhooks queues a delivery of the event to every active subscription of owner that wants it.
+// Deliveries are written in the caller's transaction, so rolled back changes are never announced.
+func enqueueWebhooks(ctx context.Context, q *Queries, owner string, event WebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateWebhookDeliveries(ctx, CreateWebhookDeliveriesParams{
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+		Owner:     owner,
+	})
+	return err
+}
+
+// enqueueTransferWebhooks tells the sender and the recipient about the transfer
+func enqueueTransferWebhooks(ctx context.Context, q *Queries, result TransferTxResult) error {
+	transfer := result.Transfer
+
+	sent := NewWebhookEvent(WebhookTransferSent, WebhookTransfer{
+		TransferID:    transfer.ID,
+		AccountID:     result.FromAccount.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Fee:           transfer.Fee,
+		Currency:      result.FromAccount.Currency,
+		Balance:       result.FromAccount.Balance,
+	})
+	if err := enqueueWebhooks(ctx, q, result.FromAccount.Owner, sent); err != nil {
+		return err
+	}
+
+	received := NewWebhookEvent(WebhookTransferReceived, WebhookTransfer{
+		TransferID:    transfer.ID,
+		AccountID:     result.ToAccount.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Currency:      result.ToAccount.Currency,
+		Balance:       result.ToAccount.Balance,
+	})
+	return enqueueWebhooks(ctx, q, result.ToAccount.Owner, received)
+}
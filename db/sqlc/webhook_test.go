@@ -0,0 +1,120 @@
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhook(t *testing.T, owner string, eventTypes ...string) WebhookSubscription {
+	webhook, err := testQueries.CreateWebhookSubscription(context.Background(), CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        "https://example.com/hooks",
+		EventTypes: eventTypes,
+		Secret:     "whsec_test",
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookActive, webhook.Status)
+	return webhook
+}
+
+func TestTransferTxQueuesWebhooks(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	received := createRandomWebhook(t, account2.Owner, WebhookTransferReceived)
+	// отправитель подписан только на входящие переводы, исходящий ему не придёт
+	sent := createRandomWebhook(t, account1.Owner, WebhookTransferReceived)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: received.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, WebhookTransferReceived, deliveries[0].EventType)
+	require.Equal(t, WebhookDeliveryPending, deliveries[0].Status)
+
+	var event struct {
+		Data WebhookTransfer `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &event))
+	require.Equal(t, result.Transfer.ID, event.Data.TransferID)
+	require.Equal(t, account2.ID, event.Data.AccountID)
+
+	deliveries, err = testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: sent.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+}
+
+func TestRecordWebhookFailureDisables(t *testing.T) {
+	user := createRandomUser(t)
+	webhook := createRandomWebhook(t, user.Username, WebhookAccountCreated)
+
+	for i := 1; i <= 3; i++ {
+		updated, err := testQueries.RecordWebhookFailure(context.Background(), RecordWebhookFailureParams{
+			ID:           webhook.ID,
+			DisableAfter: 3,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(i), updated.FailureCount)
+		webhook = updated
+	}
+	require.Equal(t, WebhookDisabled, webhook.Status)
+	require.True(t, webhook.DisabledAt.Valid)
+
+	webhook, err := testQueries.EnableWebhookSubscription(context.Background(), webhook.ID)
+	require.NoError(t, err)
+	require.Equal(t, WebhookActive, webhook.Status)
+	require.Zero(t, webhook.FailureCount)
+}
+
+func TestClaimDueWebhookDeliveries(t *testing.T) {
+	user := createRandomUser(t)
+	webhook := createRandomWebhook(t, user.Username, WebhookAccountCreated)
+
+	delivery, err := testQueries.CreateWebhookDelivery(context.Background(), CreateWebhookDeliveryParams{
+		SubscriptionID: webhook.ID,
+		EventID:        "event-claim",
+		EventType:      WebhookAccountCreated,
+		Payload:        json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+
+	claimed := func(now time.Time) bool {
+		rows, err := testQueries.ClaimDueWebhookDeliveries(context.Background(), ClaimDueWebhookDeliveriesParams{
+			Now:        now,
+			RowLimit:   1000,
+			LeaseUntil: now.Add(time.Hour),
+		})
+		require.NoError(t, err)
+		for _, row := range rows {
+			if row.ID == delivery.ID {
+				require.Equal(t, webhook.Url, row.Url)
+				require.Equal(t, webhook.Secret, row.Secret)
+				return true
+			}
+		}
+		return false
+	}
+
+	now := time.Now().Add(time.Minute)
+	require.True(t, claimed(now))
+	// пока аренда не истекла, другой диспетчер доставку не получит
+	require.False(t, claimed(now))
+	require.True(t, claimed(now.Add(2*time.Hour)))
+}
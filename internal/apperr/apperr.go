@@ -0,0 +1,314 @@
+// Package apperr is the error model the API speaks. A handler returns an *Error with a stable
+// machine-readable code and the id of a message written for the client; the cause is kept for
+// the server log only. Gin renders it as RFC 7807 problem details, gRPC as a status with details.
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/i18n"
+	"github.com/hisshihi/simple-bank/internal/validate"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+)
+
+// Code identifies the kind of error, clients may switch on it, so a published code never changes
+type Code string
+
+const (
+	CodeValidationFailed  Code = "validation_failed"
+	CodeUnauthenticated   Code = "unauthenticated"
+	CodeForbidden         Code = "forbidden"
+	CodeNotFound          Code = "not_found"
+	CodeAlreadyExists     Code = "already_exists"
+	CodeConflict          Code = "conflict"
+	CodeInsufficientFunds Code = "insufficient_funds"
+	CodeCurrencyMismatch  Code = "currency_mismatch"
+	CodeRateLimited       Code = "rate_limited"
+	CodeUnavailable       Code = "unavailable"
+	CodeInternal          Code = "internal"
+)
+
+type codeInfo struct {
+	status int
+	grpc   codes.Code
+}
+
+var codeInfos = map[Code]codeInfo{
+	CodeValidationFailed:  {http.StatusBadRequest, codes.InvalidArgument},
+	CodeUnauthenticated:   {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeForbidden:         {http.StatusForbidden, codes.PermissionDenied},
+	CodeNotFound:          {http.StatusNotFound, codes.NotFound},
+	CodeAlreadyExists:     {http.StatusConflict, codes.AlreadyExists},
+	CodeConflict:          {http.StatusConflict, codes.FailedPrecondition},
+	CodeInsufficientFunds: {http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	CodeCurrencyMismatch:  {http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	CodeRateLimited:       {http.StatusTooManyRequests, codes.ResourceExhausted},
+	CodeUnavailable:       {http.StatusServiceUnavailable, codes.Unavailable},
+	CodeInternal:          {http.StatusInternalServerError, codes.Internal},
+}
+
+// fieldMessages is the catalog section of the field violations, under CodeValidationFailed
+const fieldMessages = "field."
+
+// Title is the short summary of the code in locale, the same for every error of the code
+func (code Code) Title(locale string) string {
+	return i18n.Translate(locale, string(code))
+}
+
+func (code Code) known() bool {
+	_, ok := codeInfos[code]
+	return ok
+}
+
+// key is the catalog key of the message id of an error with the code
+func (code Code) key(message string) string {
+	return string(code) + "." + message
+}
+
+// HTTPStatus is the status a Gin response with the code gets
+func (code Code) HTTPStatus() int {
+	if info, ok := codeInfos[code]; ok {
+		return info.status
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode is the gRPC status code a call failing with the code returns
+func (code Code) GRPCCode() codes.Code {
+	if info, ok := codeInfos[code]; ok {
+		return info.grpc
+	}
+	return codes.Internal
+}
+
+// FieldViolation tells which request field is invalid and why. Message is the id of a field
+// message, "required", until the violation is localized for a response
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	// Args fill in the verbs of Message once it is translated
+	Args []any `json:"-"`
+}
+
+type Error struct {
+	Code Code
+	// Message is the id of the message shown to the client, "account" for "not_found.account"
+	// in the catalogs. The text must not contain internal details
+	Message string
+	// Args fill in the verbs of Message once it is translated
+	Args []any
+	// Fields lists the invalid fields of a validation error
+	Fields []FieldViolation
+	// RetryAfter is how long the client should wait before calling again, zero when it may retry at once
+	RetryAfter time.Duration
+	// Err is the cause, it is logged and never sent to the client
+	Err error
+
+	// localized is set on an error rebuilt from a status, its Message and Fields are texts
+	localized bool
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Localize(i18n.English), e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Localize(i18n.English))
+}
+
+// Localize returns the message in locale
+func (e *Error) Localize(locale string) string {
+	if e.localized {
+		return e.Message
+	}
+	return i18n.Translate(locale, e.Code.key(e.Message), e.Args...)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfterSeconds rounds RetryAfter up to whole seconds for the Retry-After header,
+// zero means the header is not sent
+func (e *Error) RetryAfterSeconds() int {
+	if e.RetryAfter <= 0 {
+		return 0
+	}
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// New makes an error with the message id, args fill in the verbs of its text
+func New(code Code, message string, args ...any) *Error {
+	return &Error{Code: code, Message: message, Args: args}
+}
+
+func NotFound(message string, args ...any) *Error {
+	return New(CodeNotFound, message, args...)
+}
+
+func Forbidden(message string, args ...any) *Error {
+	return New(CodeForbidden, message, args...)
+}
+
+func Unauthenticated(message string, args ...any) *Error {
+	return New(CodeUnauthenticated, message, args...)
+}
+
+func Conflict(message string, args ...any) *Error {
+	return New(CodeConflict, message, args...)
+}
+
+func AlreadyExists(message string, args ...any) *Error {
+	return New(CodeAlreadyExists, message, args...)
+}
+
+func InsufficientFunds(message string, args ...any) *Error {
+	return New(CodeInsufficientFunds, message, args...)
+}
+
+// RateLimited tells the client to slow down and come back after retryAfter
+func RateLimited(retryAfter time.Duration) *Error {
+	e := &Error{Code: CodeRateLimited, Message: "retry", RetryAfter: retryAfter}
+	e.Args = []any{e.RetryAfterSeconds()}
+	return e
+}
+
+// Invalid is a validation error that is not about a single field
+func Invalid(message string, args ...any) *Error {
+	return New(CodeValidationFailed, message, args...)
+}
+
+// Internal hides err from the client behind a generic message
+func Internal(err error) *Error {
+	return &Error{Code: CodeInternal, Message: "error", Err: err}
+}
+
+// Validation turns a binding error into field violations, other errors become a generic
+// validation failure, the text of a JSON syntax error is safe to show
+func Validation(err error) *Error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		e := &Error{Code: CodeValidationFailed, Message: "request", Err: err}
+		for _, fieldErr := range validationErrors {
+			message, args := violationMessage(fieldErr)
+			e.Fields = append(e.Fields, FieldViolation{
+				Field:   fieldErr.Field(),
+				Message: message,
+				Args:    args,
+			})
+		}
+		return e
+	}
+
+	return &Error{Code: CodeValidationFailed, Message: "body", Args: []any{err.Error()}, Err: err}
+}
+
+func violationMessage(fieldErr validator.FieldError) (string, []any) {
+	switch fieldErr.Tag() {
+	case "required":
+		return "required", nil
+	case "email":
+		return "email", nil
+	case "alphanum":
+		return "alphanum", nil
+	case "http_url":
+		return "http_url", nil
+	case "currency":
+		return "currency", nil
+	case "recurrence":
+		return "recurrence", nil
+	case "username":
+		return "username", nil
+	case "full_name":
+		return "full_name", nil
+	case "min", "gte":
+		return "min", []any{fieldErr.Param()}
+	case "gt":
+		return "gt", []any{fieldErr.Param()}
+	case "max", "lte":
+		return "max", []any{fieldErr.Param()}
+	case "lt":
+		return "lt", []any{fieldErr.Param()}
+	case "oneof":
+		return "oneof", []any{fieldErr.Param()}
+	default:
+		return "check", []any{fieldErr.Tag()}
+	}
+}
+
+// Violations collects the field violations of a request checked by hand
+type Violations []FieldViolation
+
+// Check records err as a violation of field, a nil err is ignored. An error that is not
+// a validate.RuleError has no message id and is reported as "is invalid"
+func (violations *Violations) Check(field string, err error) {
+	if err == nil {
+		return
+	}
+
+	violation := FieldViolation{Field: field, Message: "invalid"}
+	var ruleErr *validate.RuleError
+	if errors.As(err, &ruleErr) {
+		violation.Message, violation.Args = ruleErr.Message, ruleErr.Args
+	}
+	*violations = append(*violations, violation)
+}
+
+// Err is the validation error of the request, nil when nothing is violated
+func (violations Violations) Err() error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &Error{Code: CodeValidationFailed, Message: "request", Fields: violations}
+}
+
+// From converts any error a handler gets into an *Error: an *Error is kept as it is,
+// the store's domain errors and the token errors get their codes and the rest is internal
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return &Error{Code: CodeNotFound, Message: "resource", Err: err}
+	case errors.Is(err, sqlc.ErrInsufficientFunds):
+		return &Error{Code: CodeInsufficientFunds, Message: "balance", Err: err}
+	case errors.Is(err, util.ErrExpiredToken):
+		return &Error{Code: CodeUnauthenticated, Message: "token_expired", Err: err}
+	case errors.Is(err, util.ErrInvalidToken):
+		return &Error{Code: CodeUnauthenticated, Message: "token_invalid", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Code: CodeUnavailable, Message: "canceled", Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Code: CodeUnavailable, Message: "timeout", Err: err}
+	case sqlc.IsRetryable(err):
+		return &Error{Code: CodeConflict, Message: "concurrent_update", Err: err}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return &Error{Code: CodeAlreadyExists, Message: "resource", Err: err}
+		case "foreign_key_violation":
+			return &Error{Code: CodeValidationFailed, Message: "reference", Err: err}
+		}
+	}
+
+	return Internal(err)
+}
+
+// CodeOf returns the code err is converted to
+func CodeOf(err error) Code {
+	return From(err).Code
+}
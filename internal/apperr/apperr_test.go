@@ -0,0 +1,173 @@
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/i18n"
+	"github.com/hisshihi/simple-bank/internal/validate"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFrom(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		code    Code
+		message string
+	}{
+		{"AppError", fmt.Errorf("wrapped: %w", NotFound("account")), CodeNotFound, "account not found"},
+		{"NoRows", sql.ErrNoRows, CodeNotFound, "resource not found"},
+		{"InsufficientFunds", fmt.Errorf("transfer: %w", sqlc.ErrInsufficientFunds), CodeInsufficientFunds, "insufficient funds"},
+		{"DeadlineExceeded", context.DeadlineExceeded, CodeUnavailable, "request timed out"},
+		{"SerializationFailure", &pq.Error{Code: "40001"}, CodeConflict, "concurrent update, please retry"},
+		{"UniqueViolation", &pq.Error{Code: "23505", Detail: "Key (username)=(alice) already exists."}, CodeAlreadyExists, "resource already exists"},
+		{"ForeignKeyViolation", &pq.Error{Code: "23503"}, CodeValidationFailed, "referenced resource does not exist"},
+		{"ExpiredToken", util.ErrExpiredToken, CodeUnauthenticated, "token has expired"},
+		{"Unknown", errors.New(`pq: relation "accounts" does not exist`), CodeInternal, "internal server error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := From(tc.err)
+			require.Equal(t, tc.code, e.Code)
+			require.Equal(t, tc.message, e.Localize(i18n.English))
+			require.NotContains(t, e.Localize(i18n.English), "pq:")
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	type request struct {
+		Currency string `json:"currency" validate:"required"`
+		Amount   int64  `json:"amount" validate:"gt=0"`
+	}
+
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string { return field.Tag.Get("json") })
+
+	e := Validation(validate.Struct(request{}))
+	require.Equal(t, CodeValidationFailed, e.Code)
+	require.Equal(t, []FieldViolation{
+		{Field: "currency", Message: "is required"},
+		{Field: "amount", Message: "must be greater than 0"},
+	}, e.localizeFields(i18n.English))
+	require.Equal(t, "должно быть больше 0", e.Fields[1].Localize(i18n.Russian))
+
+	e = Validation(errors.New("unexpected EOF"))
+	require.Equal(t, "request body is invalid: unexpected EOF", e.Localize(i18n.English))
+	require.Empty(t, e.Fields)
+}
+
+func TestProblem(t *testing.T) {
+	problem := InsufficientFunds("balance").Problem(i18n.English, "/transfers", "req-1")
+	require.Equal(t, Problem{
+		Type:      "urn:simple-bank:problem:insufficient_funds",
+		Title:     "Insufficient funds",
+		Status:    http.StatusUnprocessableEntity,
+		Detail:    "insufficient funds",
+		Instance:  "/transfers",
+		Code:      CodeInsufficientFunds,
+		RequestID: "req-1",
+	}, problem)
+
+	problem = NotFound("account_id", 7).Problem(i18n.Russian, "/accounts/7", "req-2")
+	require.Equal(t, "Не найдено", problem.Title)
+	require.Equal(t, "счёт 7 не найден", problem.Detail)
+	require.Equal(t, CodeNotFound, problem.Code)
+}
+
+func TestStatus(t *testing.T) {
+	e := &Error{
+		Code:    CodeValidationFailed,
+		Message: "request",
+		Fields:  []FieldViolation{{Field: "username", Message: "required"}},
+		Err:     errors.New("cause"),
+	}
+
+	st := e.Status(i18n.English, "req-1")
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.Equal(t, "request is invalid", st.Message())
+
+	var info *errdetails.ErrorInfo
+	var requestInfo *errdetails.RequestInfo
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = detail
+		case *errdetails.RequestInfo:
+			requestInfo = detail
+		}
+	}
+	require.Equal(t, "VALIDATION_FAILED", info.GetReason())
+	require.Equal(t, Domain, info.GetDomain())
+	require.Equal(t, "req-1", requestInfo.GetRequestId())
+
+	ru := e.Status(i18n.Russian, "req-1")
+	require.Equal(t, "некорректный запрос", ru.Message())
+	localized, ok := ru.Details()[1].(*errdetails.LocalizedMessage)
+	require.True(t, ok)
+	require.Equal(t, i18n.Russian, localized.GetLocale())
+	require.Equal(t, "некорректный запрос", localized.GetMessage())
+
+	// через статус код и поля возвращаются уже переведёнными и без причины
+	back := FromStatus(ru)
+	require.Equal(t, e.Code, back.Code)
+	require.Equal(t, "некорректный запрос", back.Localize(i18n.English))
+	require.Equal(t, []FieldViolation{{Field: "username", Message: "обязательное поле"}}, back.localizeFields(i18n.English))
+	require.NoError(t, back.Err)
+
+	// статус без ErrorInfo, например от самого gRPC
+	require.Equal(t, CodeNotFound, FromStatus(status.New(codes.Unimplemented, "unknown method")).Code)
+	internal := FromStatus(status.New(codes.Internal, "pq: connection refused"))
+	require.Equal(t, CodeInternal, internal.Code)
+	require.Equal(t, "internal server error", internal.Localize(i18n.English))
+}
+
+func TestRateLimited(t *testing.T) {
+	e := RateLimited(1500 * time.Millisecond)
+	require.Equal(t, 2, e.RetryAfterSeconds())
+	require.Equal(t, http.StatusTooManyRequests, e.Code.HTTPStatus())
+	require.Equal(t, "too many requests, retry in 2 seconds", e.Localize(i18n.English))
+	require.Equal(t, "слишком много запросов, повторите через 2 с", e.Localize(i18n.Russian))
+	require.Zero(t, (&Error{Code: CodeRateLimited}).RetryAfterSeconds())
+
+	st := e.Status(i18n.English, "")
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	retryInfo, ok := st.Details()[2].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.Equal(t, 1500*time.Millisecond, retryInfo.GetRetryDelay().AsDuration())
+
+	back := FromStatus(st)
+	require.Equal(t, CodeRateLimited, back.Code)
+	require.Equal(t, e.RetryAfter, back.RetryAfter)
+}
+
+func TestViolations(t *testing.T) {
+	var violations Violations
+	violations.Check("username", nil)
+	require.NoError(t, violations.Err())
+
+	violations.Check("email", validate.Email("john"))
+	violations.Check("token", errors.New("no rule"))
+	e := From(violations.Err())
+	require.Equal(t, CodeValidationFailed, e.Code)
+	require.Equal(t, codes.InvalidArgument, e.Code.GRPCCode())
+	require.Equal(t, []FieldViolation{
+		{Field: "email", Message: "must be a valid email"},
+		{Field: "token", Message: "is invalid"},
+	}, e.localizeFields(i18n.English))
+}
@@ -0,0 +1,172 @@
+package apperr
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hisshihi/simple-bank/internal/i18n"
+	"github.com/stretchr/testify/require"
+)
+
+// constructors maps the functions that take a message id to the code of the error they make
+var constructors = map[string]Code{
+	"NotFound":          CodeNotFound,
+	"Forbidden":         CodeForbidden,
+	"Unauthenticated":   CodeUnauthenticated,
+	"Conflict":          CodeConflict,
+	"AlreadyExists":     CodeAlreadyExists,
+	"InsufficientFunds": CodeInsufficientFunds,
+	"Invalid":           CodeValidationFailed,
+}
+
+// TestCatalogs checks that every code has a title and every message id the source uses has
+// a text. i18n's TestCatalogs checks that every locale translates all of them
+func TestCatalogs(t *testing.T) {
+	for code := range codeInfos {
+		require.NotEqual(t, string(code), code.Title(i18n.English), "no title for %s", code)
+	}
+
+	keys := messageKeys(t, "../..")
+	require.NotEmpty(t, keys)
+	for key, position := range keys {
+		require.NotEqual(t, key, i18n.Translate(i18n.English, key), "%s: no message %q", position, key)
+	}
+}
+
+// messageKeys finds the catalog keys of the message ids written in the source under root:
+// the ids passed to New and the constructors, set in an Error or FieldViolation literal,
+// returned by violationMessage and passed to validate's ruleError
+func messageKeys(t *testing.T, root string) map[string]token.Position {
+	codes := codeConstants(t)
+	fset := token.NewFileSet()
+	keys := make(map[string]token.Position)
+
+	add := func(code Code, field bool, lit ast.Expr) {
+		basic, ok := lit.(*ast.BasicLit)
+		if !ok || basic.Kind != token.STRING {
+			return
+		}
+		id, err := strconv.Unquote(basic.Value)
+		require.NoError(t, err)
+		if field {
+			id = fieldMessages + id
+		}
+		keys[code.key(id)] = fset.Position(basic.Pos())
+	}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.CallExpr:
+				name := calleeName(node.Fun)
+				switch {
+				case name == "ruleError" && len(node.Args) > 0:
+					add(CodeValidationFailed, true, node.Args[0])
+				case name == "New" && len(node.Args) > 1:
+					if code, ok := codes[calleeName(node.Args[0])]; ok {
+						add(code, false, node.Args[1])
+					}
+				case constructors[name] != "" && len(node.Args) > 0:
+					add(constructors[name], false, node.Args[0])
+				}
+			case *ast.CompositeLit:
+				literalKeys(node, codes, add)
+			case *ast.FuncDecl:
+				if node.Name.Name == "violationMessage" {
+					ast.Inspect(node.Body, func(node ast.Node) bool {
+						if ret, ok := node.(*ast.ReturnStmt); ok && len(ret.Results) > 0 {
+							add(CodeValidationFailed, true, ret.Results[0])
+						}
+						return true
+					})
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	return keys
+}
+
+// literalKeys adds the message id of an Error{Code: ..., Message: "..."} or
+// FieldViolation{Message: "..."} literal
+func literalKeys(lit *ast.CompositeLit, codes map[string]Code, add func(Code, bool, ast.Expr)) {
+	typeName := calleeName(lit.Type)
+	if typeName != "Error" && typeName != "FieldViolation" {
+		return
+	}
+
+	var code Code
+	var message ast.Expr
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		switch calleeName(kv.Key) {
+		case "Code":
+			code = codes[calleeName(kv.Value)]
+		case "Message":
+			message = kv.Value
+		}
+	}
+	switch {
+	case message == nil:
+	case typeName == "FieldViolation":
+		add(CodeValidationFailed, true, message)
+	case code != "":
+		add(code, false, message)
+	}
+}
+
+// codeConstants maps the names of the Code constants to their values
+func codeConstants(t *testing.T) map[string]Code {
+	file, err := parser.ParseFile(token.NewFileSet(), "apperr.go", nil, 0)
+	require.NoError(t, err)
+
+	codes := make(map[string]Code)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, name := range value.Names {
+				if lit, ok := value.Values[i].(*ast.BasicLit); ok && strings.HasPrefix(name.Name, "Code") {
+					s, err := strconv.Unquote(lit.Value)
+					require.NoError(t, err)
+					codes[name.Name] = Code(s)
+				}
+			}
+		}
+	}
+	require.Len(t, codes, len(codeInfos))
+	return codes
+}
+
+// calleeName is the name of an identifier, pkg.Name or the type of &T{}
+func calleeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	}
+	return ""
+}
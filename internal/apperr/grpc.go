@@ -0,0 +1,97 @@
+package apperr
+
+import (
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the ErrorInfo domain of the errors the service returns
+const Domain = "simple-bank"
+
+// Status converts e into a gRPC status with the message in locale, the code travels
+// in ErrorInfo.reason, the locale in LocalizedMessage, field violations in BadRequest,
+// the retry delay in RetryInfo and the request id in RequestInfo
+func (e *Error) Status(locale, requestID string) *status.Status {
+	message := e.Localize(locale)
+	st := status.New(e.Code.GRPCCode(), message)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: strings.ToUpper(string(e.Code)), Domain: Domain},
+		&errdetails.LocalizedMessage{Locale: locale, Message: message},
+	}
+	if len(e.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range e.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Localize(locale),
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)})
+	}
+	if requestID != "" {
+		details = append(details, &errdetails.RequestInfo{RequestId: requestID})
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// FromStatus recovers the *Error a status was made from, its messages are already localized.
+// A status without ErrorInfo (one made by gRPC or the gateway itself) gets the code closest
+// to its gRPC code
+func FromStatus(st *status.Status) *Error {
+	e := &Error{Code: codeFromGRPC(st.Code()), Message: st.Message(), localized: true}
+
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if code := Code(strings.ToLower(detail.GetReason())); detail.GetDomain() == Domain && code.known() {
+				e.Code = code
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				e.Fields = append(e.Fields, FieldViolation{Field: violation.GetField(), Message: violation.GetDescription()})
+			}
+		case *errdetails.RetryInfo:
+			e.RetryAfter = detail.GetRetryDelay().AsDuration()
+		}
+	}
+
+	if e.Code == CodeInternal {
+		e.Message, e.localized = "error", false
+	}
+	return e
+}
+
+func codeFromGRPC(code codes.Code) Code {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return CodeValidationFailed
+	case codes.Unauthenticated:
+		return CodeUnauthenticated
+	case codes.PermissionDenied:
+		return CodeForbidden
+	case codes.NotFound, codes.Unimplemented:
+		return CodeNotFound
+	case codes.AlreadyExists:
+		return CodeAlreadyExists
+	case codes.FailedPrecondition, codes.Aborted:
+		return CodeConflict
+	case codes.Unavailable, codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return CodeUnavailable
+	default:
+		return CodeInternal
+	}
+}
@@ -0,0 +1,54 @@
+package apperr
+
+import "github.com/hisshihi/simple-bank/internal/i18n"
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix makes the problem type a stable URI built from the error code
+const problemTypePrefix = "urn:simple-bank:problem:"
+
+// Problem is the RFC 7807 body of an HTTP error response, code and request_id are extension members
+type Problem struct {
+	Type      string           `json:"type"`
+	Title     string           `json:"title"`
+	Status    int              `json:"status"`
+	Detail    string           `json:"detail"`
+	Instance  string           `json:"instance"`
+	Code      Code             `json:"code"`
+	RequestID string           `json:"request_id"`
+	Errors    []FieldViolation `json:"errors,omitempty"`
+}
+
+// Problem describes e in locale for a response to the request at instance
+func (e *Error) Problem(locale, instance, requestID string) Problem {
+	return Problem{
+		Type:      problemTypePrefix + string(e.Code),
+		Title:     e.Code.Title(locale),
+		Status:    e.Code.HTTPStatus(),
+		Detail:    e.Localize(locale),
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.localizeFields(locale),
+	}
+}
+
+// Localize returns the message in locale
+func (violation FieldViolation) Localize(locale string) string {
+	return i18n.Translate(locale, CodeValidationFailed.key(fieldMessages+violation.Message), violation.Args...)
+}
+
+func (e *Error) localizeFields(locale string) []FieldViolation {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	if e.localized {
+		return e.Fields
+	}
+	localized := make([]FieldViolation, len(e.Fields))
+	for i, field := range e.Fields {
+		localized[i] = FieldViolation{Field: field.Field, Message: field.Localize(locale)}
+	}
+	return localized
+}
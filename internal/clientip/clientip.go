@@ -0,0 +1,154 @@
+// Package clientip finds the address of the client behind reverse proxies. The forwarding
+// headers are only believed when they were added by a trusted proxy: the chain is walked from
+// the peer towards the client and the first hop that is not a trusted proxy is the client.
+// The same Resolver serves Gin, the gateway and gRPC, so they all record the same address.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+const (
+	ForwardedHeader     = "Forwarded"
+	XForwardedForHeader = "X-Forwarded-For"
+	XRealIPHeader       = "X-Real-IP"
+)
+
+// Headers are the forwarding headers of a request, each may be sent several times
+type Headers struct {
+	Forwarded     []string
+	XForwardedFor []string
+	XRealIP       []string
+}
+
+// Resolver knows which proxies may report the client's address
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// NewResolver parses the trusted proxies, IPs and CIDRs; none trusted means the peer is the client
+func NewResolver(trusted []string) (*Resolver, error) {
+	resolver := &Resolver{trusted: make([]*net.IPNet, 0, len(trusted))}
+	for _, entry := range trusted {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			resolver.trusted = append(resolver.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		ip, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		// 172.17.0.2/12 разбирается, но почти наверняка имелась в виду другая сеть
+		if !ip.Equal(network.IP) {
+			return nil, fmt.Errorf("trusted proxy %q has host bits set, did you mean %s?", entry, network)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+func (resolver *Resolver) isTrusted(ip net.IP) bool {
+	return slices.ContainsFunc(resolver.trusted, func(network *net.IPNet) bool {
+		return network.Contains(ip)
+	})
+}
+
+// Resolve returns the client's IP without a port. peer is the address of the connection,
+// it may be empty when the chain already ends with it, as in the metadata the gateway passes
+// on. Forwarded wins over X-Forwarded-For, X-Real-IP is used when neither is sent
+func (resolver *Resolver) Resolve(peer string, headers Headers) string {
+	var chain []string
+	switch {
+	case len(headers.Forwarded) > 0:
+		chain = parseForwarded(headers.Forwarded)
+	case len(headers.XForwardedFor) > 0:
+		for _, value := range headers.XForwardedFor {
+			chain = append(chain, splitList(value)...)
+		}
+	case len(headers.XRealIP) > 0:
+		chain = []string{strings.TrimSpace(headers.XRealIP[len(headers.XRealIP)-1])}
+	}
+	if peer != "" {
+		chain = append(chain, peer)
+	}
+
+	// от ближайшего узла к клиенту: первый недоверенный адрес и есть клиент
+	client := ""
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := parseHop(chain[i])
+		if ip == nil {
+			// мусор в заголовке: клиентом считаем последний разобранный узел
+			break
+		}
+		client = ip.String()
+		if !resolver.isTrusted(ip) {
+			break
+		}
+	}
+	return client
+}
+
+// parseForwarded returns the for= nodes of RFC 7239 Forwarded values in order
+func parseForwarded(values []string) []string {
+	var nodes []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			node := ""
+			for _, pair := range strings.Split(element, ";") {
+				key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(key, "for") {
+					node = strings.Trim(val, `"`)
+				}
+			}
+			// узел без for= или с obfuscated/unknown не разбирается и останавливает обход
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// parseHop accepts an IP with an optional port, IPv6 in brackets when the port is present
+func parseHop(hop string) net.IP {
+	hop = strings.TrimSpace(hop)
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+	hop = strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]")
+	return net.ParseIP(hop)
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+type clientIPKey struct{}
+
+// WithClientIP stores the resolved client IP of the request
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// FromContext returns the client IP stored by a middleware or interceptor, empty when none ran
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
@@ -0,0 +1,76 @@
+package clientip
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestNewResolver(t *testing.T) {
+	resolver, err := NewResolver([]string{"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12"})
+	require.NoError(t, err)
+	require.Len(t, resolver.trusted, 4)
+	require.Equal(t, "127.0.0.1/32", resolver.trusted[0].String())
+	require.Equal(t, "::1/128", resolver.trusted[1].String())
+
+	for _, invalid := range []string{"localhost", "10.0.0.0/33", "172.17.0.2/12"} {
+		_, err := NewResolver([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "2001:db8::/32"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		peer     string
+		headers  Headers
+		clientIP string
+	}{
+		{"NoProxy", "203.0.113.7:5000", Headers{}, "203.0.113.7"},
+		{"UntrustedPeerIgnoresHeaders", "203.0.113.7:5000", Headers{XForwardedFor: []string{"198.51.100.1"}, XRealIP: []string{"198.51.100.2"}}, "203.0.113.7"},
+		{"XForwardedFor", "10.0.0.2:5000", Headers{XForwardedFor: []string{"198.51.100.1"}}, "198.51.100.1"},
+		{"SpoofedXForwardedFor", "10.0.0.2:5000", Headers{XForwardedFor: []string{"1.1.1.1, 198.51.100.1", "10.0.0.3"}}, "198.51.100.1"},
+		{"OnlyProxies", "10.0.0.2:5000", Headers{XForwardedFor: []string{"10.0.0.3"}}, "10.0.0.3"},
+		{"ChainEndsWithPeer", "", Headers{XForwardedFor: []string{"198.51.100.1, 10.0.0.2"}}, "198.51.100.1"},
+		{"GarbageInChain", "10.0.0.2:5000", Headers{XForwardedFor: []string{"not-an-ip"}}, "10.0.0.2"},
+		{"XRealIP", "10.0.0.2:5000", Headers{XRealIP: []string{"198.51.100.1"}}, "198.51.100.1"},
+		{"Forwarded", "10.0.0.2:5000", Headers{Forwarded: []string{`for=198.51.100.1;proto=https, for="[2001:db8::1]:4711"`}}, "198.51.100.1"},
+		{"ForwardedIPv6Client", "10.0.0.2:5000", Headers{Forwarded: []string{`for="[2001:db9::17]:4711"`}}, "2001:db9::17"},
+		{"ForwardedWinsOverXForwardedFor", "10.0.0.2:5000", Headers{Forwarded: []string{"for=198.51.100.1"}, XForwardedFor: []string{"198.51.100.2"}}, "198.51.100.1"},
+		{"ForwardedObfuscated", "10.0.0.2:5000", Headers{Forwarded: []string{"for=_hidden"}}, "10.0.0.2"},
+		{"IPv6Peer", "[2001:db9::1]:443", Headers{}, "2001:db9::1"},
+		{"Empty", "", Headers{}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.clientIP, resolver.Resolve(tc.peer, tc.headers))
+		})
+	}
+}
+
+func TestFromRequestAndContext(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	request.RemoteAddr = "10.0.0.2:5000"
+	request.Header.Set(XForwardedForHeader, "198.51.100.1")
+	require.Equal(t, "198.51.100.1", resolver.FromRequest(request))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-real-ip", "198.51.100.1"))
+	require.Equal(t, "198.51.100.1", resolver.FromIncomingContext(ctx))
+
+	require.Empty(t, FromContext(context.Background()))
+	require.Equal(t, "198.51.100.1", FromContext(WithClientIP(context.Background(), "198.51.100.1")))
+}
@@ -0,0 +1,49 @@
+package clientip
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// FromIncomingContext resolves the client IP of a gRPC call from its peer and metadata
+func (resolver *Resolver) FromIncomingContext(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var peerAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		peerAddr = p.Addr.String()
+	}
+	return resolver.Resolve(peerAddr, Headers{
+		Forwarded:     md.Get(strings.ToLower(ForwardedHeader)),
+		XForwardedFor: md.Get(strings.ToLower(XForwardedForHeader)),
+		XRealIP:       md.Get(strings.ToLower(XRealIPHeader)),
+	})
+}
+
+// UnaryServerInterceptor stores the client IP of the call in its context
+func (resolver *Resolver) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(WithClientIP(ctx, resolver.FromIncomingContext(ctx)), req)
+	}
+}
+
+// StreamServerInterceptor does for streams what UnaryServerInterceptor does for unary calls
+func (resolver *Resolver) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := WithClientIP(stream.Context(), resolver.FromIncomingContext(stream.Context()))
+		return handler(srv, &clientIPStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+type clientIPStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *clientIPStream) Context() context.Context {
+	return stream.ctx
+}
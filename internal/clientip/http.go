@@ -0,0 +1,36 @@
+package clientip
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// FromRequest resolves the client IP of an HTTP request
+func (resolver *Resolver) FromRequest(r *http.Request) string {
+	return resolver.Resolve(r.RemoteAddr, Headers{
+		Forwarded:     r.Header.Values(ForwardedHeader),
+		XForwardedFor: r.Header.Values(XForwardedForHeader),
+		XRealIP:       r.Header.Values(XRealIPHeader),
+	})
+}
+
+// GinMiddleware stores the client IP in the request's context, read it with FromContext
+func (resolver *Resolver) GinMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ip := resolver.FromRequest(ctx.Request)
+		ctx.Request = ctx.Request.WithContext(WithClientIP(ctx.Request.Context(), ip))
+		ctx.Next()
+	}
+}
+
+// GatewayMiddleware does for the gateway mux what GinMiddleware does for Gin,
+// the IP reaches the in-process gRPC handler with the request's context
+func (resolver *Resolver) GatewayMiddleware() runtime.Middleware {
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			next(w, r.WithContext(WithClientIP(r.Context(), resolver.FromRequest(r))), pathParams)
+		}
+	}
+}
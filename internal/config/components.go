@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Components says which parts of the application the process runs
+type Components struct {
+	GRPC    bool
+	Gateway bool
+	Gin     bool
+	Workers bool
+}
+
+// ParseComponents parses a comma separated list of grpc, gateway, gin and workers.
+// An empty list means all components.
+func ParseComponents(s string) (Components, error) {
+	var components Components
+	empty := true
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		empty = false
+
+		switch name {
+		case "grpc":
+			components.GRPC = true
+		case "gateway":
+			components.Gateway = true
+		case "gin":
+			components.Gin = true
+		case "workers":
+			components.Workers = true
+		default:
+			return Components{}, fmt.Errorf("unknown component %q, expected grpc, gateway, gin or workers", name)
+		}
+	}
+
+	if empty {
+		return Components{GRPC: true, Gateway: true, Gin: true, Workers: true}, nil
+	}
+	return components, nil
+}
+
+// MetricsServer reports whether the process needs its own metrics server: the workers'
+// metrics are otherwise served by the gateway or Gin server
+func (components Components) MetricsServer() bool {
+	return components.Workers && !components.Gateway && !components.Gin
+}
+
+// EnabledComponents parses COMPONENTS
+func (config Config) EnabledComponents() (Components, error) {
+	return ParseComponents(config.Components)
+}
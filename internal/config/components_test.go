@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComponents(t *testing.T) {
+	components, err := ParseComponents("grpc, workers")
+	require.NoError(t, err)
+	require.Equal(t, Components{GRPC: true, Workers: true}, components)
+	// метрики воркеров отдавать некому, кроме своего сервера
+	require.True(t, components.MetricsServer())
+
+	components, err = ParseComponents("")
+	require.NoError(t, err)
+	require.Equal(t, Components{GRPC: true, Gateway: true, Gin: true, Workers: true}, components)
+	require.False(t, components.MetricsServer())
+
+	_, err = ParseComponents("grpc,cron")
+	require.Error(t, err)
+}
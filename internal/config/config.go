@@ -1,63 +1,186 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Config is the effective configuration, fields tagged secret are masked by Print
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	DBSouceProd          string        `mapstructure:"DB_SOURCE_PROD"`
+	DBDriver string `mapstructure:"DB_DRIVER"`
+	DBSource string `mapstructure:"DB_SOURCE" secret:"true"`
+	// ENV is the profile, app.<ENV>.env is read over app.env when it exists
 	ENV                  string        `mapstructure:"ENV"`
 	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
 	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	GinServerAddress     string        `mapstructure:"GIN_SERVER_ADDRESS"`
+	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY" secret:"true"`
 	AccesTokenDuration   time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+
+	ScheduledTransferInterval    time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	ScheduledTransferMaxAttempts int64         `mapstructure:"SCHEDULED_TRANSFER_MAX_ATTEMPTS"`
+	ScheduledTransferRetryDelay  time.Duration `mapstructure:"SCHEDULED_TRANSFER_RETRY_DELAY"`
+
+	HoldTTL           time.Duration `mapstructure:"HOLD_TTL"`
+	HoldSweepInterval time.Duration `mapstructure:"HOLD_SWEEP_INTERVAL"`
+
+	OverdraftInterestRateBps  int64         `mapstructure:"OVERDRAFT_INTEREST_RATE_BPS"`
+	OverdraftInterestInterval time.Duration `mapstructure:"OVERDRAFT_INTEREST_INTERVAL"`
+
+	InterestAccrualInterval time.Duration `mapstructure:"INTEREST_ACCRUAL_INTERVAL"`
+
+	ReconciliationInterval time.Duration `mapstructure:"RECONCILIATION_INTERVAL"`
+
+	OutboxPublisher     string        `mapstructure:"OUTBOX_PUBLISHER"`
+	OutboxWebhookURL    string        `mapstructure:"OUTBOX_WEBHOOK_URL" secret:"true"`
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+
+	WebhookDeliveryInterval     time.Duration `mapstructure:"WEBHOOK_DELIVERY_INTERVAL"`
+	WebhookMaxAttempts          int64         `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookDisableAfterFailures int64         `mapstructure:"WEBHOOK_DISABLE_AFTER_FAILURES"`
+
+	// TaskQueues lists the task queues and their workers, "default=4,statements=1"
+	TaskQueues       string        `mapstructure:"TASK_QUEUES"`
+	TaskPollInterval time.Duration `mapstructure:"TASK_POLL_INTERVAL"`
+	TaskLease        time.Duration `mapstructure:"TASK_LEASE"`
+
+	// Components lists what the process runs, see ParseComponents
+	Components string `mapstructure:"COMPONENTS"`
+	// MetricsServerAddress serves /metrics, /healthz and /readyz of a process that runs the
+	// workers without the gateway or Gin, which serve them otherwise
+	MetricsServerAddress string `mapstructure:"METRICS_SERVER_ADDRESS"`
+	// ShutdownTimeout is how long in-flight requests get to finish after SIGINT or SIGTERM
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	// ShutdownDrainDelay is how long readiness reports not serving before the servers stop,
+	// set it to the readiness probe period behind a load balancer
+	ShutdownDrainDelay time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+
+	// TracingExporter is none, stdout, file or otlp
+	TracingExporter string `mapstructure:"TRACING_EXPORTER"`
+	TracingFile     string `mapstructure:"TRACING_FILE"`
+	// TracingOTLPEndpoint is the collector's host:port, empty means OTEL_EXPORTER_OTLP_ENDPOINT
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+
+	// LogLevel is debug, info, warn or error
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+	// LogFormat is text or json, empty means json in production and text elsewhere
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+
+	// CORSAllowedOrigins lists the origins browsers may call the HTTP APIs from, "*" allows any
+	// origin without credentials, empty disables CORS
+	CORSAllowedOrigins   string `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowCredentials bool   `mapstructure:"CORS_ALLOW_CREDENTIALS"`
+	// TrustedProxies lists the IPs and CIDRs whose X-Forwarded-For is believed, empty trusts none
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+
+	// TLSCertFile and TLSKeyFile turn on TLS for all servers, both empty means plaintext
+	TLSCertFile string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile  string `mapstructure:"TLS_KEY_FILE"`
+	// GRPCClientCAFile makes the gRPC server require client certificates signed by this CA
+	GRPCClientCAFile string `mapstructure:"GRPC_CLIENT_CA_FILE"`
+
+	// RateLimitBackend is none, memory or postgres, postgres shares the limits between instances
+	RateLimitBackend string `mapstructure:"RATE_LIMIT_BACKEND"`
+	// RateLimitRules lists the limits per route, see ParseRateLimitRules
+	RateLimitRules string `mapstructure:"RATE_LIMIT_RULES"`
 }
 
-func LoadConfig(configName ...string) (config Config, err error) {
-	fileName := "app"
-	if len(configName) > 0 && configName[0] != "" {
-		fileName = configName[0]
-	}
+// DirEnv names the directory with app.env when LoadConfig gets an empty dir
+const DirEnv = "CONFIG_DIR"
 
-	// Возможные пути к файлу конфигурации
-	possiblePaths := []string{
-		".",            // Текущая директория
-		"./cmd",        // Относительно корня проекта или Docker
-		"..",           // Родительская директория
-		"../..",        // Родительская директория уровнем выше
-		"../../cmd",    // cmd относительно корня проекта
-		"../../../cmd", // Для очень глубоких вложенных директорий
-		"../cmd",       // Для поддиректорий
-	}
+// secretFileSuffix marks a variable with the path of a file holding the value, KEY_FILE
+// sets KEY the way Docker and Kubernetes secrets are mounted and wins over KEY
+const secretFileSuffix = "_FILE"
 
-	// Добавляем пути в viper
-	for _, path := range possiblePaths {
-		viper.AddConfigPath(path)
+// LoadConfig reads app.env from dir, then app.<ENV>.env from the same dir when it exists,
+// then the environment and the *_FILE secrets. An empty dir means $CONFIG_DIR or the working
+// directory. The result is not validated, call Validate before using it
+func LoadConfig(dir string) (config Config, err error) {
+	if dir == "" {
+		dir = os.Getenv(DirEnv)
+	}
+	if dir == "" {
+		dir = "."
 	}
 
-	viper.SetConfigName(fileName) // Имя файла без расширения
-	viper.SetConfigType("env")    // Формат файла — .env
+	v := viper.New()
+	v.AddConfigPath(dir)
+	v.SetConfigName("app") // Имя файла без расширения
+	v.SetConfigType("env") // Формат файла — .env
+	v.AutomaticEnv()
 
-	// Поддержка переменных окружения (опционально)
-	viper.AutomaticEnv()
+	// без BindEnv viper не видит переменные окружения, которых нет в файле
+	keys := configKeys()
+	for _, key := range keys {
+		if err := v.BindEnv(key); err != nil {
+			return config, err
+		}
+		if err := v.BindEnv(key + secretFileSuffix); err != nil {
+			return config, err
+		}
+	}
 
-	// Чтение конфигурации
-	err = viper.ReadInConfig()
+	err = v.ReadInConfig()
 	if err != nil {
 		return config, fmt.Errorf("ошибка чтения файла конфигурации: %w", err)
 	}
 
+	// профиль берётся уже с учётом переменной окружения ENV
+	if profile := v.GetString("ENV"); profile != "" {
+		v.SetConfigName("app." + profile)
+		var notFound viper.ConfigFileNotFoundError
+		if err := v.MergeInConfig(); err != nil && !errors.As(err, &notFound) {
+			return config, fmt.Errorf("cannot read config profile %q: %w", profile, err)
+		}
+	}
+
+	for _, key := range keys {
+		path := v.GetString(key + secretFileSuffix)
+		if path == "" {
+			continue
+		}
+		value, err := readSecretFile(path)
+		if err != nil {
+			return config, fmt.Errorf("%s%s: %w", key, secretFileSuffix, err)
+		}
+		v.Set(key, value)
+	}
+
 	// Декодирование в структуру
-	err = viper.Unmarshal(&config)
+	err = v.Unmarshal(&config)
 	if err != nil {
 		return config, fmt.Errorf("не удалось декодировать конфигурацию: %w", err)
 	}
 
 	return config, nil
 }
+
+// readSecretFile reads a mounted secret, the trailing newline editors and echo add is dropped
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// configKeys lists the variables Config is read from, in field order
+func configKeys() []string {
+	fields := reflect.VisibleFields(reflect.TypeOf(Config{}))
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if key := field.Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
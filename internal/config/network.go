@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/hisshihi/simple-bank/internal/clientip"
+)
+
+// AnyOrigin in CORS_ALLOWED_ORIGINS allows every origin
+const AnyOrigin = "*"
+
+// CORSOrigins parses CORS_ALLOWED_ORIGINS
+func (config Config) CORSOrigins() []string {
+	return splitList(config.CORSAllowedOrigins)
+}
+
+// AllowsAnyOrigin says whether CORS_ALLOWED_ORIGINS contains AnyOrigin
+func (config Config) AllowsAnyOrigin() bool {
+	return slices.Contains(config.CORSOrigins(), AnyOrigin)
+}
+
+// TrustedProxyList parses TRUSTED_PROXIES
+func (config Config) TrustedProxyList() []string {
+	return splitList(config.TrustedProxies)
+}
+
+// ClientIPResolver resolves client IPs behind the TRUSTED_PROXIES
+func (config Config) ClientIPResolver() (*clientip.Resolver, error) {
+	return clientip.NewResolver(config.TrustedProxyList())
+}
+
+func validateOrigins(origins []string, allowCredentials bool) error {
+	for _, origin := range origins {
+		if origin == AnyOrigin {
+			if allowCredentials {
+				return fmt.Errorf("CORS origin %q cannot be used with credentials", AnyOrigin)
+			}
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return fmt.Errorf("invalid CORS origin %q, expected scheme://host[:port]", origin)
+		}
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
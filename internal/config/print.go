@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Redacted replaces secrets in the output of Print
+const Redacted = "REDACTED"
+
+// Print writes the configuration as KEY=value lines in the app.env format. The fields tagged
+// secret are masked unless showSecrets is set, a URL keeps everything but its password
+func (config Config) Print(w io.Writer, showSecrets bool) error {
+	value := reflect.ValueOf(config)
+	for _, field := range reflect.VisibleFields(value.Type()) {
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+
+		s := formatValue(value.FieldByIndex(field.Index).Interface())
+		if !showSecrets && field.Tag.Get("secret") == "true" {
+			s = redact(s)
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrintCommand runs "config print" with its flags: --redacted, the default, masks secrets and
+// --show-secrets prints them. Flag errors are reported to stderr and returned
+func (config Config) PrintCommand(w, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	// маскирование включено и так, флаг нужен, чтобы попросить его явно
+	redacted := flags.Bool("redacted", false, "mask secrets (default)")
+	showSecrets := flags.Bool("show-secrets", false, "print secrets instead of masking them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *redacted && *showSecrets {
+		return errors.New("--redacted and --show-secrets cannot be used together")
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	return config.Print(w, *showSecrets)
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// redact masks a secret, an empty one stays empty so a missing secret is still visible
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	if strings.Contains(s, "://") {
+		if u, err := url.Parse(s); err == nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), Redacted)
+			}
+			// токены в параметрах запроса тоже секретны
+			if u.RawQuery != "" {
+				query := u.Query()
+				for name := range query {
+					if isSecretParam(name) {
+						query.Set(name, Redacted)
+					}
+				}
+				u.RawQuery = query.Encode()
+			}
+			return u.String()
+		}
+	}
+	return Redacted
+}
+
+func isSecretParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"password", "secret", "token", "key"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
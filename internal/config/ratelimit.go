@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitRule lets Count requests through per Period on a route
+type RateLimitRule struct {
+	Count  int
+	Period time.Duration
+}
+
+// ParseRateLimitRules parses "<route>=<count>/<period>" rules separated by ";", such as
+// "POST /login=10/1m; /pb.SimpleBank/LoginUser=10/1m; *=300/1m"
+func ParseRateLimitRules(s string) (map[string]RateLimitRule, error) {
+	rules := make(map[string]RateLimitRule)
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		route, value, found := strings.Cut(part, "=")
+		route = strings.TrimSpace(route)
+		if !found || route == "" {
+			return nil, fmt.Errorf("invalid rate limit rule %q", part)
+		}
+
+		count, period, found := strings.Cut(strings.TrimSpace(value), "/")
+		n, err := strconv.Atoi(count)
+		if !found || err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid limit of route %q: %q", route, value)
+		}
+		d, err := time.ParseDuration(period)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid period of route %q: %q", route, value)
+		}
+
+		if _, ok := rules[route]; ok {
+			return nil, fmt.Errorf("route %q listed twice", route)
+		}
+		rules[route] = RateLimitRule{Count: n, Period: d}
+	}
+	return rules, nil
+}
+
+// RateLimitsByRoute parses RATE_LIMIT_RULES
+func (config Config) RateLimitsByRoute() (map[string]RateLimitRule, error) {
+	return ParseRateLimitRules(config.RateLimitRules)
+}
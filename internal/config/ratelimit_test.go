@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitRules(t *testing.T) {
+	rules, err := ParseRateLimitRules("POST /login=10/1m; /pb.SimpleBank/LoginUser = 5/30s ;*=300/1m;")
+	require.NoError(t, err)
+	require.Equal(t, map[string]RateLimitRule{
+		"POST /login":              {Count: 10, Period: time.Minute},
+		"/pb.SimpleBank/LoginUser": {Count: 5, Period: 30 * time.Second},
+		"*":                        {Count: 300, Period: time.Minute},
+	}, rules)
+
+	for _, invalid := range []string{"POST /login", "=1/1m", "*=0/1m", "*=x/1m", "*=10", "*=10/x", "*=10/-1s", "*=1/1m;*=2/1m"} {
+		_, err := ParseRateLimitRules(invalid)
+		require.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseTaskQueues parses the per-queue concurrency "default=4,statements=1",
+// a queue without "=n" gets one worker
+func ParseTaskQueues(s string) (map[string]int, error) {
+	queues := make(map[string]int)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid queue %q", part)
+		}
+
+		concurrency := 1
+		if found {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid concurrency of queue %q: %q", name, value)
+			}
+			concurrency = n
+		}
+
+		if _, ok := queues[name]; ok {
+			return nil, fmt.Errorf("queue %q listed twice", name)
+		}
+		queues[name] = concurrency
+	}
+	return queues, nil
+}
+
+// TaskQueueConcurrency parses TASK_QUEUES
+func (config Config) TaskQueueConcurrency() (map[string]int, error) {
+	return ParseTaskQueues(config.TaskQueues)
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskQueues(t *testing.T) {
+	queues, err := ParseTaskQueues("default=4, statements ,emails=2")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"default": 4, "statements": 1, "emails": 2}, queues)
+
+	for _, invalid := range []string{"default=0", "default=x", "=2", "a=1,a=2"} {
+		_, err := ParseTaskQueues(invalid)
+		require.Error(t, err, invalid)
+	}
+}
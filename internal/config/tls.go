@@ -0,0 +1,57 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSEnabled says whether the servers listen with TLS
+func (config Config) TLSEnabled() bool {
+	return config.TLSCertFile != "" || config.TLSKeyFile != ""
+}
+
+// ServerTLS loads the certificate of the HTTP servers, nil means plaintext
+func (config Config) ServerTLS() (*tls.Config, error) {
+	if !config.TLSEnabled() {
+		return nil, nil
+	}
+	if config.TLSCertFile == "" || config.TLSKeyFile == "" {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// GRPCServerTLS is ServerTLS that also verifies client certificates when GRPC_CLIENT_CA_FILE is set
+func (config Config) GRPCServerTLS() (*tls.Config, error) {
+	tlsConfig, err := config.ServerTLS()
+	if err != nil || config.GRPCClientCAFile == "" {
+		return tlsConfig, err
+	}
+	if tlsConfig == nil {
+		return nil, errors.New("GRPC_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	pem, err := os.ReadFile(config.GRPCClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read gRPC client CA: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in gRPC client CA %s", config.GRPCClientCAFile)
+	}
+
+	tlsConfig.ClientCAs = clientCAs
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
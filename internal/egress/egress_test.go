@@ -0,0 +1,88 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver map[string][]netip.Addr
+
+func (resolver fakeResolver) LookupNetIP(_ context.Context, _ string, host string) ([]netip.Addr, error) {
+	addrs, ok := resolver[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func TestIsPublic(t *testing.T) {
+	for _, addr := range []string{"93.184.215.14", "8.8.8.8", "2606:4700::1111"} {
+		require.True(t, IsPublic(netip.MustParseAddr(addr)), addr)
+	}
+
+	for _, addr := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "224.0.0.1", "255.255.255.255",
+		"::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "::ffff:10.0.0.1",
+	} {
+		require.False(t, IsPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	resolver := fakeResolver{
+		"example.com":   {netip.MustParseAddr("93.184.215.14")},
+		"localhost":     {netip.MustParseAddr("127.0.0.1")},
+		"split.example": {netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("10.0.0.1")},
+	}
+	ctx := context.Background()
+
+	require.NoError(t, CheckURL(ctx, resolver, "https://example.com/hooks"))
+	require.NoError(t, CheckURL(ctx, resolver, "http://93.184.215.14:8080/hooks"))
+
+	for _, raw := range []string{
+		"http://localhost/hooks",
+		"http://127.0.0.1/hooks",
+		"http://[::1]/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"https://split.example/hooks",
+	} {
+		require.ErrorIs(t, CheckURL(ctx, resolver, raw), ErrForbiddenAddress, raw)
+	}
+
+	require.Error(t, CheckURL(ctx, resolver, "ftp://example.com"))
+	require.Error(t, CheckURL(ctx, resolver, "https://unknown.example/hooks"))
+}
+
+func TestClientRefusesPrivateAddress(t *testing.T) {
+	called := false
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer endpoint.Close()
+
+	_, err := NewClient(time.Second).Get(endpoint.URL)
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+	require.False(t, called)
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	client := NewClient(time.Second)
+	// httptest слушает loopback, поэтому проверка адреса заменена на пропускающую
+	client.Transport = http.DefaultTransport
+
+	endpoint := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/", http.StatusFound))
+	defer endpoint.Close()
+
+	rsp, err := client.Get(endpoint.URL)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	require.Equal(t, http.StatusFound, rsp.StatusCode)
+}
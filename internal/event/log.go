@@ -0,0 +1,18 @@
+package event
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogPublisher only writes events to the log, it is the default when no broker is configured
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (publisher *LogPublisher) Publish(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "event published", "event_id", msg.ID, "type", msg.Type, "aggregate_type", msg.AggregateType, "aggregate_id", msg.AggregateID)
+	return nil
+}
@@ -0,0 +1,44 @@
+package event
+
+import (
+	"context"
+	"sync"
+)
+
+const memorySubscriberBuffer = 100
+
+// MemoryBroker is an in-process stand-in for a message broker, handy in development and tests
+type MemoryBroker struct {
+	mu          sync.RWMutex
+	subscribers []chan Message
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// Subscribe returns a channel receiving every event published after the call
+func (broker *MemoryBroker) Subscribe() <-chan Message {
+	ch := make(chan Message, memorySubscriberBuffer)
+
+	broker.mu.Lock()
+	broker.subscribers = append(broker.subscribers, ch)
+	broker.mu.Unlock()
+
+	return ch
+}
+
+// Publish waits until every subscriber has room for the event, so nothing is dropped
+func (broker *MemoryBroker) Publish(ctx context.Context, msg Message) error {
+	broker.mu.RLock()
+	defer broker.mu.RUnlock()
+
+	for _, ch := range broker.subscribers {
+		select {
+		case ch <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
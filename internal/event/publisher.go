@@ -0,0 +1,50 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hisshihi/simple-bank/internal/config"
+)
+
+const (
+	PublisherLog     = "log"
+	PublisherWebhook = "webhook"
+	PublisherMemory  = "memory"
+)
+
+// Message is a domain event taken from the outbox
+type Message struct {
+	// ID is the outbox id, consumers use it to drop duplicates
+	ID            int64
+	AggregateType string
+	AggregateID   string
+	// Type is the full protobuf message name of Payload
+	Type      string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// Publisher hands events over to consumers. Delivery is at least once:
+// an event is published again if the relay fails before marking it published.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// NewPublisher creates the publisher chosen by OUTBOX_PUBLISHER, the log publisher by default
+func NewPublisher(config config.Config) (Publisher, error) {
+	switch config.OutboxPublisher {
+	case "", PublisherLog:
+		return NewLogPublisher(), nil
+	case PublisherWebhook:
+		if config.OutboxWebhookURL == "" {
+			return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for the webhook publisher")
+		}
+		return NewWebhookPublisher(config.OutboxWebhookURL, nil), nil
+	case PublisherMemory:
+		return NewMemoryBroker(), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", config.OutboxPublisher)
+	}
+}
@@ -0,0 +1,101 @@
+package event
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWebhookPublisher(t *testing.T) {
+	payload, err := proto.Marshal(&pb.AccountCreated{AccountId: 7, Owner: "alice", Currency: "USD"})
+	require.NoError(t, err)
+
+	msg := Message{
+		ID:            42,
+		AggregateType: "account",
+		AggregateID:   "7",
+		Type:          "pb.AccountCreated",
+		Payload:       payload,
+		CreatedAt:     time.Now(),
+	}
+
+	var received pb.AccountCreated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "42", r.Header.Get("X-Event-ID"))
+		require.Equal(t, msg.Type, r.Header.Get("X-Event-Type"))
+		require.Equal(t, msg.AggregateID, r.Header.Get("X-Aggregate-ID"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	err = NewWebhookPublisher(server.URL, nil).Publish(context.Background(), msg)
+	require.NoError(t, err)
+	require.Equal(t, "alice", received.GetOwner())
+}
+
+func TestWebhookPublisherFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewWebhookPublisher(server.URL, nil).Publish(context.Background(), Message{ID: 1})
+	require.Error(t, err)
+}
+
+func TestMemoryBroker(t *testing.T) {
+	broker := NewMemoryBroker()
+	first := broker.Subscribe()
+	second := broker.Subscribe()
+
+	for id := int64(1); id <= 3; id++ {
+		require.NoError(t, broker.Publish(context.Background(), Message{ID: id}))
+	}
+
+	for _, ch := range []<-chan Message{first, second} {
+		for id := int64(1); id <= 3; id++ {
+			require.Equal(t, id, (<-ch).ID)
+		}
+	}
+}
+
+func TestMemoryBrokerRespectsContext(t *testing.T) {
+	broker := NewMemoryBroker()
+	broker.Subscribe()
+
+	for i := 0; i < memorySubscriberBuffer; i++ {
+		require.NoError(t, broker.Publish(context.Background(), Message{ID: int64(i)}))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, broker.Publish(ctx, Message{}), context.Canceled)
+}
+
+func TestNewPublisher(t *testing.T) {
+	publisher, err := NewPublisher(config.Config{})
+	require.NoError(t, err)
+	require.IsType(t, &LogPublisher{}, publisher)
+
+	_, err = NewPublisher(config.Config{OutboxPublisher: PublisherWebhook})
+	require.Error(t, err)
+
+	publisher, err = NewPublisher(config.Config{OutboxPublisher: PublisherWebhook, OutboxWebhookURL: "http://localhost"})
+	require.NoError(t, err)
+	require.IsType(t, &WebhookPublisher{}, publisher)
+
+	_, err = NewPublisher(config.Config{OutboxPublisher: "kafka"})
+	require.Error(t, err)
+}
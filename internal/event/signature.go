@@ -0,0 +1,63 @@
+package event
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader carries "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">"
+const WebhookSignatureHeader = "X-Webhook-Signature"
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrSignatureExpired = errors.New("webhook signature is too old")
+)
+
+// SignWebhook signs the body with the subscription secret. The timestamp is signed too,
+// so a captured request can't be replayed later.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, webhookMAC(secret, unix, body))
+}
+
+// VerifyWebhookSignature checks a signature header made by SignWebhook no longer than tolerance ago
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var unix, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+
+	expected := webhookMAC(secret, unix, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	if now.Sub(time.Unix(seconds, 0)) > tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func webhookMAC(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
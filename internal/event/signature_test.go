@@ -0,0 +1,22 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"id":"1","type":"webhook.test"}`)
+	signedAt := time.Now()
+	header := SignWebhook(secret, signedAt, body)
+
+	require.NoError(t, VerifyWebhookSignature(secret, header, body, time.Minute, signedAt.Add(time.Second)))
+
+	require.ErrorIs(t, VerifyWebhookSignature("whsec_other", header, body, time.Minute, signedAt), ErrInvalidSignature)
+	require.ErrorIs(t, VerifyWebhookSignature(secret, header, []byte(`{"id":"2"}`), time.Minute, signedAt), ErrInvalidSignature)
+	require.ErrorIs(t, VerifyWebhookSignature(secret, "v1=deadbeef", body, time.Minute, signedAt), ErrInvalidSignature)
+	require.ErrorIs(t, VerifyWebhookSignature(secret, header, body, time.Minute, signedAt.Add(time.Hour)), ErrSignatureExpired)
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookPublisher posts the protobuf encoded event to a URL.
+// The event metadata travels in headers, so the body can be decoded with the pb types as is.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPublisher creates a publisher posting to url, a client with a 10s timeout is used when client is nil
+func NewWebhookPublisher(url string, client *http.Client) *WebhookPublisher {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookPublisher{
+		url:    url,
+		client: client,
+	}
+}
+
+func (publisher *WebhookPublisher) Publish(ctx context.Context, msg Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.url, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(msg.ID, 10))
+	req.Header.Set("X-Event-Type", msg.Type)
+	req.Header.Set("X-Aggregate-Type", msg.AggregateType)
+	req.Header.Set("X-Aggregate-ID", msg.AggregateID)
+	req.Header.Set("X-Event-Created-At", msg.CreatedAt.UTC().Format(time.RFC3339Nano))
+
+	rsp, err := publisher.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", rsp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DatabaseCheck pings the database
+func DatabaseCheck(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// MigrationCheck verifies that the schema is migrated to at least the version the binary was built with
+func MigrationCheck(db *sql.DB, required uint) Check {
+	return func(ctx context.Context) error {
+		var version uint
+		var dirty bool
+		err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("no migration applied, need version %d", required)
+			}
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("migration %d failed halfway and left the schema dirty", version)
+		}
+		if version < required {
+			return fmt.Errorf("schema is at version %d, need %d", version, required)
+		}
+		return nil
+	}
+}
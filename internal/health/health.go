@@ -0,0 +1,220 @@
+// Package health keeps the liveness and readiness of the process. Readiness combines
+// the registered checks, such as database connectivity, with the health components report
+// about themselves, and is published over HTTP and the grpc.health.v1 protocol.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	checkTimeout = 2 * time.Second
+	// grpcUpdateInterval is how often the gRPC serving status is brought up to date
+	grpcUpdateInterval = 5 * time.Second
+)
+
+// ErrShuttingDown is reported by readiness once shutdown has begun
+var ErrShuttingDown = errors.New("shutting down")
+
+// Check returns an error when the dependency it checks is not usable
+type Check func(ctx context.Context) error
+
+type Checker struct {
+	mu         sync.RWMutex
+	checks     map[string]Check
+	components map[string]error
+	// grpcServices get the readiness as their gRPC serving status
+	grpcServices []string
+	grpc         *health.Server
+	shutdown     atomic.Bool
+}
+
+// NewChecker publishes readiness over gRPC for the overall server and the given services
+func NewChecker(grpcServices ...string) *Checker {
+	return &Checker{
+		checks:       make(map[string]Check),
+		components:   make(map[string]error),
+		grpcServices: append([]string{""}, grpcServices...),
+		grpc:         health.NewServer(),
+	}
+}
+
+// AddCheck makes readiness depend on check
+func (checker *Checker) AddCheck(name string, check Check) {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+
+	checker.checks[name] = check
+}
+
+// Component returns the reporter for a component, the component counts as healthy
+// until it reports otherwise
+func (checker *Checker) Component(name string) *Component {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+
+	checker.components[name] = nil
+	return &Component{name: name, checker: checker}
+}
+
+func (checker *Checker) report(name string, err error) {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+
+	checker.components[name] = err
+}
+
+// Shutdown flips readiness to not serving, so load balancers stop sending traffic
+// while the servers drain
+func (checker *Checker) Shutdown() {
+	checker.shutdown.Store(true)
+	checker.grpc.Shutdown()
+}
+
+// Ready runs the checks and returns the result of every check and component, nil meaning healthy
+func (checker *Checker) Ready(ctx context.Context) (map[string]error, bool) {
+	checker.mu.RLock()
+	checks := maps.Clone(checker.checks)
+	results := maps.Clone(checker.components)
+	checker.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := check(ctx)
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if checker.shutdown.Load() {
+		results["shutdown"] = ErrShuttingDown
+	}
+
+	ready := true
+	for _, err := range results {
+		ready = ready && err == nil
+	}
+	return results, ready
+}
+
+// GRPC returns the grpc.health.v1 server to register on the gRPC server
+func (checker *Checker) GRPC() healthpb.HealthServer {
+	return checker.grpc
+}
+
+// Run keeps the gRPC serving status in line with readiness until ctx is cancelled
+func (checker *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(grpcUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		// после Shutdown статус gRPC больше не меняется
+		if !checker.shutdown.Load() {
+			status := healthpb.HealthCheckResponse_NOT_SERVING
+			if _, ready := checker.Ready(ctx); ready {
+				status = healthpb.HealthCheckResponse_SERVING
+			}
+			for _, service := range checker.grpcServices {
+				checker.grpc.SetServingStatus(service, status)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// LiveHandler answers /healthz: the process is up and able to serve HTTP.
+// It doesn't look at dependencies, a restart would not bring the database back.
+func (checker *Checker) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, response{Status: "ok"})
+	})
+}
+
+// ReadyHandler answers /readyz with 200 when the process can take traffic and 503 otherwise.
+// The endpoint is unauthenticated, so the body only says which checks failed and the errors,
+// which name hosts and queries, go to the log
+func (checker *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results, ready := checker.Ready(r.Context())
+
+		rsp := response{Status: "ok", Checks: make(map[string]string, len(results))}
+		for _, name := range slices.Sorted(maps.Keys(results)) {
+			rsp.Checks[name] = "ok"
+			if err := results[name]; err != nil {
+				rsp.Checks[name] = "unavailable"
+				// остановка ожидаема, её не пишем в журнал на каждой пробе
+				if !errors.Is(err, ErrShuttingDown) {
+					slog.WarnContext(r.Context(), "readiness check failed", "check", name, "error", err)
+				}
+			}
+		}
+
+		status := http.StatusOK
+		if !ready {
+			rsp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, rsp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Fprintln(w, err)
+	}
+}
+
+// Component lets a component such as the task pool report its own health.
+// A nil Component ignores reports, so components run fine without health reporting.
+type Component struct {
+	name    string
+	checker *Checker
+}
+
+func (component *Component) Healthy() {
+	if component != nil {
+		component.checker.report(component.name, nil)
+	}
+}
+
+func (component *Component) Unhealthy(err error) {
+	if component != nil {
+		component.checker.report(component.name, err)
+	}
+}
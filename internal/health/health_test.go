@@ -0,0 +1,82 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func readyz(t *testing.T, checker *Checker) (int, response) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	checker.ReadyHandler().ServeHTTP(recorder, request)
+
+	var rsp response
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	return recorder.Code, rsp
+}
+
+func grpcStatus(t *testing.T, checker *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	rsp, err := checker.GRPC().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return rsp.GetStatus()
+}
+
+func TestReadiness(t *testing.T) {
+	checker := NewChecker("pb.SimpleBank")
+
+	var dbErr error
+	checker.AddCheck("database", func(ctx context.Context) error { return dbErr })
+	pool := checker.Component("task_pool")
+
+	code, rsp := readyz(t, checker)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, map[string]string{"database": "ok", "task_pool": "ok"}, rsp.Checks)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	checker.Run(ctx)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, grpcStatus(t, checker, "pb.SimpleBank"))
+
+	pool.Unhealthy(errors.New("cannot claim tasks"))
+	code, rsp = readyz(t, checker)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "unavailable", rsp.Checks["task_pool"])
+
+	pool.Healthy()
+	dbErr = errors.New("dial tcp 10.0.0.7:5432: connection refused")
+	code, rsp = readyz(t, checker)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	// подробности только в журнале, проба открыта без авторизации
+	require.Equal(t, map[string]string{"database": "unavailable", "task_pool": "ok"}, rsp.Checks)
+
+	checker.Run(ctx)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, checker, ""))
+}
+
+func TestShutdownFlipsReadiness(t *testing.T) {
+	checker := NewChecker()
+	checker.Shutdown()
+
+	code, rsp := readyz(t, checker)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "unavailable", rsp.Checks["shutdown"])
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, checker, ""))
+
+	// liveness не зависит от остановки
+	recorder := httptest.NewRecorder()
+	checker.LiveHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestNilComponent(t *testing.T) {
+	var component *Component
+	component.Healthy()
+	component.Unhealthy(errors.New("ignored"))
+}
@@ -0,0 +1,41 @@
+// Package httpstatus records the status code a handler writes, for the middlewares that
+// wrap the gateway mux and need it after the handler returns
+package httpstatus
+
+import "net/http"
+
+// Recorder remembers the status code written to the response
+type Recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// NewRecorder wraps w, a handler that writes no header responds with 200
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status returns the first status code written
+func (recorder *Recorder) Status() int {
+	return recorder.status
+}
+
+func (recorder *Recorder) WriteHeader(code int) {
+	if !recorder.wroteHeader {
+		recorder.status = code
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(code)
+}
+
+// Flush keeps server-sent events streaming through the recorder
+func (recorder *Recorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (recorder *Recorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
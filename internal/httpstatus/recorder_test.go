@@ -0,0 +1,24 @@
+package httpstatus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	recorder := NewRecorder(w)
+	require.Equal(t, http.StatusOK, recorder.Status())
+
+	recorder.WriteHeader(http.StatusNotFound)
+	// повторный WriteHeader игнорируется net/http, статус остаётся первым
+	recorder.WriteHeader(http.StatusInternalServerError)
+	require.Equal(t, http.StatusNotFound, recorder.Status())
+
+	recorder.Flush()
+	require.True(t, w.Flushed)
+	require.Same(t, w, recorder.Unwrap())
+}
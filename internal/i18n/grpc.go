@@ -0,0 +1,44 @@
+package i18n
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// acceptLanguageMetadata is how Accept-Language travels in gRPC metadata
+const acceptLanguageMetadata = "accept-language"
+
+// UnaryServerInterceptor negotiates the call's locale from the accept-language metadata
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withIncomingLocale(ctx), req)
+	}
+}
+
+// StreamServerInterceptor does for streams what UnaryServerInterceptor does for unary calls
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &localeStream{ServerStream: stream, ctx: withIncomingLocale(stream.Context())})
+	}
+}
+
+func withIncomingLocale(ctx context.Context) context.Context {
+	var acceptLanguage string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(acceptLanguageMetadata); len(values) > 0 {
+			acceptLanguage = values[0]
+		}
+	}
+	return WithLocale(ctx, Negotiate(acceptLanguage))
+}
+
+type localeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *localeStream) Context() context.Context {
+	return stream.ctx
+}
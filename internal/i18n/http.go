@@ -0,0 +1,30 @@
+package i18n
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+const AcceptLanguageHeader = "Accept-Language"
+
+// GinMiddleware negotiates the request's locale from Accept-Language
+func GinMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locale := Negotiate(ctx.GetHeader(AcceptLanguageHeader))
+		ctx.Request = ctx.Request.WithContext(WithLocale(ctx.Request.Context(), locale))
+		ctx.Next()
+	}
+}
+
+// GatewayMiddleware does for the gateway mux what GinMiddleware does for Gin,
+// the locale reaches the in-process gRPC handler with the request's context
+func GatewayMiddleware() runtime.Middleware {
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			locale := Negotiate(r.Header.Get(AcceptLanguageHeader))
+			next(w, r.WithContext(WithLocale(r.Context(), locale)), pathParams)
+		}
+	}
+}
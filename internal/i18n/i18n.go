@@ -0,0 +1,94 @@
+// Package i18n translates the messages the API sends to clients. Every message has a stable
+// key made of the apperr code it is sent with and a message id, "not_found.account", the code
+// alone keys the code's title. locales/<locale>.json holds the texts of a locale, English
+// included, so rewording a message never loses its translations. A key missing from a
+// catalog is sent in English.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	English = "en"
+	Russian = "ru"
+
+	DefaultLocale = English
+)
+
+// supported is in the matcher's order, the first tag is the fallback
+var supported = []language.Tag{language.English, language.Russian}
+
+var matcher = language.NewMatcher(supported)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]map[string]string {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	catalogs := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("invalid catalog %s: %v", file.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(file.Name(), ".json")] = catalog
+	}
+	return catalogs
+}
+
+// Negotiate picks the supported locale that fits an Accept-Language value best
+func Negotiate(acceptLanguage string) string {
+	// при ошибке разбора tags пустой и выбирается язык по умолчанию
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := matcher.Match(tags...)
+	return supported[index].String()
+}
+
+// Translate returns the message of key in locale, args fill in its verbs like in fmt.Sprintf.
+// A key no catalog has is returned as it is
+func Translate(locale, key string, args ...any) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		message, ok = catalogs[English][key]
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+type localeKey struct{}
+
+// WithLocale stores the locale negotiated for the request
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// Locale returns the locale of the request, English when none was negotiated
+func Locale(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
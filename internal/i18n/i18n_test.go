@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	testCases := []struct {
+		acceptLanguage string
+		locale         string
+	}{
+		{"", English},
+		{"ru", Russian},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8", Russian},
+		{"en-US,en;q=0.9,ru;q=0.8", English},
+		{"de-DE,ru;q=0.5", Russian},
+		{"de", English},
+		{"not a language;;", English},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.locale, Negotiate(tc.acceptLanguage), tc.acceptLanguage)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	require.Equal(t, "счёт 7 не найден", Translate(Russian, "not_found.account_id", 7))
+	require.Equal(t, "account 7 not found", Translate(English, "not_found.account_id", 7))
+	require.Equal(t, "Не найдено", Translate(Russian, "not_found"))
+	require.Equal(t, "no such message", Translate(Russian, "no such message"))
+	require.Equal(t, "100% done", Translate(Russian, "100% done"))
+}
+
+// TestCatalogs checks that every locale translates every English key with the same verbs.
+// apperr's TestCatalogs checks that the keys its codes and messages use are in the catalogs
+func TestCatalogs(t *testing.T) {
+	verbs := func(s string) string {
+		var found []string
+		for i := 0; i < len(s)-1; i++ {
+			if s[i] == '%' {
+				found = append(found, s[i:i+2])
+				i++
+			}
+		}
+		return strings.Join(found, " ")
+	}
+
+	english := catalogs[English]
+	require.NotEmpty(t, english)
+	for _, tag := range supported {
+		catalog := catalogs[tag.String()]
+		for key, message := range english {
+			translation, ok := catalog[key]
+			require.True(t, ok, "%s: no %q", tag, key)
+			require.NotEmpty(t, translation, "%s: %q", tag, key)
+			require.Equal(t, verbs(message), verbs(translation), "%s: %q", tag, key)
+		}
+		require.Len(t, catalog, len(english), "%s: keys missing in English", tag)
+	}
+}
+
+func TestLocale(t *testing.T) {
+	require.Equal(t, DefaultLocale, Locale(context.Background()))
+	require.Equal(t, Russian, Locale(WithLocale(context.Background(), Russian)))
+}
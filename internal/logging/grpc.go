@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadata is how X-Request-ID travels in gRPC metadata
+const requestIDMetadata = "x-request-id"
+
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// UnaryServerInterceptor starts the call's logging context from the x-request-id metadata,
+// returns the id in the response header and logs the method, status, duration and user
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx = startCall(ctx)
+
+		resp, err := handler(ctx, req)
+		logCall(ctx, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor does for streams what UnaryServerInterceptor does for unary calls,
+// the call is logged when the stream ends
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := startCall(stream.Context())
+
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		logCall(ctx, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+func startCall(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadata); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+
+	ctx = WithRequest(ctx, requestID)
+	// заголовок уходит с первым ответом или с трейлерами при ошибке
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, RequestID(ctx)))
+	return ctx
+}
+
+func logCall(ctx context.Context, method string, err error, elapsed time.Duration) {
+	code := status.Code(err)
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+		// пробы балансировщика идут каждые несколько секунд
+		if strings.HasPrefix(method, healthMethodPrefix) {
+			level = slog.LevelDebug
+		}
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("status", code.String()),
+		slog.Duration("duration", elapsed),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	slog.Default().LogAttrs(ctx, level, "grpc call", attrs...)
+}
+
+// contextStream replaces the stream's context with the one carrying the logging context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *contextStream) Context() context.Context {
+	return stream.ctx
+}
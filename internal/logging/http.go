@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hisshihi/simple-bank/internal/httpstatus"
+)
+
+// GinMiddleware starts the request's logging context from X-Request-ID, echoes the id
+// and logs the request once it is handled. It replaces gin.Logger
+func GinMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		reqCtx := WithRequest(ctx.Request.Context(), ctx.GetHeader(RequestIDHeader))
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Header(RequestIDHeader, RequestID(reqCtx))
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = ctx.Request.URL.Path
+		}
+		logRequest(ctx.Request, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
+
+// GatewayMiddleware does for the gateway mux what GinMiddleware does for Gin
+func GatewayMiddleware() runtime.Middleware {
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			start := time.Now()
+
+			r = r.WithContext(WithRequest(r.Context(), r.Header.Get(RequestIDHeader)))
+			w.Header().Set(RequestIDHeader, RequestID(r.Context()))
+
+			recorder := httpstatus.NewRecorder(w)
+			next(recorder, r, pathParams)
+
+			logRequest(r, r.URL.Path, recorder.Status(), time.Since(start))
+		}
+	}
+}
+
+func logRequest(r *http.Request, route string, status int, elapsed time.Duration) {
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+
+	slog.Default().LogAttrs(r.Context(), level, "http request",
+		slog.String("method", r.Method),
+		slog.String("route", route),
+		slog.Int("status", status),
+		slog.Duration("duration", elapsed),
+	)
+}
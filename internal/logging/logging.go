@@ -0,0 +1,133 @@
+// Package logging provides the shared slog logger. Every line logged with a request's context
+// carries the request id, the authenticated user and the trace id, and passwords, tokens and
+// emails are redacted before a line is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header a caller sets to correlate its request, it is echoed in the response
+const RequestIDHeader = "X-Request-ID"
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New returns the logger for the configured level and format, JSON by default in production
+func New(config config.Config, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if config.LogLevel != "" {
+		if err := level.UnmarshalText([]byte(config.LogLevel)); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+		}
+	}
+
+	format := config.LogFormat
+	if format == "" {
+		format = FormatText
+		if config.ENV == "production" {
+			format = FormatJSON
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: Redact}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// contextHandler adds what is known about the request in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (handler contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if info, ok := ctx.Value(requestKey{}).(*requestInfo); ok {
+		record.AddAttrs(slog.String("request_id", info.id))
+		if user := info.User(); user != "" {
+			record.AddAttrs(slog.String("user", user))
+		}
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return handler.Handler.Handle(ctx, record)
+}
+
+func (handler contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{handler.Handler.WithAttrs(attrs)}
+}
+
+func (handler contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{handler.Handler.WithGroup(name)}
+}
+
+type requestKey struct{}
+
+// requestInfo is shared by the middleware that starts the request and the handlers
+// that learn who the user is
+type requestInfo struct {
+	id string
+
+	mu   sync.Mutex
+	user string
+}
+
+func (info *requestInfo) User() string {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	return info.user
+}
+
+// WithRequest starts the logging context of a request, a new id is generated when requestID is empty
+func WithRequest(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	return context.WithValue(ctx, requestKey{}, &requestInfo{id: requestID})
+}
+
+// RequestID returns the id of the request ctx belongs to, or an empty string outside a request
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(requestKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// SetUser records the authenticated user, so the rest of the request's lines carry it
+func SetUser(ctx context.Context, username string) {
+	if info, ok := ctx.Value(requestKey{}).(*requestInfo); ok {
+		info.mu.Lock()
+		info.user = username
+		info.mu.Unlock()
+	}
+}
+
+// User returns the user recorded with SetUser
+func User(ctx context.Context) string {
+	if info, ok := ctx.Value(requestKey{}).(*requestInfo); ok {
+		return info.User()
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// captureLogs makes the default logger write JSON lines to the returned buffer for the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	logger, err := New(config.Config{LogFormat: FormatJSON, LogLevel: "debug"}, &buf)
+	require.NoError(t, err)
+
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(config.Config{LogLevel: "loud"}, &bytes.Buffer{})
+	require.Error(t, err)
+
+	_, err = New(config.Config{LogFormat: "xml"}, &bytes.Buffer{})
+	require.EqualError(t, err, `unknown log format "xml"`)
+}
+
+func TestRedact(t *testing.T) {
+	buf := captureLogs(t)
+
+	slog.Info("user john.doe@example.com logged in",
+		"password", "secret123",
+		"refresh_token", "v2.local.abc",
+		"email", "john.doe@example.com",
+		"error", errors.New("invalid token Bearer v2.local.Zm9v.YmFy"),
+		slog.Group("user", "hashed_password", "$2a$10$abc"),
+	)
+
+	records := lines(t, buf)
+	require.Len(t, records, 1)
+	record := records[0]
+
+	require.Equal(t, "user j***@example.com logged in", record["msg"])
+	require.Equal(t, redacted, record["password"])
+	require.Equal(t, redacted, record["refresh_token"])
+	require.Equal(t, "j***@example.com", record["email"])
+	require.Equal(t, "invalid token Bearer "+redacted, record["error"])
+	require.Equal(t, map[string]any{"hashed_password": redacted}, record["user"])
+	require.NotContains(t, buf.String(), "secret123")
+}
+
+func TestScrub(t *testing.T) {
+	require.Equal(t, "token "+redacted+" expired", Scrub("token v2.local.Zm9vYmFy.YmF6 expired"))
+	require.Equal(t, "a***@b.io", Scrub("a@b.io"))
+	require.Equal(t, "nothing to hide", Scrub("nothing to hide"))
+}
+
+func TestContextAttributes(t *testing.T) {
+	buf := captureLogs(t)
+
+	ctx := WithRequest(context.Background(), "req-1")
+	slog.InfoContext(ctx, "before login")
+	SetUser(ctx, "alice")
+	slog.InfoContext(ctx, "after login")
+	slog.Info("no request")
+
+	records := lines(t, buf)
+	require.Len(t, records, 3)
+	require.Equal(t, "req-1", records[0]["request_id"])
+	require.NotContains(t, records[0], "user")
+	require.Equal(t, "alice", records[1]["user"])
+	require.NotContains(t, records[2], "request_id")
+
+	require.NotEmpty(t, RequestID(WithRequest(context.Background(), "")))
+}
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := captureLogs(t)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(GinMiddleware())
+	router.GET("/accounts/:id", func(ctx *gin.Context) {
+		SetUser(ctx, "alice")
+		ctx.Status(http.StatusNotFound)
+	})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	request.Header.Set(RequestIDHeader, "req-42")
+	router.ServeHTTP(recorder, request)
+
+	require.Equal(t, "req-42", recorder.Header().Get(RequestIDHeader))
+
+	records := lines(t, buf)
+	require.Len(t, records, 1)
+	require.Equal(t, "WARN", records[0]["level"])
+	require.Equal(t, "/accounts/:id", records[0]["route"])
+	require.Equal(t, float64(http.StatusNotFound), records[0]["status"])
+	require.Equal(t, "req-42", records[0]["request_id"])
+	require.Equal(t, "alice", records[0]["user"])
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	buf := captureLogs(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDMetadata, "req-7"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.SimpleBank/LoginUser"}
+
+	_, err := UnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		require.Equal(t, "req-7", RequestID(ctx))
+		SetUser(ctx, "alice")
+		return nil, status.Error(codes.NotFound, "user not found")
+	})
+	require.Error(t, err)
+
+	records := lines(t, buf)
+	require.Len(t, records, 1)
+	require.Equal(t, "grpc call", records[0]["msg"])
+	require.Equal(t, info.FullMethod, records[0]["method"])
+	require.Equal(t, "NotFound", records[0]["status"])
+	require.Equal(t, "alice", records[0]["user"])
+	require.Equal(t, "req-7", records[0]["request_id"])
+	require.Contains(t, records[0], "duration")
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeyParts mark attributes whose value is dropped whole, "refresh_token" and "hashed_password" included
+var sensitiveKeyParts = []string{"password", "token", "secret", "authorization", "cookie", "api_key"}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9._~+/\-]+=*`)
+	// v2.local. и v4.public. — токены PASETO, которые выдаёт сервис
+	pasetoPattern = regexp.MustCompile(`v[1-4]\.(local|public)\.[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)?`)
+)
+
+// Redact is the slog ReplaceAttr that keeps secrets and personal data out of the logs:
+// sensitive attributes are dropped, emails are masked and tokens are cut out of messages and errors
+func Redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+
+	switch value := attr.Value.Any().(type) {
+	case string:
+		return slog.String(attr.Key, Scrub(value))
+	case error:
+		return slog.String(attr.Key, Scrub(value.Error()))
+	}
+	return attr
+}
+
+// Scrub masks the emails and removes the tokens found in s
+func Scrub(s string) string {
+	s = emailPattern.ReplaceAllStringFunc(s, MaskEmail)
+	s = bearerPattern.ReplaceAllString(s, "$1 "+redacted)
+	return pasetoPattern.ReplaceAllString(s, redacted)
+}
+
+// MaskEmail keeps the first letter and the domain, "john@example.com" becomes "j***@example.com"
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return redacted
+	}
+	return local[:1] + "***@" + domain
+}
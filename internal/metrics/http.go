@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hisshihi/simple-bank/internal/httpstatus"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners cannot blow up the label cardinality
+const unmatchedRoute = "unmatched"
+
+// GinMiddleware records the Gin requests by route template, it goes before gin.Recovery
+// so a panic is recorded as the 500 the recovery responds with
+func (m *Metrics) GinMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.observeHTTP("gin", ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
+
+// GatewayMiddleware records the requests the gateway mux routed to a handler.
+// The gateway calls the gRPC server in process, so its requests never reach the gRPC interceptors
+func (m *Metrics) GatewayMiddleware() runtime.Middleware {
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			start := time.Now()
+			recorder := httpstatus.NewRecorder(w)
+			next(recorder, r, pathParams)
+
+			// маршруты шлюза без параметров пути, поэтому путь запроса и есть маршрут
+			m.observeHTTP("gateway", r.Method, r.URL.Path, recorder.Status(), time.Since(start))
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package metrics defines the Prometheus metrics of the service: the rate, errors and duration
+// of HTTP and gRPC requests and the transfer KPIs. Metrics are registered on the registerer
+// passed to New, so tests can use a fresh registry and assert on what was recorded.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "simple_bank"
+
+type Metrics struct {
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	grpcRequests *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+
+	transfers        *prometheus.CounterVec
+	transferVolume   *prometheus.CounterVec
+	transferFailures *prometheus.CounterVec
+	txRetries        *prometheus.CounterVec
+}
+
+// New creates the metrics and registers them on reg, it panics when they are already registered
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by server, method, route and status code.",
+		}, []string{"server", "method", "route", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by server, method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"server", "method", "route"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "gRPC calls by full method name and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "gRPC call latency by full method name, for streams the lifetime of the stream.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		transfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfers_total",
+			Help:      "Transfers made by currency, scheduled transfers, hold captures and reversal refunds included.",
+		}, []string{"currency"}),
+		transferVolume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfer_volume_total",
+			Help:      "Amount transferred in minor units of the currency, fees excluded.",
+		}, []string{"currency"}),
+		transferFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfer_failures_total",
+			Help:      "Transfers that failed by reason.",
+		}, []string{"reason"}),
+		txRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tx_retries_total",
+			Help:      "Transactions run again after a serialization failure or deadlock, by operation.",
+		}, []string{"op"}),
+	}
+
+	reg.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.grpcRequests,
+		m.grpcDuration,
+		m.transfers,
+		m.transferVolume,
+		m.transferFailures,
+		m.txRetries,
+	)
+	return m
+}
+
+// NewRegistry returns a registry with the Go runtime, process and connection pool collectors
+func NewRegistry(db *sql.DB) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, namespace),
+	)
+	return registry
+}
+
+// Handler serves the metrics gathered from gatherer in the Prometheus exposition format
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
+
+// TxRetried counts a transaction of op run again, it is meant for sqlc.WithRetryObserver
+func (m *Metrics) TxRetried(op string) {
+	m.txRetries.WithLabelValues(op).Inc()
+}
+
+// ReconciliationObserver registers the discrepancy gauge on reg and returns the observer for
+// worker.WithDiscrepancyObserver. Only a process that runs the reconciler registers it, so a
+// process without one does not export a zero that reads as a clean ledger
+func ReconciliationObserver(reg prometheus.Registerer) func(discrepancies int64) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_discrepancies",
+		Help:      "Ledger discrepancies found by the last completed reconciliation run.",
+	})
+	reg.MustRegister(gauge)
+
+	return func(discrepancies int64) {
+		gauge.Set(float64(discrepancies))
+	}
+}
+
+func (m *Metrics) observeHTTP(server, method, route string, code int, elapsed time.Duration) {
+	m.httpRequests.WithLabelValues(server, method, route, strconv.Itoa(code)).Inc()
+	m.httpDuration.WithLabelValues(server, method, route).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New(prometheus.NewRegistry())
+
+	router := gin.New()
+	router.Use(m.GinMiddleware())
+	router.Use(gin.Recovery())
+	router.GET("/accounts/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNotFound)
+	})
+	router.GET("/panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/accounts/1", "/accounts/2", "/panic", "/wp-login.php"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	require.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("gin", http.MethodGet, "/accounts/:id", "404")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("gin", http.MethodGet, "/panic", "500")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("gin", http.MethodGet, unmatchedRoute, "404")))
+	require.Equal(t, 3, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestGatewayMiddleware(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+
+	handler := m.GatewayMiddleware()(func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, ok := w.(http.Flusher)
+		require.True(t, ok)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/login_user", nil), nil)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("gateway", http.MethodPost, "/v1/login_user", "401")))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.SimpleBank/LoginUser"}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	})
+	require.Error(t, err)
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.grpcRequests.WithLabelValues(info.FullMethod, "NotFound")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.grpcRequests.WithLabelValues(info.FullMethod, "OK")))
+}
+
+func TestInstrumentStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mockdb.NewMockStore(ctrl)
+
+	m := New(prometheus.NewRegistry())
+	store := m.InstrumentStore(mockStore)
+
+	transferred := func(amount int64) sqlc.TransferTxResult {
+		return sqlc.TransferTxResult{
+			Transfer:    sqlc.Transfer{ID: 1, Amount: amount},
+			FromAccount: sqlc.Account{Currency: "USD"},
+		}
+	}
+
+	arg := sqlc.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 250}
+	gomock.InOrder(
+		mockStore.EXPECT().TransferTx(gomock.Any(), arg).Return(transferred(250), nil),
+		mockStore.EXPECT().TransferTx(gomock.Any(), arg).
+			Return(sqlc.TransferTxResult{}, sqlc.ErrInsufficientFunds),
+	)
+	gomock.InOrder(
+		mockStore.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+			Return(sqlc.ExecuteScheduledTransferTxResult{Transfer: transferred(100)}, nil),
+		// неудачная попытка коммитится вместе с записью о ней
+		mockStore.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+			Return(sqlc.ExecuteScheduledTransferTxResult{TransferErr: sqlc.ErrInsufficientFunds}, nil),
+		mockStore.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+			Return(sqlc.ExecuteScheduledTransferTxResult{}, sqlc.ErrScheduledTransferNotDue),
+	)
+	mockStore.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).
+		Return(sqlc.CaptureHoldTxResult{Transfer: transferred(30)}, nil)
+	mockStore.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).
+		Return(sqlc.ReverseTransferTxResult{Reversal: transferred(20)}, nil)
+	mockStore.EXPECT().ApproveReversalTx(gomock.Any(), gomock.Any()).
+		Return(sqlc.ReverseTransferTxResult{}, sqlc.ErrReversalNotPending)
+
+	_, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, sqlc.ErrInsufficientFunds)
+
+	for range 3 {
+		store.ExecuteScheduledTransferTx(context.Background(), sqlc.ExecuteScheduledTransferTxParams{})
+	}
+	_, err = store.CaptureHoldTx(context.Background(), sqlc.CaptureHoldTxParams{})
+	require.NoError(t, err)
+	_, err = store.ReverseTransferTx(context.Background(), sqlc.ReverseTransferTxParams{})
+	require.NoError(t, err)
+	_, err = store.ApproveReversalTx(context.Background(), sqlc.ApproveReversalTxParams{})
+	require.ErrorIs(t, err, sqlc.ErrReversalNotPending)
+
+	require.Equal(t, 4.0, testutil.ToFloat64(m.transfers.WithLabelValues("USD")))
+	require.Equal(t, 400.0, testutil.ToFloat64(m.transferVolume.WithLabelValues("USD")))
+	require.Equal(t, 2.0, testutil.ToFloat64(m.transferFailures.WithLabelValues(reasonInsufficientFunds)))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.transferFailures.WithLabelValues(reasonRejected)))
+
+	// отказ обработчика до TransferTx считается под той же причиной
+	m.TransferRejected(sql.ErrNoRows)
+	require.Equal(t, 1.0, testutil.ToFloat64(m.transferFailures.WithLabelValues(reasonAccountNotFound)))
+}
+
+func TestTransferFailureReason(t *testing.T) {
+	testCases := []struct {
+		err    error
+		reason string
+	}{
+		{sqlc.ErrInsufficientFunds, reasonInsufficientFunds},
+		{sql.ErrNoRows, reasonAccountNotFound},
+		{fmt.Errorf("capture: %w", sqlc.ErrHoldExpired), reasonRejected},
+		{fmt.Errorf("tx: %w", &pq.Error{Code: "40P01"}), reasonConflict},
+		{context.DeadlineExceeded, reasonCanceled},
+		{fmt.Errorf("no fee revenue account for currency USD"), reasonInternal},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.reason, transferFailureReason(tc.err), tc.err.Error())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+	m.TxRetried("transfer")
+
+	recorder := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `simple_bank_tx_retries_total{op="transfer"} 1`)
+	// без сверки в процессе датчика нет вовсе
+	require.NotContains(t, recorder.Body.String(), "simple_bank_reconciliation_discrepancies")
+
+	ReconciliationObserver(registry)(3)
+
+	recorder = httptest.NewRecorder()
+	Handler(registry).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Contains(t, recorder.Body.String(), "simple_bank_reconciliation_discrepancies 3")
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+)
+
+// Reasons a transfer failed, kept few so the label stays bounded
+const (
+	reasonInsufficientFunds = "insufficient_funds"
+	reasonAccountNotFound   = "account_not_found"
+	// reasonConflict is a serialization failure or deadlock that outlived the retries
+	reasonConflict = "conflict"
+	reasonCanceled = "canceled"
+	// reasonRejected is a hold or reversal in a state that doesn't allow the transfer
+	reasonRejected = "rejected"
+	reasonInternal = "internal"
+)
+
+// InstrumentStore counts the money movements made through the returned store: transfers,
+// scheduled transfers, hold captures and reversal refunds. They are counted once their
+// transaction has committed, a transaction retried after a conflict counts once
+func (m *Metrics) InstrumentStore(store sqlc.Store) sqlc.Store {
+	return &instrumentedStore{Store: store, metrics: m}
+}
+
+type instrumentedStore struct {
+	sqlc.Store
+	metrics *Metrics
+}
+
+func (store *instrumentedStore) TransferTx(ctx context.Context, arg sqlc.TransferTxParams) (sqlc.TransferTxResult, error) {
+	result, err := store.Store.TransferTx(ctx, arg)
+	store.observeTransfer(result, err)
+	return result, err
+}
+
+func (store *instrumentedStore) ExecuteScheduledTransferTx(ctx context.Context, arg sqlc.ExecuteScheduledTransferTxParams) (sqlc.ExecuteScheduledTransferTxResult, error) {
+	result, err := store.Store.ExecuteScheduledTransferTx(ctx, arg)
+	switch {
+	case errors.Is(err, sqlc.ErrScheduledTransferNotDue):
+		// другой воркер уже выполнил повторение, перевода не было
+	case err != nil:
+		store.observeTransfer(result.Transfer, err)
+	case result.TransferErr != nil:
+		// неудачная попытка записана в расписание, сама транзакция закоммичена
+		store.observeTransfer(result.Transfer, result.TransferErr)
+	case result.Transfer.Transfer.ID != 0:
+		store.observeTransfer(result.Transfer, nil)
+	}
+	return result, err
+}
+
+func (store *instrumentedStore) CaptureHoldTx(ctx context.Context, arg sqlc.CaptureHoldTxParams) (sqlc.CaptureHoldTxResult, error) {
+	result, err := store.Store.CaptureHoldTx(ctx, arg)
+	store.observeTransfer(result.Transfer, err)
+	return result, err
+}
+
+func (store *instrumentedStore) ReverseTransferTx(ctx context.Context, arg sqlc.ReverseTransferTxParams) (sqlc.ReverseTransferTxResult, error) {
+	result, err := store.Store.ReverseTransferTx(ctx, arg)
+	store.observeTransfer(result.Reversal, err)
+	return result, err
+}
+
+func (store *instrumentedStore) ApproveReversalTx(ctx context.Context, arg sqlc.ApproveReversalTxParams) (sqlc.ReverseTransferTxResult, error) {
+	result, err := store.Store.ApproveReversalTx(ctx, arg)
+	store.observeTransfer(result.Reversal, err)
+	return result, err
+}
+
+// observeTransfer counts a committed transfer by its currency and amount, or the reason it failed
+func (store *instrumentedStore) observeTransfer(result sqlc.TransferTxResult, err error) {
+	if err != nil {
+		store.metrics.TransferRejected(err)
+		return
+	}
+
+	currency := result.FromAccount.Currency
+	store.metrics.transfers.WithLabelValues(currency).Inc()
+	store.metrics.transferVolume.WithLabelValues(currency).Add(float64(result.Transfer.Amount))
+}
+
+// TransferRejected counts a transfer refused before it reached the store, such as one from an
+// account the handler could not find, under the reason the store would have reported
+func (m *Metrics) TransferRejected(err error) {
+	m.transferFailures.WithLabelValues(transferFailureReason(err)).Inc()
+}
+
+func transferFailureReason(err error) string {
+	switch {
+	case errors.Is(err, sqlc.ErrInsufficientFunds):
+		return reasonInsufficientFunds
+	case errors.Is(err, sql.ErrNoRows):
+		return reasonAccountNotFound
+	case errors.Is(err, sqlc.ErrHoldNotAuthorized), errors.Is(err, sqlc.ErrHoldExpired),
+		errors.Is(err, sqlc.ErrCaptureExceedsHold), errors.Is(err, sqlc.ErrReversalOfReversal),
+		errors.Is(err, sqlc.ErrRefundExceedsTransfer), errors.Is(err, sqlc.ErrReversalNotPending):
+		return reasonRejected
+	case sqlc.IsRetryable(err):
+		return reasonConflict
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return reasonCanceled
+	default:
+		return reasonInternal
+	}
+}
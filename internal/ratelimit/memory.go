@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets are dropped
+const sweepInterval = time.Minute
+
+// MemoryBackend keeps the buckets of a single instance
+type MemoryBackend struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{tats: make(map[string]time.Time), now: time.Now}
+}
+
+func (b *MemoryBackend) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep(now)
+
+	tat := b.tats[key]
+	if tat.Before(now) {
+		tat = now
+	}
+	if wait := tat.Sub(now) - limit.tolerance(); wait > 0 {
+		return Result{RetryAfter: wait}, nil
+	}
+	b.tats[key] = tat.Add(limit.interval())
+	return Result{Allowed: true}, nil
+}
+
+// sweep drops the buckets whose TAT has passed, they are full and equal to a missing one
+func (b *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < sweepInterval {
+		return
+	}
+	b.lastSweep = now
+	for key, tat := range b.tats {
+		if tat.Before(now) {
+			delete(b.tats, key)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+)
+
+// PostgresBackend keeps the buckets in the rate_limits table, so all instances share them
+type PostgresBackend struct {
+	store     sqlc.Querier
+	lastSweep atomic.Int64
+}
+
+func NewPostgresBackend(store sqlc.Querier) *PostgresBackend {
+	return &PostgresBackend{store: store}
+}
+
+func (b *PostgresBackend) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	b.sweep(ctx)
+
+	_, err := b.store.TakeRateLimit(ctx, sqlc.TakeRateLimitParams{
+		Key:              key,
+		IntervalSeconds:  limit.interval().Seconds(),
+		ToleranceSeconds: limit.tolerance().Seconds(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// запрос не обновил строку, значит корзина пуста; точное время ожидания не больше
+		// одного интервала, его и отдаём, чтобы не делать второй запрос
+		return Result{RetryAfter: limit.interval()}, nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Allowed: true}, nil
+}
+
+// sweep deletes the full buckets at most once per sweepInterval on this instance
+func (b *PostgresBackend) sweep(ctx context.Context) {
+	now := time.Now().UnixNano()
+	last := b.lastSweep.Load()
+	if now-last < int64(sweepInterval) || !b.lastSweep.CompareAndSwap(last, now) {
+		return
+	}
+	if err := b.store.DeleteExpiredRateLimits(ctx); err != nil {
+		slog.WarnContext(ctx, "rate limit sweep failed", "error", err)
+	}
+}
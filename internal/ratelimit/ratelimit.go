@@ -0,0 +1,108 @@
+// Package ratelimit limits how often a caller may hit a route. Every route and caller pair
+// gets a token bucket, kept as GCRA: instead of a token count the bucket stores the
+// theoretical arrival time (TAT) of the next request, which fits in one row for the
+// Postgres backend and is updated with a single statement.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+)
+
+const (
+	BackendNone     = "none"
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// DefaultRoute is the rule of the routes that have none of their own
+const DefaultRoute = "*"
+
+// Limit lets Count requests through per Period, all of them may come in one burst
+type Limit struct {
+	Count  int
+	Period time.Duration
+}
+
+// interval is how often the bucket gets a token back
+func (limit Limit) interval() time.Duration {
+	return limit.Period / time.Duration(limit.Count)
+}
+
+// tolerance is how far the TAT may run ahead of now, the burst beyond the first request
+func (limit Limit) tolerance() time.Duration {
+	return limit.interval() * time.Duration(limit.Count-1)
+}
+
+// Result is the outcome of taking a token, RetryAfter is set when the request is denied
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Backend stores the buckets
+type Backend interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Limiter applies the per-route rules. A nil Limiter allows everything
+type Limiter struct {
+	backend Backend
+	rules   map[string]Limit
+}
+
+func New(backend Backend, rules map[string]Limit) *Limiter {
+	return &Limiter{backend: backend, rules: rules}
+}
+
+// Setup builds the limiter of the configured backend, nil when rate limiting is off
+func Setup(config config.Config, store sqlc.Querier) (*Limiter, error) {
+	parsed, err := config.RateLimitsByRoute()
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]Limit, len(parsed))
+	for route, rule := range parsed {
+		rules[route] = Limit{Count: rule.Count, Period: rule.Period}
+	}
+
+	switch config.RateLimitBackend {
+	case "", BackendNone:
+		return nil, nil
+	case BackendMemory:
+		return New(NewMemoryBackend(), rules), nil
+	case BackendPostgres:
+		return New(NewPostgresBackend(store), rules), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", config.RateLimitBackend)
+	}
+}
+
+// Allow takes a token from the bucket of identity on route. A route without a rule falls
+// back to DefaultRoute, and to no limit at all when that is missing too
+func (l *Limiter) Allow(ctx context.Context, route, identity string) Result {
+	if l == nil {
+		return Result{Allowed: true}
+	}
+
+	limit, ok := l.rules[route]
+	if !ok {
+		limit, ok = l.rules[DefaultRoute]
+	}
+	if !ok {
+		return Result{Allowed: true}
+	}
+
+	result, err := l.backend.Take(ctx, route+"|"+identity, limit)
+	if err != nil {
+		// лимит защищает от злоупотреблений, а не от сбоев, поэтому при ошибке хранилища пропускаем запрос
+		slog.WarnContext(ctx, "rate limiter failed, request let through", "route", route, "error", err)
+		return Result{Allowed: true}
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	backend := NewMemoryBackend()
+	backend.now = func() time.Time { return now }
+
+	limit := Limit{Count: 3, Period: 3 * time.Second}
+	take := func(key string) Result {
+		result, err := backend.Take(context.Background(), key, limit)
+		require.NoError(t, err)
+		return result
+	}
+
+	// полная корзина пропускает всю очередь сразу
+	for i := 0; i < 3; i++ {
+		require.True(t, take("a").Allowed, i)
+	}
+	result := take("a")
+	require.False(t, result.Allowed)
+	require.Equal(t, time.Second, result.RetryAfter)
+
+	// у другого ключа своя корзина
+	require.True(t, take("b").Allowed)
+
+	// за интервал возвращается ровно один токен
+	now = now.Add(time.Second)
+	require.True(t, take("a").Allowed)
+	require.False(t, take("a").Allowed)
+
+	// пустые корзины удаляются при очистке
+	now = now.Add(2 * sweepInterval)
+	require.True(t, take("c").Allowed)
+	require.Len(t, backend.tats, 1)
+}
+
+type failingBackend struct{}
+
+func (failingBackend) Take(context.Context, string, Limit) (Result, error) {
+	return Result{}, errors.New("connection refused")
+}
+
+func TestLimiter(t *testing.T) {
+	backend := NewMemoryBackend()
+	limiter := New(backend, map[string]Limit{
+		"POST /login": {Count: 1, Period: time.Minute},
+		DefaultRoute:  {Count: 2, Period: time.Minute},
+	})
+	ctx := context.Background()
+
+	require.True(t, limiter.Allow(ctx, "POST /login", "ip:1").Allowed)
+	require.False(t, limiter.Allow(ctx, "POST /login", "ip:1").Allowed)
+	require.True(t, limiter.Allow(ctx, "POST /login", "ip:2").Allowed)
+
+	// маршрут без своего правила получает правило по умолчанию и отдельную корзину
+	require.True(t, limiter.Allow(ctx, "GET /accounts", "ip:1").Allowed)
+	require.True(t, limiter.Allow(ctx, "GET /accounts", "ip:1").Allowed)
+	require.False(t, limiter.Allow(ctx, "GET /accounts", "ip:1").Allowed)
+
+	var off *Limiter
+	require.True(t, off.Allow(ctx, "POST /login", "ip:1").Allowed)
+
+	unlimited := New(backend, map[string]Limit{})
+	require.True(t, unlimited.Allow(ctx, "POST /login", "ip:1").Allowed)
+
+	failing := New(failingBackend{}, map[string]Limit{DefaultRoute: {Count: 1, Period: time.Minute}})
+	require.True(t, failing.Allow(ctx, "POST /login", "ip:1").Allowed)
+}
@@ -3,34 +3,62 @@ package api
 import (
 	"database/sql"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
 	"github.com/hisshihi/simple-bank/pkg/util"
 	"github.com/lib/pq"
 )
 
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Product  string `json:"product" binding:"omitempty,oneof=checking savings term_deposit"`
+	// MaturesAt is required for term deposits and not allowed for other products
+	MaturesAt *time.Time `json:"matures_at"`
 }
 
 type accountResponse struct {
-	ID       int64  `json:"id"`
-	Owner    string `json:"owner"`
-	Currency string `json:"currency"`
-	Balance  int64  `json:"balance"`
+	ID               int64      `json:"id"`
+	Owner            string     `json:"owner"`
+	Currency         string     `json:"currency"`
+	Balance          int64      `json:"balance"`
+	AvailableBalance int64      `json:"available_balance"`
+	OverdraftLimit   int64      `json:"overdraft_limit"`
+	Product          string     `json:"product"`
+	Tier             string     `json:"tier"`
+	MaturesAt        *time.Time `json:"matures_at,omitempty"`
+}
+
+func newAccountResponse(account sqlc.Account) accountResponse {
+	rsp := accountResponse{
+		ID:               account.ID,
+		Owner:            account.Owner,
+		Currency:         account.Currency,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance,
+		OverdraftLimit:   account.OverdraftLimit,
+		Product:          account.Product,
+		Tier:             account.Tier,
+	}
+	if account.MaturesAt.Valid {
+		rsp.MaturesAt = &account.MaturesAt.Time
+	}
+	return rsp
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
 	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondError(ctx, apperr.Unauthenticated("required"))
 		return
 	}
 
@@ -40,25 +68,37 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		Currency: req.Currency,
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	if req.Product != "" {
+		arg.Product = sql.NullString{String: req.Product, Valid: true}
+	}
+	if req.Product == sqlc.ProductTermDeposit {
+		if req.MaturesAt == nil || !req.MaturesAt.After(time.Now()) {
+			respondError(ctx, apperr.Invalid("maturity_required"))
+			return
+		}
+		arg.MaturesAt = sql.NullTime{Time: *req.MaturesAt, Valid: true}
+	} else if req.MaturesAt != nil {
+		respondError(ctx, apperr.Invalid("maturity_not_allowed"))
+		return
+	}
+
+	account, err := server.store.CreateAccountTx(server.auditContext(ctx, authPayload.Username, "account.create"), arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
+			case "foreign_key_violation":
+				respondError(ctx, apperr.NotFound("owner"))
+				return
+			case "unique_violation":
+				respondError(ctx, apperr.AlreadyExists("account_currency"))
 				return
 			}
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
-	rsp := accountResponse{
-		ID:       account.ID,
-		Owner:    account.Owner,
-		Currency: account.Currency,
-		Balance:  account.Balance,
-	}
+	rsp := newAccountResponse(account)
 
 	ctx.JSON(http.StatusOK, rsp)
 }
@@ -70,34 +110,29 @@ type getAccountRequest struct {
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
 	account, err := server.store.GetAccount(ctx, req.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			respondError(ctx, apperr.NotFound("account"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
-	rsp := accountResponse{
-		ID:       account.ID,
-		Owner:    account.Owner,
-		Currency: account.Currency,
-		Balance:  account.Balance,
-	}
+	rsp := newAccountResponse(account)
 
 	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondError(ctx, apperr.Unauthenticated("required"))
 		return
 	}
 	if account.Owner != authPayload.Username {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "account doesn't belong to the authenticated user"})
+		respondError(ctx, apperr.Forbidden("account"))
 		return
 	}
 
@@ -112,13 +147,13 @@ type listAccountRequest struct {
 func (server *Server) listAccount(ctx *gin.Context) {
 	var req listAccountRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
 	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondError(ctx, apperr.Unauthenticated("required"))
 		return
 	}
 
@@ -130,7 +165,7 @@ func (server *Server) listAccount(ctx *gin.Context) {
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
@@ -145,23 +180,23 @@ func (server *Server) updateAccount(ctx *gin.Context) {
 	var req updateAccountRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
 	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondError(ctx, apperr.Unauthenticated("required"))
 		return
 	}
 
 	account, err := server.store.GetAccountByOwner(ctx, authPayload.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			respondError(ctx, apperr.NotFound("account"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
@@ -170,21 +205,22 @@ func (server *Server) updateAccount(ctx *gin.Context) {
 		Balance: req.Balance,
 	}
 
-	updateAccount, err := server.store.UpdateAccount(ctx, arg)
+	var updateAccount sqlc.Account
+	err = server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "account.update_balance"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		updateAccount, err = q.UpdateAccount(ctx, arg)
+		return accountAuditChange(account, updateAccount), err
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			respondError(ctx, apperr.NotFound("account"))
+			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
-	rsp := accountResponse{
-		ID:       updateAccount.ID,
-		Owner:    updateAccount.Owner,
-		Currency: updateAccount.Currency,
-		Balance:  updateAccount.Balance,
-	}
+	rsp := newAccountResponse(updateAccount)
 
 	ctx.JSON(http.StatusOK, rsp)
 }
@@ -192,31 +228,140 @@ func (server *Server) updateAccount(ctx *gin.Context) {
 func (server *Server) deleteAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
 	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondError(ctx, apperr.Unauthenticated("required"))
 		return
 	}
 
 	account, err := server.store.GetAccountByOwner(ctx, authPayload.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			respondError(ctx, apperr.NotFound("account"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
-	err = server.store.DeleteAccount(ctx, account.ID)
+	err = server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "account.delete"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		return accountAuditChange(account, sqlc.Account{}), q.DeleteAccount(ctx, account.ID)
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+type updateOverdraftLimitRequest struct {
+	OverdraftLimit *int64 `json:"overdraft_limit" binding:"required,min=0"`
+}
+
+// updateOverdraftLimit lets an admin change how far below zero an account may go
+func (server *Server) updateOverdraftLimit(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req updateOverdraftLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	var account sqlc.Account
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "account.update_overdraft_limit"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		before, err := q.GetAccount(ctx, uri.ID)
+		if err != nil {
+			return sqlc.AuditChange{}, err
+		}
+
+		account, err = q.UpdateAccountOverdraftLimit(ctx, sqlc.UpdateAccountOverdraftLimitParams{
+			ID:             uri.ID,
+			OverdraftLimit: *req.OverdraftLimit,
+		})
+		return accountAuditChange(before, account), err
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("account"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	rsp := newAccountResponse(account)
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type updateAccountTierRequest struct {
+	Tier string `json:"tier" binding:"required,alphanum,max=32"`
+}
+
+// updateAccountTier moves an account to another fee schedule tier, a tier without its own
+// schedule pays the standard fees
+func (server *Server) updateAccountTier(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req updateAccountTierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	var account sqlc.Account
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "account.update_tier"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		before, err := q.GetAccount(ctx, uri.ID)
+		if err != nil {
+			return sqlc.AuditChange{}, err
+		}
+
+		account, err = q.UpdateAccountTier(ctx, sqlc.UpdateAccountTierParams{
+			ID:   uri.ID,
+			Tier: req.Tier,
+		})
+		return accountAuditChange(before, account), err
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("account"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+// accountAuditChange describes a change of the account, an empty before or after means it was created or deleted
+func accountAuditChange(before sqlc.Account, after sqlc.Account) sqlc.AuditChange {
+	change := sqlc.AuditChange{TargetType: sqlc.AuditTargetAccount}
+	if before.ID != 0 {
+		change.TargetID = strconv.FormatInt(before.ID, 10)
+		change.Before = before
+	}
+	if after.ID != 0 {
+		change.TargetID = strconv.FormatInt(after.ID, 10)
+		change.After = after
+	}
+	return change
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+func (server *Server) listAccountProducts(ctx *gin.Context) {
+	products, err := server.store.ListAccountProducts(ctx)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, products)
+}
+
+type accountProductURI struct {
+	Code string `uri:"code" binding:"required,oneof=checking savings term_deposit"`
+}
+
+type updateAccountProductRateRequest struct {
+	AnnualRateBps *int64 `json:"annual_rate_bps" binding:"required,min=0,max=10000"`
+}
+
+// updateAccountProductRate changes the interest rate of a product, it applies from the next accrual
+func (server *Server) updateAccountProductRate(ctx *gin.Context) {
+	var uri accountProductURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req updateAccountProductRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	var product sqlc.AccountProduct
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "account_product.update_rate"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		before, err := q.GetAccountProduct(ctx, uri.Code)
+		if err != nil {
+			return sqlc.AuditChange{}, err
+		}
+
+		product, err = q.UpdateAccountProductRate(ctx, sqlc.UpdateAccountProductRateParams{
+			Code:          uri.Code,
+			AnnualRateBps: *req.AnnualRateBps,
+		})
+		return sqlc.AuditChange{
+			TargetType: sqlc.AuditTargetAccountProduct,
+			TargetID:   uri.Code,
+			Before:     before,
+			After:      product,
+		}, err
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("product"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, product)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateAccountProductRateAPI(t *testing.T) {
+	product := sqlc.AccountProduct{
+		Code:          sqlc.ProductSavings,
+		Kind:          sqlc.ProductSavings,
+		AnnualRateBps: 450,
+	}
+
+	testCases := []struct {
+		name          string
+		code          string
+		body          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			code: sqlc.ProductSavings,
+			body: `{"annual_rate_bps": 450}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := sqlc.UpdateAccountProductRateParams{
+					Code:          sqlc.ProductSavings,
+					AnnualRateBps: 450,
+				}
+				store.EXPECT().
+					GetAccountProduct(gomock.Any(), gomock.Eq(sqlc.ProductSavings)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					UpdateAccountProductRate(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(product, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownProduct",
+			code: "gold",
+			body: `{"annual_rate_bps": 450}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountProductRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeRate",
+			code: sqlc.ProductSavings,
+			body: `{"annual_rate_bps": -1}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountProductRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			code: sqlc.ProductTermDeposit,
+			body: `{"annual_rate_bps": 0}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountProduct(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.AccountProduct{}, sql.ErrNoRows)
+				store.EXPECT().UpdateAccountProductRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "DepositorForbidden",
+			code: sqlc.ProductSavings,
+			body: `{"annual_rate_bps": 450}`,
+			role: util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountProductRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPut, "/account-products/"+tc.code, bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
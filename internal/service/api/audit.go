@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/clientip"
+	"github.com/hisshihi/simple-bank/internal/logging"
+)
+
+// auditContext returns the context to pass to the store, so the change made by actor is
+// written to the audit log in the same transaction
+func (server *Server) auditContext(ctx *gin.Context, actor, action string) context.Context {
+	return sqlc.WithAudit(ctx, sqlc.AuditRecord{
+		Actor:     actor,
+		Action:    action,
+		RequestID: logging.RequestID(ctx),
+		ClientIP:  clientip.FromContext(ctx),
+		UserAgent: ctx.Request.UserAgent(),
+	})
+}
+
+type listAuditEventsRequest struct {
+	Actor      string `form:"actor"`
+	Action     string `form:"action"`
+	TargetType string `form:"target_type"`
+	TargetID   string `form:"target_id"`
+	// BeforeID is the last id of the previous page, the newest events are returned when it is empty
+	BeforeID int64 `form:"before_id" binding:"omitempty,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+func (server *Server) listAuditEvents(ctx *gin.Context) {
+	var req listAuditEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	arg := sqlc.ListAuditEventsParams{
+		Actor:      nullString(req.Actor),
+		Action:     nullString(req.Action),
+		TargetType: nullString(req.TargetType),
+		TargetID:   nullString(req.TargetID),
+		BeforeID:   req.BeforeID,
+		RowLimit:   int64(req.PageSize),
+	}
+	if arg.BeforeID == 0 {
+		arg.BeforeID = math.MaxInt64
+	}
+
+	events, err := server.store.ListAuditEvents(ctx, arg)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+// verifyAuditChain recomputes the hash chain to detect edited or deleted events
+func (server *Server) verifyAuditChain(ctx *gin.Context) {
+	status, err := sqlc.VerifyAuditChain(ctx, server.store)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// nullString treats an empty query parameter as no filter
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
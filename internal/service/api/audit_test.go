@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListAuditEventsAPI(t *testing.T) {
+	events := []sqlc.AuditEvent{{ID: 7, Actor: "admin", Action: "account.update_overdraft_limit"}}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "page_size=5",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := sqlc.ListAuditEventsParams{
+					BeforeID: math.MaxInt64,
+					RowLimit: 5,
+				}
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(arg)).Times(1).Return(events, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get(requestIDHeaderKey))
+				requireBodyMatchAuditEvents(t, recorder.Body, events)
+			},
+		},
+		{
+			name:  "Filters",
+			query: "page_size=5&actor=admin&target_type=account&target_id=1&before_id=8",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := sqlc.ListAuditEventsParams{
+					Actor:      nullString("admin"),
+					TargetType: nullString(sqlc.AuditTargetAccount),
+					TargetID:   nullString("1"),
+					BeforeID:   8,
+					RowLimit:   5,
+				}
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(arg)).Times(1).Return(events, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPageSize",
+			query: "page_size=1000",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "BankerForbidden",
+			query: "page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/audit-events?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyAuditChainAPI(t *testing.T) {
+	first := sqlc.AuditEvent{ID: 1, Actor: "admin", Action: "account.delete", CreatedAt: time.Now().UTC()}
+	first.Hash = sqlc.AuditEventHash(first)
+	second := sqlc.AuditEvent{ID: 2, Actor: "admin", Action: "account.create", PrevHash: first.Hash, CreatedAt: time.Now().UTC()}
+	second.Hash = sqlc.AuditEventHash(second)
+
+	tampered := second
+	tampered.Actor = "intruder"
+
+	testCases := []struct {
+		name          string
+		events        []sqlc.AuditEvent
+		checkResponse func(t *testing.T, status sqlc.AuditChainStatus)
+	}{
+		{
+			name:   "Valid",
+			events: []sqlc.AuditEvent{first, second},
+			checkResponse: func(t *testing.T, status sqlc.AuditChainStatus) {
+				require.True(t, status.Valid)
+				require.Equal(t, int64(2), status.Checked)
+			},
+		},
+		{
+			name:   "Tampered",
+			events: []sqlc.AuditEvent{first, tampered},
+			checkResponse: func(t *testing.T, status sqlc.AuditChainStatus) {
+				require.False(t, status.Valid)
+				require.Equal(t, second.ID, status.BrokenAt)
+			},
+		},
+		{
+			name:   "Deleted",
+			events: []sqlc.AuditEvent{second},
+			checkResponse: func(t *testing.T, status sqlc.AuditChainStatus) {
+				require.False(t, status.Valid)
+				require.Equal(t, second.ID, status.BrokenAt)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListAuditEventsAfter(gomock.Any(), gomock.Any()).Times(1).Return(tc.events, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/audit-events/verify", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var status sqlc.AuditChainStatus
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+			tc.checkResponse(t, status)
+		})
+	}
+}
+
+func TestRequestIDMiddlewareKeepsCallerID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(1).Return([]sqlc.AuditEvent{}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/audit-events?page_size=5", nil)
+	require.NoError(t, err)
+	request.Header.Set(requestIDHeaderKey, "req-42")
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, "req-42", recorder.Header().Get(requestIDHeaderKey))
+}
+
+func requireBodyMatchAuditEvents(t *testing.T, body *bytes.Buffer, events []sqlc.AuditEvent) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotEvents []sqlc.AuditEvent
+	err = json.Unmarshal(data, &gotEvents)
+	require.NoError(t, err)
+	require.Len(t, gotEvents, len(events))
+	require.Equal(t, events[0].ID, gotEvents[0].ID)
+	require.Equal(t, events[0].Action, gotEvents[0].Action)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+func (server *Server) listFeeSchedules(ctx *gin.Context) {
+	schedules, err := server.store.ListFeeSchedules(ctx)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+type feeScheduleURI struct {
+	Currency string `uri:"currency" binding:"required,currency"`
+	Tier     string `uri:"tier" binding:"required,alphanum,max=32"`
+}
+
+type upsertFeeScheduleRequest struct {
+	Kind          string `json:"kind" binding:"required,oneof=flat percentage"`
+	FlatAmount    int64  `json:"flat_amount" binding:"min=0"`
+	PercentageBps int64  `json:"percentage_bps" binding:"min=0,max=10000"`
+	MinFee        int64  `json:"min_fee" binding:"min=0"`
+	// MaxFee 0 means no upper bound
+	MaxFee int64 `json:"max_fee" binding:"min=0"`
+}
+
+// upsertFeeSchedule creates or replaces the fee schedule of a currency and account tier,
+// it applies to the next transfer
+func (server *Server) upsertFeeSchedule(ctx *gin.Context) {
+	var uri feeScheduleURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req upsertFeeScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+	if req.MaxFee > 0 && req.MaxFee < req.MinFee {
+		respondError(ctx, apperr.Invalid("fee_bounds"))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	var schedule sqlc.FeeSchedule
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "fee_schedule.upsert"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		change := sqlc.AuditChange{
+			TargetType: sqlc.AuditTargetFeeSchedule,
+			TargetID:   uri.Currency + "/" + uri.Tier,
+		}
+
+		// новой схемы ещё нет, тогда before остаётся пустым
+		before, err := q.GetFeeScheduleByTier(ctx, sqlc.GetFeeScheduleByTierParams{
+			Currency: uri.Currency,
+			Tier:     uri.Tier,
+		})
+		if err == nil {
+			change.Before = before
+		} else if err != sql.ErrNoRows {
+			return change, err
+		}
+
+		schedule, err = q.UpsertFeeSchedule(ctx, sqlc.UpsertFeeScheduleParams{
+			Currency:      uri.Currency,
+			Tier:          uri.Tier,
+			Kind:          req.Kind,
+			FlatAmount:    req.FlatAmount,
+			PercentageBps: req.PercentageBps,
+			MinFee:        req.MinFee,
+			MaxFee:        req.MaxFee,
+		})
+		change.After = schedule
+		return change, err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListFeeSchedulesAPI(t *testing.T) {
+	schedules := []sqlc.FeeSchedule{
+		{ID: 1, Currency: util.USD, Tier: sqlc.DefaultAccountTier, Kind: sqlc.FeeKindFlat, FlatAmount: 30},
+		{ID: 2, Currency: util.USD, Tier: "premium", Kind: sqlc.FeeKindPercentage, PercentageBps: 50, MaxFee: 500},
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFeeSchedules(gomock.Any()).Times(1).Return(schedules, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []sqlc.FeeSchedule
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, schedules, got)
+			},
+		},
+		{
+			name: "InternalError",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFeeSchedules(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "BankerForbidden",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFeeSchedules(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/fee-schedules", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpsertFeeScheduleAPI(t *testing.T) {
+	schedule := sqlc.FeeSchedule{
+		ID:            1,
+		Currency:      util.USD,
+		Tier:          "premium",
+		Kind:          sqlc.FeeKindPercentage,
+		PercentageBps: 50,
+		MinFee:        10,
+		MaxFee:        500,
+	}
+	key := sqlc.GetFeeScheduleByTierParams{Currency: util.USD, Tier: "premium"}
+
+	testCases := []struct {
+		name          string
+		path          string
+		body          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Create",
+			path: "/fee-schedules/USD/premium",
+			body: `{"kind": "percentage", "percentage_bps": 50, "min_fee": 10, "max_fee": 500}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := sqlc.UpsertFeeScheduleParams{
+					Currency:      util.USD,
+					Tier:          "premium",
+					Kind:          sqlc.FeeKindPercentage,
+					PercentageBps: 50,
+					MinFee:        10,
+					MaxFee:        500,
+				}
+				store.EXPECT().
+					GetFeeScheduleByTier(gomock.Any(), gomock.Eq(key)).
+					Times(1).
+					Return(sqlc.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpsertFeeSchedule(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(schedule, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got sqlc.FeeSchedule
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, schedule, got)
+			},
+		},
+		{
+			name: "Replace",
+			path: "/fee-schedules/USD/premium",
+			body: `{"kind": "flat", "flat_amount": 25}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFeeScheduleByTier(gomock.Any(), gomock.Eq(key)).
+					Times(1).
+					Return(schedule, nil)
+				store.EXPECT().
+					UpsertFeeSchedule(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sqlc.FeeSchedule{ID: 1, Currency: util.USD, Tier: "premium", Kind: sqlc.FeeKindFlat, FlatAmount: 25}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedCurrency",
+			path: "/fee-schedules/GBP/premium",
+			body: `{"kind": "flat", "flat_amount": 25}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownKind",
+			path: "/fee-schedules/USD/premium",
+			body: `{"kind": "tiered"}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PercentageAboveHundred",
+			path: "/fee-schedules/USD/premium",
+			body: `{"kind": "percentage", "percentage_bps": 10001}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MaxFeeBelowMinFee",
+			path: "/fee-schedules/USD/premium",
+			body: `{"kind": "percentage", "percentage_bps": 50, "min_fee": 100, "max_fee": 10}`,
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BankerForbidden",
+			path: "/fee-schedules/USD/premium",
+			body: `{"kind": "flat", "flat_amount": 25}`,
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPut, tc.path, bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
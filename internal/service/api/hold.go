@@ -0,0 +1,237 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+type holdResponse struct {
+	ID             int64     `json:"id"`
+	AccountID      int64     `json:"account_id"`
+	ToAccountID    int64     `json:"to_account_id"`
+	Amount         int64     `json:"amount"`
+	CapturedAmount int64     `json:"captured_amount"`
+	Status         string    `json:"status"`
+	TransferID     *int64    `json:"transfer_id,omitempty"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newHoldResponse(hold sqlc.Hold) holdResponse {
+	rsp := holdResponse{
+		ID:             hold.ID,
+		AccountID:      hold.AccountID,
+		ToAccountID:    hold.ToAccountID,
+		Amount:         hold.Amount,
+		CapturedAmount: hold.CapturedAmount,
+		Status:         hold.Status,
+		ExpiresAt:      hold.ExpiresAt,
+		CreatedAt:      hold.CreatedAt,
+	}
+	if hold.TransferID.Valid {
+		rsp.TransferID = &hold.TransferID.Int64
+	}
+	return rsp
+}
+
+type authorizeHoldRequest struct {
+	AccountID   int64      `json:"account_id" binding:"required,min=1"`
+	ToAccountID int64      `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64      `json:"amount" binding:"required,gt=0"`
+	Currency    string     `json:"currency" binding:"required,currency"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+func (server *Server) authorizeHold(ctx *gin.Context) {
+	var req authorizeHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.AccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+	if account.Owner != authPayload.Username {
+		respondError(ctx, apperr.Forbidden("account"))
+		return
+	}
+
+	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	ttl := server.config.HoldTTL
+	if ttl <= 0 {
+		ttl = defaultHoldTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			respondError(ctx, apperr.Invalid("hold_expired"))
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	result, err := server.store.AuthorizeHoldTx(server.auditContext(ctx, authPayload.Username, "hold.authorize"), sqlc.AuthorizeHoldTxParams{
+		AccountID:   req.AccountID,
+		ToAccountID: req.ToAccountID,
+		Amount:      req.Amount,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, sqlc.ErrInsufficientFunds) {
+			respondError(ctx, apperr.InsufficientFunds("hold"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(result.Hold))
+}
+
+type getHoldRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) {
+	var req getHoldRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	hold, valid := server.ownHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(hold))
+}
+
+type captureHoldRequest struct {
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri getHoldRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	// тело необязательно: без суммы списывается всё удержание
+	var req captureHoldRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			respondError(ctx, apperr.Validation(err))
+			return
+		}
+	}
+
+	hold, valid := server.ownHold(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	result, err := server.store.CaptureHoldTx(server.auditContext(ctx, authPayload.Username, "hold.capture"), sqlc.CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: req.Amount,
+		Now:    time.Now(),
+	})
+	if err != nil {
+		server.holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) voidHold(ctx *gin.Context) {
+	var req getHoldRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	hold, valid := server.ownHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	result, err := server.store.VoidHoldTx(server.auditContext(ctx, authPayload.Username, "hold.void"), hold.ID)
+	if err != nil {
+		server.holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(result.Hold))
+}
+
+// ownHold loads the hold and checks the held account belongs to the authenticated user
+func (server *Server) ownHold(ctx *gin.Context, id int64) (sqlc.Hold, bool) {
+	hold, err := server.store.GetHold(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("hold"))
+			return hold, false
+		}
+		internalError(ctx, err)
+		return hold, false
+	}
+
+	account, err := server.store.GetAccount(ctx, hold.AccountID)
+	if err != nil {
+		internalError(ctx, err)
+		return hold, false
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return hold, false
+	}
+	if account.Owner != authPayload.Username {
+		respondError(ctx, apperr.Forbidden("hold"))
+		return hold, false
+	}
+
+	return hold, true
+}
+
+func (server *Server) holdErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	// текст ошибки хранилища служит ключом каталога, поэтому берётся у самой ошибки, без обёрток
+	case errors.Is(err, sqlc.ErrHoldNotAuthorized):
+		respondError(ctx, apperr.Conflict("hold_not_authorized"))
+	case errors.Is(err, sqlc.ErrHoldExpired):
+		respondError(ctx, apperr.Conflict("hold_expired"))
+	case errors.Is(err, sqlc.ErrCaptureExceedsHold):
+		respondError(ctx, apperr.Invalid("capture_exceeds_hold"))
+	case errors.Is(err, sqlc.ErrInsufficientFunds):
+		respondError(ctx, apperr.InsufficientFunds("transfer"))
+	default:
+		internalError(ctx, err)
+	}
+}
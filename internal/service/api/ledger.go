@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+)
+
+type trialBalanceRequest struct {
+	// AsOf limits the report to postings made before it, now by default
+	AsOf time.Time `form:"as_of" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type trialBalanceLine struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Debit  int64  `json:"debit"`
+	Credit int64  `json:"credit"`
+	// Balance is shown on the account's normal side: debit for assets and expenses, credit otherwise
+	Balance int64 `json:"balance"`
+}
+
+type trialBalanceCurrency struct {
+	Currency    string             `json:"currency"`
+	Accounts    []trialBalanceLine `json:"accounts"`
+	TotalDebit  int64              `json:"total_debit"`
+	TotalCredit int64              `json:"total_credit"`
+	Balanced    bool               `json:"balanced"`
+}
+
+type trialBalanceResponse struct {
+	AsOf       time.Time              `json:"as_of"`
+	Currencies []trialBalanceCurrency `json:"currencies"`
+}
+
+func (server *Server) getTrialBalance(ctx *gin.Context) {
+	var req trialBalanceRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	if req.AsOf.IsZero() {
+		req.AsOf = time.Now()
+	}
+
+	rows, err := server.store.GetTrialBalance(ctx, req.AsOf)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTrialBalanceResponse(req.AsOf, rows))
+}
+
+// newTrialBalanceResponse groups the rows, which come ordered by currency, into one report per currency
+func newTrialBalanceResponse(asOf time.Time, rows []sqlc.GetTrialBalanceRow) trialBalanceResponse {
+	rsp := trialBalanceResponse{
+		AsOf:       asOf,
+		Currencies: []trialBalanceCurrency{},
+	}
+
+	for _, row := range rows {
+		if n := len(rsp.Currencies); n == 0 || rsp.Currencies[n-1].Currency != row.Currency {
+			rsp.Currencies = append(rsp.Currencies, trialBalanceCurrency{Currency: row.Currency})
+		}
+		currency := &rsp.Currencies[len(rsp.Currencies)-1]
+
+		line := trialBalanceLine{
+			Code:    row.Code,
+			Name:    row.Name,
+			Type:    row.Type,
+			Debit:   row.Debit,
+			Credit:  row.Credit,
+			Balance: row.Credit - row.Debit,
+		}
+		if row.Type == "asset" || row.Type == "expense" {
+			line.Balance = -line.Balance
+		}
+
+		currency.Accounts = append(currency.Accounts, line)
+		currency.TotalDebit += row.Debit
+		currency.TotalCredit += row.Credit
+	}
+
+	for i := range rsp.Currencies {
+		rsp.Currencies[i].Balanced = rsp.Currencies[i].TotalDebit == rsp.Currencies[i].TotalCredit
+	}
+
+	return rsp
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetTrialBalanceAPI(t *testing.T) {
+	asOf := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	rows := []sqlc.GetTrialBalanceRow{
+		{Code: sqlc.GLCustomerDeposits, Type: "liability", Currency: "EUR", Debit: 100, Credit: 150},
+		{Code: sqlc.GLSuspense, Type: "asset", Currency: "EUR", Debit: 50},
+		{Code: sqlc.GLCustomerDeposits, Type: "liability", Currency: "USD", Debit: 10, Credit: 10},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?as_of=2024-03-10T00:00:00Z",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTrialBalance(gomock.Any(), gomock.Eq(asOf)).
+					Times(1).
+					Return(rows, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var rsp trialBalanceResponse
+				require.NoError(t, json.Unmarshal(data, &rsp))
+				require.Len(t, rsp.Currencies, 2)
+
+				eur := rsp.Currencies[0]
+				require.Equal(t, "EUR", eur.Currency)
+				require.Len(t, eur.Accounts, 2)
+				require.Equal(t, int64(50), eur.Accounts[0].Balance)
+				require.Equal(t, int64(50), eur.Accounts[1].Balance)
+				require.Equal(t, int64(150), eur.TotalDebit)
+				require.Equal(t, int64(150), eur.TotalCredit)
+				require.True(t, eur.Balanced)
+
+				require.Equal(t, "USD", rsp.Currencies[1].Currency)
+			},
+		},
+		{
+			name:  "InvalidAsOf",
+			query: "?as_of=yesterday",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTrialBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "DepositorForbidden",
+			query: "",
+			role:  util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTrialBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/ledger/trial-balance"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "banker", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,29 +1,66 @@
 package api
 
 import (
+	"context"
+	"fmt"
+	"net/netip"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/gin-gonic/gin"
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
 	"github.com/hisshihi/simple-bank/db/sqlc"
 	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/metrics"
+	"github.com/hisshihi/simple-bank/internal/ratelimit"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func newTestServer(t *testing.T, store sqlc.Store) *Server {
+	return newTestServerWithLimiter(t, store, nil)
+}
+
+func newTestServerWithLimiter(t *testing.T, store sqlc.Store, limiter *ratelimit.Limiter) *Server {
+	// AuditTx только оборачивает изменения в транзакцию, поэтому в тестах он вызывает fn напрямую
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().
+			AuditTx(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			DoAndReturn(func(ctx context.Context, fn func(q sqlc.Querier) (sqlc.AuditChange, error)) error {
+				_, err := fn(mockStore)
+				return err
+			})
+	}
+
 	config := config.Config{
 		TokenSymmetricKey:  gofakeit.Password(true, true, true, true, false, 32),
 		AccesTokenDuration: time.Minute,
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, metrics.New(prometheus.NewRegistry()), limiter)
 	require.NoError(t, err)
+	server.resolver = testResolver{}
 
 	return server
 }
 
+// testResolver answers for the hosts used in tests, so webhook addresses are checked offline
+type testResolver struct{}
+
+func (testResolver) LookupNetIP(_ context.Context, _ string, host string) ([]netip.Addr, error) {
+	switch host {
+	case "example.com":
+		return []netip.Addr{netip.MustParseAddr("93.184.215.14")}, nil
+	case "internal.example.com":
+		return []netip.Addr{netip.MustParseAddr("10.0.0.5")}, nil
+	}
+	return nil, fmt.Errorf("no such host %s", host)
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
@@ -1,12 +1,12 @@
 package api
 
 import (
-	"errors"
-	"fmt"
-	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/logging"
 	"github.com/hisshihi/simple-bank/pkg/util"
 )
 
@@ -14,39 +14,53 @@ const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+
+	requestIDHeaderKey = logging.RequestIDHeader
 )
 
 func authMiddleware(tokenMaker util.Maker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			respondError(ctx, apperr.Unauthenticated("missing_authorization"))
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			respondError(ctx, apperr.Unauthenticated("authorization_format"))
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			respondError(ctx, apperr.Unauthenticated("authorization_type", authorizationType))
 			return
 		}
 
 		accessToken := fields[1]
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			// From переводит ошибку токена в unauthenticated
+			respondError(ctx, err)
 			return
 		}
 
 		ctx.Set(authorizationPayloadKey, payload)
+		logging.SetUser(ctx, payload.Username)
+		ctx.Next()
+	}
+}
+
+// requireRole lets the request through only when the authenticated user has one of the roles
+func requireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+		if !ok || !slices.Contains(roles, payload.Role) {
+			respondError(ctx, apperr.Forbidden("role"))
+			return
+		}
+
 		ctx.Next()
 	}
 }
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/i18n"
+	"github.com/hisshihi/simple-bank/internal/logging"
+)
+
+// respondError writes err as problem details in the request's locale, an internal error is logged and its cause
+// never reaches the client. The request is aborted, so it works in middleware too
+func respondError(ctx *gin.Context, err error) {
+	e := apperr.From(err)
+	if e.Code == apperr.CodeInternal {
+		slog.ErrorContext(ctx, "request failed", "error", e.Err)
+	}
+
+	locale := i18n.Locale(ctx.Request.Context())
+	problem := e.Problem(locale, ctx.Request.URL.Path, logging.RequestID(ctx.Request.Context()))
+	// gin не перезаписывает уже выставленный Content-Type
+	ctx.Header("Content-Type", apperr.ProblemContentType)
+	ctx.Header("Content-Language", locale)
+	if seconds := e.RetryAfterSeconds(); seconds > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// internalError responds with a generic 500, err is only logged
+func internalError(ctx *gin.Context, err error) {
+	respondError(ctx, apperr.Internal(err))
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/clientip"
+	"github.com/hisshihi/simple-bank/internal/ratelimit"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+// rateLimitMiddleware limits the requests of the caller identity returns per route,
+// the route is the method and the route pattern, such as "POST /transfers"
+func rateLimitMiddleware(limiter *ratelimit.Limiter, identity func(*gin.Context) string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		result := limiter.Allow(ctx, ctx.Request.Method+" "+ctx.FullPath(), identity(ctx))
+		if !result.Allowed {
+			respondError(ctx, apperr.RateLimited(result.RetryAfter))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// clientIdentity keys the public routes by client IP
+func clientIdentity(ctx *gin.Context) string {
+	return "ip:" + clientip.FromContext(ctx)
+}
+
+// userIdentity keys the authenticated routes by user, so clients behind one NAT don't share a limit,
+// it must run after authMiddleware
+func userIdentity(ctx *gin.Context) string {
+	payload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	return "user:" + payload.Username
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/ratelimit"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+
+	limiter := ratelimit.New(ratelimit.NewMemoryBackend(), map[string]ratelimit.Limit{
+		"POST /login":    {Count: 1, Period: time.Minute},
+		"POST /accounts": {Count: 1, Period: time.Minute},
+	})
+	server := newTestServerWithLimiter(t, mockdb.NewMockStore(gomock.NewController(t)), limiter)
+
+	// тело запроса некорректно, поэтому пропущенный запрос отвечает 400 и не трогает хранилище
+	send := func(path, remoteAddr, username string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(`{}`)))
+		require.NoError(t, err)
+		request.RemoteAddr = remoteAddr
+		if username != "" {
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, user.Role, time.Minute)
+		}
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusBadRequest, send("/login", "192.0.2.1:1234", "").Code)
+
+	recorder := send("/login", "192.0.2.1:1234", "")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+
+	var rsp apperr.Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Equal(t, apperr.CodeRateLimited, rsp.Code)
+
+	// у другого IP своя корзина
+	require.Equal(t, http.StatusBadRequest, send("/login", "192.0.2.2:1234", "").Code)
+
+	// после входа лимит считается по пользователю, а не по IP
+	require.Equal(t, http.StatusBadRequest, send("/accounts", "192.0.2.1:1234", user.Username).Code)
+	require.Equal(t, http.StatusTooManyRequests, send("/accounts", "192.0.2.2:1234", user.Username).Code)
+	require.Equal(t, http.StatusBadRequest, send("/accounts", "192.0.2.1:1234", otherUser.Username).Code)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/task"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+type listReconciliationRunsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listReconciliationRuns(ctx *gin.Context) {
+	var req listReconciliationRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	runs, err := server.store.ListReconciliationRuns(ctx, sqlc.ListReconciliationRunsParams{
+		Limit:  int64(req.PageSize),
+		Offset: int64((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// requestReconciliation queues a reconciliation for the task pool, the run shows up in
+// listReconciliationRuns once a worker picks the task
+func (server *Server) requestReconciliation(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	var queued sqlc.Task
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "reconciliation.request"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		queued, err = task.ReconcileLedger.Enqueue(ctx, q, struct{}{})
+		return sqlc.AuditChange{
+			TargetType: sqlc.AuditTargetTask,
+			TargetID:   strconv.FormatInt(queued.ID, 10),
+			After:      queued,
+		}, err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, queued)
+}
+
+type reconciliationRunURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listReconciliationDiscrepanciesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+func (server *Server) listReconciliationDiscrepancies(ctx *gin.Context) {
+	var uri reconciliationRunURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req listReconciliationDiscrepanciesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	_, err := server.store.GetReconciliationRun(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("reconciliation_run"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	discrepancies, err := server.store.ListReconciliationDiscrepancies(ctx, sqlc.ListReconciliationDiscrepanciesParams{
+		RunID:  uri.ID,
+		Limit:  int64(req.PageSize),
+		Offset: int64((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, discrepancies)
+}
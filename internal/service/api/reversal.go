@@ -0,0 +1,232 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+type reverseTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferRequest struct {
+	Amount int64  `json:"amount" binding:"required,gt=0"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// reverseTransfer refunds a transfer right away when asked by its recipient or a banker.
+// The sender can only file a request that a banker has to approve.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri reverseTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("transfer"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	if req.Amount > transfer.Amount-transfer.RefundedAmount {
+		respondError(ctx, apperr.Invalid("refund_exceeds_transfer"))
+		return
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	if authPayload.Role == util.BankerRole || toAccount.Owner == authPayload.Username {
+		result, err := server.store.ReverseTransferTx(server.auditContext(ctx, authPayload.Username, "transfer.reverse"), sqlc.ReverseTransferTxParams{
+			TransferID:  transfer.ID,
+			Amount:      req.Amount,
+			Reason:      req.Reason,
+			RequestedBy: authPayload.Username,
+		})
+		if err != nil {
+			reversalErrorResponse(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+		return
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+	if fromAccount.Owner != authPayload.Username {
+		respondError(ctx, apperr.Forbidden("transfer"))
+		return
+	}
+
+	var request sqlc.ReversalRequest
+	err = server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "reversal_request.create"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		request, err = q.CreateReversalRequest(ctx, sqlc.CreateReversalRequestParams{
+			TransferID:  transfer.ID,
+			Amount:      req.Amount,
+			Reason:      req.Reason,
+			RequestedBy: authPayload.Username,
+			Status:      sqlc.ReversalPending,
+		})
+		return sqlc.AuditChange{
+			TargetType: sqlc.AuditTargetReversalRequest,
+			TargetID:   strconv.FormatInt(request.ID, 10),
+			After:      request,
+		}, err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, request)
+}
+
+type listReversalRequestsRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending completed rejected"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listReversalRequests(ctx *gin.Context) {
+	var req listReversalRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	status := req.Status
+	if status == "" {
+		status = sqlc.ReversalPending
+	}
+
+	requests, err := server.store.ListReversalRequests(ctx, sqlc.ListReversalRequestsParams{
+		Status: status,
+		Limit:  int64(req.PageSize),
+		Offset: int64((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+type reversalRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) approveReversalRequest(ctx *gin.Context) {
+	var uri reversalRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+
+	result, err := server.store.ApproveReversalTx(server.auditContext(ctx, authPayload.Username, "reversal_request.approve"), sqlc.ApproveReversalTxParams{
+		RequestID:  uri.ID,
+		ReviewedBy: authPayload.Username,
+	})
+	if err != nil {
+		reversalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) rejectReversalRequest(ctx *gin.Context) {
+	var uri reversalRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+
+	var request sqlc.ReversalRequest
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "reversal_request.reject"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		request, err = q.UpdateReversalRequest(ctx, sqlc.UpdateReversalRequestParams{
+			ID:         uri.ID,
+			Status:     sqlc.ReversalRejected,
+			ReviewedBy: sql.NullString{String: authPayload.Username, Valid: true},
+		})
+		return sqlc.AuditChange{
+			TargetType: sqlc.AuditTargetReversalRequest,
+			TargetID:   strconv.FormatInt(uri.ID, 10),
+			After:      request,
+		}, err
+	})
+	if err != nil {
+		// запрос не найден или уже рассмотрен
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.Conflict("reversal_not_pending"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+func reversalErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondError(ctx, apperr.NotFound("transfer"))
+	// текст ошибки хранилища служит ключом каталога, поэтому берётся у самой ошибки, без обёрток
+	case errors.Is(err, sqlc.ErrReversalNotPending):
+		respondError(ctx, apperr.Conflict("reversal_not_pending"))
+	case errors.Is(err, sqlc.ErrRefundExceedsTransfer):
+		respondError(ctx, apperr.Invalid("refund_exceeds_transfer"))
+	case errors.Is(err, sqlc.ErrReversalOfReversal):
+		respondError(ctx, apperr.Invalid("reversal_of_reversal"))
+	case errors.Is(err, sqlc.ErrInsufficientFunds):
+		respondError(ctx, apperr.InsufficientFunds("refund"))
+	default:
+		internalError(ctx, err)
+	}
+}
@@ -0,0 +1,403 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+type scheduledTransferResponse struct {
+	ID            int64      `json:"id"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        int64      `json:"amount"`
+	Recurrence    string     `json:"recurrence"`
+	StartAt       time.Time  `json:"start_at"`
+	EndAt         *time.Time `json:"end_at,omitempty"`
+	NextRunAt     time.Time  `json:"next_run_at"`
+	Status        string     `json:"status"`
+	FailureCount  int64      `json:"failure_count"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func newScheduledTransferResponse(scheduled sqlc.ScheduledTransfer) scheduledTransferResponse {
+	rsp := scheduledTransferResponse{
+		ID:            scheduled.ID,
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Recurrence:    scheduled.Recurrence,
+		StartAt:       scheduled.StartAt,
+		NextRunAt:     scheduled.NextRunAt,
+		Status:        scheduled.Status,
+		FailureCount:  scheduled.FailureCount,
+		LastError:     scheduled.LastError,
+		CreatedAt:     scheduled.CreatedAt,
+	}
+	if scheduled.EndAt.Valid {
+		rsp.EndAt = &scheduled.EndAt.Time
+	}
+	return rsp
+}
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64      `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64      `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64      `json:"amount" binding:"required,gt=0"`
+	Currency      string     `json:"currency" binding:"required,currency"`
+	Recurrence    string     `json:"recurrence" binding:"required,recurrence"`
+	StartAt       time.Time  `json:"start_at" binding:"required"`
+	EndAt         *time.Time `json:"end_at"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+	if fromAccount.Owner != authPayload.Username {
+		respondError(ctx, apperr.Forbidden("from_account"))
+		return
+	}
+
+	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	// прошедшие повторения не выполняются задним числом
+	start := req.StartAt
+	if now := time.Now(); start.Before(now) {
+		start = now
+	}
+
+	nextRunAt, err := util.FirstOccurrence(req.Recurrence, start)
+	if err != nil {
+		respondError(ctx, apperr.Invalid("recurrence", req.Recurrence))
+		return
+	}
+
+	arg := sqlc.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Recurrence:    req.Recurrence,
+		StartAt:       req.StartAt,
+		NextRunAt:     nextRunAt,
+	}
+	if req.EndAt != nil {
+		if req.EndAt.Before(nextRunAt) {
+			respondError(ctx, apperr.Invalid("empty_schedule"))
+			return
+		}
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+
+	var scheduled sqlc.ScheduledTransfer
+	err = server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "scheduled_transfer.create"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		scheduled, err = q.CreateScheduledTransfer(ctx, arg)
+		return scheduledTransferAuditChange(sqlc.ScheduledTransfer{}, scheduled), err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+type getScheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	scheduled, valid := server.ownScheduledTransfer(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+
+	arg := sqlc.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  int64(req.PageSize),
+		Offset: int64((req.PageID - 1) * req.PageSize),
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	rsp := make([]scheduledTransferResponse, 0, len(scheduledTransfers))
+	for _, scheduled := range scheduledTransfers {
+		rsp = append(rsp, newScheduledTransferResponse(scheduled))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type updateScheduledTransferRequest struct {
+	Amount     *int64     `json:"amount" binding:"omitempty,gt=0"`
+	Recurrence *string    `json:"recurrence" binding:"omitempty,recurrence"`
+	EndAt      *time.Time `json:"end_at"`
+	Status     *string    `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	scheduled, valid := server.ownScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if scheduled.Status != sqlc.ScheduledTransferActive && scheduled.Status != sqlc.ScheduledTransferPaused {
+		respondError(ctx, apperr.Invalid("schedule_finished"))
+		return
+	}
+
+	arg := sqlc.UpdateScheduledTransferParams{
+		ID: scheduled.ID,
+	}
+	if req.Amount != nil {
+		arg.Amount = sql.NullInt64{Int64: *req.Amount, Valid: true}
+	}
+	if req.Status != nil {
+		arg.Status = sql.NullString{String: *req.Status, Valid: true}
+	}
+
+	// при смене расписания или возобновлении следующий перевод считается от текущего момента
+	recurrence := scheduled.Recurrence
+	resumed := req.Status != nil && *req.Status == sqlc.ScheduledTransferActive && scheduled.Status == sqlc.ScheduledTransferPaused
+	if req.Recurrence != nil || resumed {
+		if req.Recurrence != nil {
+			recurrence = *req.Recurrence
+			arg.Recurrence = sql.NullString{String: recurrence, Valid: true}
+		}
+
+		start := scheduled.StartAt
+		if now := time.Now(); start.Before(now) {
+			start = now
+		}
+		nextRunAt, err := util.FirstOccurrence(recurrence, start)
+		if err != nil {
+			respondError(ctx, apperr.Invalid("recurrence", recurrence))
+			return
+		}
+		arg.NextRunAt = sql.NullTime{Time: nextRunAt, Valid: true}
+	}
+
+	if req.EndAt != nil {
+		nextRunAt := scheduled.NextRunAt
+		if arg.NextRunAt.Valid {
+			nextRunAt = arg.NextRunAt.Time
+		}
+		if req.EndAt.Before(nextRunAt) {
+			respondError(ctx, apperr.Invalid("end_before_next_run"))
+			return
+		}
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	before := scheduled
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "scheduled_transfer.update"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		scheduled, err = q.UpdateScheduledTransfer(ctx, arg)
+		return scheduledTransferAuditChange(before, scheduled), err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
+
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	scheduled, valid := server.ownScheduledTransfer(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "scheduled_transfer.cancel"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		cancelled, err := q.UpdateScheduledTransfer(ctx, sqlc.UpdateScheduledTransferParams{
+			ID:     scheduled.ID,
+			Status: sql.NullString{String: sqlc.ScheduledTransferCancelled, Valid: true},
+		})
+		return scheduledTransferAuditChange(scheduled, cancelled), err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type listScheduledTransferRunsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type scheduledTransferRunResponse struct {
+	ID           int64     `json:"id"`
+	OccurrenceAt time.Time `json:"occurrence_at"`
+	Status       string    `json:"status"`
+	Attempts     int64     `json:"attempts"`
+	TransferID   *int64    `json:"transfer_id,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri getScheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req listScheduledTransferRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	scheduled, valid := server.ownScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, sqlc.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               int64(req.PageSize),
+		Offset:              int64((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	rsp := make([]scheduledTransferRunResponse, 0, len(runs))
+	for _, run := range runs {
+		item := scheduledTransferRunResponse{
+			ID:           run.ID,
+			OccurrenceAt: run.OccurrenceAt,
+			Status:       run.Status,
+			Attempts:     run.Attempts,
+			LastError:    run.LastError,
+			UpdatedAt:    run.UpdatedAt,
+		}
+		if run.TransferID.Valid {
+			item.TransferID = &run.TransferID.Int64
+		}
+		rsp = append(rsp, item)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// ownScheduledTransfer loads the scheduled transfer and checks it belongs to the authenticated user
+func (server *Server) ownScheduledTransfer(ctx *gin.Context, id int64) (sqlc.ScheduledTransfer, bool) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("scheduled_transfer"))
+			return scheduled, false
+		}
+		internalError(ctx, err)
+		return scheduled, false
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return scheduled, false
+	}
+	if scheduled.Owner != authPayload.Username {
+		respondError(ctx, apperr.Forbidden("scheduled_transfer"))
+		return scheduled, false
+	}
+
+	return scheduled, true
+}
+
+// scheduledTransferAuditChange describes a change of the schedule, an empty before means it was created
+func scheduledTransferAuditChange(before sqlc.ScheduledTransfer, after sqlc.ScheduledTransfer) sqlc.AuditChange {
+	change := sqlc.AuditChange{
+		TargetType: sqlc.AuditTargetScheduledTransfer,
+		TargetID:   strconv.FormatInt(after.ID, 10),
+		After:      after,
+	}
+	if before.ID != 0 {
+		change.Before = before
+	}
+	return change
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"fmt"
+	"net"
+	"net/http"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -9,62 +11,103 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/clientip"
 	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/egress"
+	"github.com/hisshihi/simple-bank/internal/i18n"
+	"github.com/hisshihi/simple-bank/internal/logging"
+	"github.com/hisshihi/simple-bank/internal/metrics"
+	"github.com/hisshihi/simple-bank/internal/ratelimit"
 	"github.com/hisshihi/simple-bank/pkg/util"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
 	config     config.Config
 	store      sqlc.Store
 	tokenMaker util.Maker
-	router     *gin.Engine
+	metrics    *metrics.Metrics
+	limiter    *ratelimit.Limiter
+	clientIPs  *clientip.Resolver
+	// resolver checks where webhook URLs point, tests replace it to stay off the network
+	resolver egress.Resolver
+	router   *gin.Engine
 }
 
-func NewServer(config config.Config, store sqlc.Store) (*Server, error) {
+// NewServer builds the Gin API, a nil limiter turns rate limiting off
+func NewServer(config config.Config, store sqlc.Store, metrics *metrics.Metrics, limiter *ratelimit.Limiter) (*Server, error) {
 	tokenMaker, err := util.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
+	clientIPs, err := config.ClientIPResolver()
+	if err != nil {
+		return nil, err
+	}
 	server := &Server{
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		metrics:    metrics,
+		limiter:    limiter,
+		clientIPs:  clientIPs,
+		resolver:   net.DefaultResolver,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("recurrence", validRecurrence)
+		v.RegisterValidation("username", validUsername)
+		v.RegisterValidation("full_name", validFullName)
+		v.RegisterTagNameFunc(fieldName)
 	}
 
-	server.setupRouter()
+	if err := server.setupRouter(); err != nil {
+		return nil, err
+	}
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.New()
+	// обработчики передают *gin.Context в хранилище, а трасса и request id лежат в контексте запроса
+	router.ContextWithFallback = true
+	router.Use(server.metrics.GinMiddleware())
+	router.Use(otelgin.Middleware("simple-bank-gin"))
+	router.Use(logging.GinMiddleware())
+	router.Use(server.clientIPs.GinMiddleware())
+	router.Use(i18n.GinMiddleware())
 	router.Use(gin.Recovery())
-	router.Use(gin.Logger())
-	router.SetTrustedProxies([]string{
-		"127.0.0.1",
-		"10.0.0.0/8",
-		"172.17.0.2/12",
-		"192.168.0.0/16",
-	})
-
-	corsConfig := cors.Config{
-		AllowOrigins:     []string{"http://localhost:8080", "http://localhost:8081", "http://localhost:5173", "https://order-of-venhicles-services.onrender.com"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+	// адрес клиента определяет clientip, как и для gRPC; собственный ClientIP gin видит только соединение
+	if err := router.SetTrustedProxies(nil); err != nil {
+		return err
 	}
-	router.Use(cors.New(corsConfig))
 
-	router.POST("/register", server.register)
-	router.POST("/login", server.login)
-	router.POST("/refresh-token", server.renewAccessToken)
+	if origins := server.config.CORSOrigins(); len(origins) > 0 {
+		corsConfig := cors.Config{
+			AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "Accept-Language", requestIDHeaderKey},
+			ExposeHeaders:    []string{"Content-Length", "Content-Language", "Retry-After", requestIDHeaderKey},
+			AllowCredentials: server.config.CORSAllowCredentials,
+			MaxAge:           12 * time.Hour,
+		}
+		if server.config.AllowsAnyOrigin() {
+			corsConfig.AllowAllOrigins = true
+		} else {
+			corsConfig.AllowOrigins = origins
+		}
+		router.Use(cors.New(corsConfig))
+	}
+
+	publicRoutes := router.Group("/").Use(rateLimitMiddleware(server.limiter, clientIdentity))
+
+	publicRoutes.POST("/register", server.register)
+	publicRoutes.POST("/login", server.login)
+	publicRoutes.POST("/refresh-token", server.renewAccessToken)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker), rateLimitMiddleware(server.limiter, userIdentity))
+
+	authRoutes.GET("/sessions", server.listSessions)
 
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
@@ -72,15 +115,64 @@ func (server *Server) setupRouter() {
 	authRoutes.PUT("/accounts", server.updateAccount)
 	authRoutes.DELETE("/accounts/:id", server.deleteAccount)
 
+	authRoutes.GET("/account-products", server.listAccountProducts)
+
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers/quote", server.quoteTransfer)
+	authRoutes.POST("/transfers/:id/reversals", server.reverseTransfer)
 
-	server.router = router
-}
+	authRoutes.POST("/scheduled-transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled-transfers/:id", server.getScheduledTransfer)
+	authRoutes.PATCH("/scheduled-transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled-transfers/:id", server.cancelScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers/:id/runs", server.listScheduledTransferRuns)
+
+	authRoutes.POST("/holds", server.authorizeHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/void", server.voidHold)
 
-func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+	authRoutes.POST("/webhooks", server.createWebhook)
+	authRoutes.GET("/webhooks", server.listWebhooks)
+	authRoutes.GET("/webhooks/:id", server.getWebhook)
+	authRoutes.PATCH("/webhooks/:id", server.updateWebhook)
+	authRoutes.DELETE("/webhooks/:id", server.deleteWebhook)
+	authRoutes.GET("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	authRoutes.POST("/webhooks/:id/deliveries/:delivery_id/replay", server.replayWebhookDelivery)
+	authRoutes.POST("/webhooks/:id/test", server.testWebhook)
+
+	bankerRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker), rateLimitMiddleware(server.limiter, userIdentity), requireRole(util.BankerRole))
+
+	bankerRoutes.GET("/reversal-requests", server.listReversalRequests)
+	bankerRoutes.POST("/reversal-requests/:id/approve", server.approveReversalRequest)
+	bankerRoutes.POST("/reversal-requests/:id/reject", server.rejectReversalRequest)
+
+	bankerRoutes.GET("/ledger/trial-balance", server.getTrialBalance)
+
+	adminRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker), rateLimitMiddleware(server.limiter, userIdentity), requireRole(util.AdminRole))
+
+	adminRoutes.PUT("/accounts/:id/overdraft-limit", server.updateOverdraftLimit)
+	adminRoutes.PUT("/account-products/:code", server.updateAccountProductRate)
+	adminRoutes.PUT("/accounts/:id/tier", server.updateAccountTier)
+	adminRoutes.GET("/fee-schedules", server.listFeeSchedules)
+	adminRoutes.PUT("/fee-schedules/:currency/:tier", server.upsertFeeSchedule)
+
+	adminRoutes.GET("/reconciliation/runs", server.listReconciliationRuns)
+	adminRoutes.POST("/reconciliation/runs", server.requestReconciliation)
+	adminRoutes.GET("/reconciliation/runs/:id/discrepancies", server.listReconciliationDiscrepancies)
+
+	adminRoutes.GET("/audit-events", server.listAuditEvents)
+	adminRoutes.GET("/audit-events/verify", server.verifyAuditChain)
+
+	adminRoutes.GET("/tasks", server.listTasks)
+	adminRoutes.POST("/tasks/:id/retry", server.retryTask)
+
+	server.router = router
+	return nil
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// Handler returns the router, the caller serves it and shuts it down
+func (server *Server) Handler() http.Handler {
+	return server.router
 }
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/useragent"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+// sessionResponse describes a login of the user, the refresh token is never returned.
+// Device, OS and browser are parsed from the user agent when the list is read
+type sessionResponse struct {
+	ID             uuid.UUID `json:"id"`
+	ClientIP       string    `json:"client_ip"`
+	UserAgent      string    `json:"user_agent"`
+	Device         string    `json:"device"`
+	OS             string    `json:"os"`
+	OSVersion      string    `json:"os_version"`
+	Browser        string    `json:"browser"`
+	BrowserVersion string    `json:"browser_version"`
+	IsBlocked      bool      `json:"is_blocked"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newSessionResponse(session sqlc.Session) sessionResponse {
+	ua := useragent.Parse(session.UserAgent)
+	return sessionResponse{
+		ID:             session.ID,
+		ClientIP:       session.ClientIp,
+		UserAgent:      session.UserAgent,
+		Device:         ua.Device,
+		OS:             ua.OS,
+		OSVersion:      ua.OSVersion,
+		Browser:        ua.Browser,
+		BrowserVersion: ua.BrowserVersion,
+		IsBlocked:      session.IsBlocked,
+		ExpiresAt:      session.ExpiresAt,
+		CreatedAt:      session.CreatedAt,
+	}
+}
+
+// listSessions returns the sessions of the authenticated user that have not expired, newest first
+func (server *Server) listSessions(ctx *gin.Context) {
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+
+	sessions, err := server.store.ListSessions(ctx, authPayload.Username)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	rsp := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		rsp = append(rsp, newSessionResponse(session))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
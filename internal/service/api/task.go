@@ -0,0 +1,91 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+type listTasksRequest struct {
+	Status   string `form:"status" binding:"required,oneof=pending running succeeded dead"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+func (server *Server) listTasks(ctx *gin.Context) {
+	var req listTasksRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	tasks, err := server.store.ListTasks(ctx, sqlc.ListTasksParams{
+		Status: req.Status,
+		Limit:  int64(req.PageSize),
+		Offset: int64((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tasks)
+}
+
+type taskURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// retryTask puts a dead task back into its queue with fresh attempts
+func (server *Server) retryTask(ctx *gin.Context) {
+	var uri taskURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	before, err := server.store.GetTask(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("task"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	if before.Status != sqlc.TaskDead {
+		respondError(ctx, apperr.Conflict("task_not_dead"))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	var after sqlc.Task
+	err = server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "task.retry"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		after, err = q.RequeueDeadTask(ctx, uri.ID)
+		return sqlc.AuditChange{
+			TargetType: sqlc.AuditTargetTask,
+			TargetID:   strconv.FormatInt(uri.ID, 10),
+			Before:     before,
+			After:      after,
+		}, err
+	})
+	if err != nil {
+		// задачу успели вернуть в очередь параллельным запросом
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.Conflict("task_not_dead"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, after)
+}
@@ -2,11 +2,11 @@ package api
 
 import (
 	"database/sql"
-	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/internal/apperr"
 )
 
 type renewAccessRequest struct {
@@ -21,53 +21,49 @@ type renewAccessResponse struct {
 func (server *Server) renewAccessToken(ctx *gin.Context) {
 	var req renewAccessRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
 	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		respondError(ctx, apperr.Unauthenticated("refresh_token"))
 		return
 	}
 
 	session, err := server.store.GetSession(ctx, refreshPayload.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			respondError(ctx, apperr.NotFound("session"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
 	if session.IsBlocked {
-		err := fmt.Errorf("blocked session")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		respondError(ctx, apperr.Unauthenticated("session_blocked"))
 		return
 	}
 
 	if session.Username != refreshPayload.Username {
-		err := fmt.Errorf("incorrect session user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		respondError(ctx, apperr.Unauthenticated("session_user"))
 		return
 	}
 
 	if session.RefreshToken != req.RefreshToken {
-		err := fmt.Errorf("mismatched session token")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		respondError(ctx, apperr.Unauthenticated("session_token"))
 		return
 	}
 
 	if time.Now().After(session.ExpiresAt) {
-		err := fmt.Errorf("refresh token expired")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		respondError(ctx, apperr.Unauthenticated("refresh_token_expired"))
 		return
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(refreshPayload.Username, server.config.AccesTokenDuration)
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.Role, server.config.AccesTokenDuration)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
@@ -3,11 +3,11 @@ package api
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
 	"github.com/hisshihi/simple-bank/pkg/util"
 )
 
@@ -28,85 +28,140 @@ type transferResponse struct {
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
-	if !valid {
+	fromAccount, err := server.accountInCurrency(ctx, req.FromAccountID, req.Currency)
+	if err != nil {
+		server.rejectTransfer(ctx, err)
 		return
 	}
 
 	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondError(ctx, apperr.Unauthenticated("required"))
 		return
 	}
 	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
-	}
-
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
-	if !valid {
+		respondError(ctx, apperr.Forbidden("from_account"))
 		return
 	}
 
-	if !server.validAmount(ctx, req.FromAccountID, req.Amount) {
+	if _, err := server.accountInCurrency(ctx, req.ToAccountID, req.Currency); err != nil {
+		server.rejectTransfer(ctx, err)
 		return
 	}
 
+	// хватает ли денег вместе с комиссией, проверяет TransferTx под блокировкой счёта
 	arg := sqlc.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	result, err := server.store.TransferTx(server.auditContext(ctx, authPayload.Username, "transfer.create"), arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, sqlc.ErrInsufficientFunds) {
+			respondError(ctx, apperr.InsufficientFunds("transfer"))
+			return
+		}
+		internalError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, result)
 }
 
-func (server *Server) validAmount(ctx *gin.Context, accountID int64, amount int64) bool {
-	account, err := server.store.GetAccount(ctx, accountID)
+type transferQuoteRequest struct {
+	FromAccountID int64  `form:"from_account_id" binding:"required,min=1"`
+	Amount        int64  `form:"amount" binding:"required,gt=0"`
+	Currency      string `form:"currency" binding:"required,currency"`
+}
+
+type transferQuoteResponse struct {
+	FromAccountID int64  `json:"from_account_id"`
+	Currency      string `json:"currency"`
+	Amount        int64  `json:"amount"`
+	Fee           int64  `json:"fee"`
+	Total         int64  `json:"total"`
+}
+
+func (server *Server) quoteTransfer(ctx *gin.Context) {
+	var req transferQuoteRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+	if fromAccount.Owner != authPayload.Username {
+		respondError(ctx, apperr.Forbidden("from_account"))
+		return
+	}
+
+	fee, err := sqlc.TransferFee(ctx, server.store, fromAccount, req.Amount)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return false
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return false
+		internalError(ctx, err)
+		return
 	}
 
-	if account.Balance < amount {
-		err = fmt.Errorf("недостаточно средств для перевода: %d", account.Balance)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "недостаточно средств для перевода"})
-		return false
+	rsp := transferQuoteResponse{
+		FromAccountID: fromAccount.ID,
+		Currency:      fromAccount.Currency,
+		Amount:        req.Amount,
+		Fee:           fee,
+		Total:         req.Amount + fee,
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// rejectTransfer responds to a transfer refused before TransferTx runs. A missing account is
+// counted as a failed transfer, the same as when the store refuses it
+func (server *Server) rejectTransfer(ctx *gin.Context, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		server.metrics.TransferRejected(err)
 	}
-	return true
+	respondAccountError(ctx, err)
 }
 
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (sqlc.Account, bool) {
-	account, err := server.store.GetAccount(ctx, accountID)
+	account, err := server.accountInCurrency(ctx, accountID, currency)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return account, false
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondAccountError(ctx, err)
 		return account, false
 	}
+	return account, true
+}
+
+// accountInCurrency returns the account, sql.ErrNoRows when there is none and a currency
+// mismatch error when it is not in currency
+func (server *Server) accountInCurrency(ctx *gin.Context, accountID int64, currency string) (sqlc.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
 
 	if account.Currency != currency {
-		err = fmt.Errorf("аккаунт: [%d] не соответствие валют %v и %v", account.ID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "не соответствие валют аккаунта"})
-		return account, false
+		return account, apperr.New(apperr.CodeCurrencyMismatch, "account", account.ID, currency)
 	}
+	return account, nil
+}
 
-	return account, true
+func respondAccountError(ctx *gin.Context, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(ctx, apperr.NotFound("account"))
+		return
+	}
+	respondError(ctx, err)
 }
@@ -8,15 +8,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/clientip"
 	"github.com/hisshihi/simple-bank/pkg/util"
 	"github.com/lib/pq"
 )
 
 type registerRequest struct {
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	FullName string `json:"full_name" binding:"required"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username string `json:"username" binding:"required,min=3,max=100,username"`
+	Email    string `json:"email" binding:"required,max=200,email"`
+	FullName string `json:"full_name" binding:"required,max=100,full_name"`
+	Password string `json:"password" binding:"required,min=6,max=72"`
 }
 
 type userResponse struct {
@@ -28,13 +30,13 @@ type userResponse struct {
 func (server *Server) register(ctx *gin.Context) {
 	var req registerRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
@@ -45,16 +47,16 @@ func (server *Server) register(ctx *gin.Context) {
 		Email:          req.Email,
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	user, err := server.store.CreateUserTx(server.auditContext(ctx, req.Username, "user.register"), arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				respondError(ctx, apperr.AlreadyExists("user"))
 				return
 			}
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
@@ -68,8 +70,8 @@ func (server *Server) register(ctx *gin.Context) {
 }
 
 type loginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username string `json:"username" binding:"required,max=100"`
+	Password string `json:"password" binding:"required,min=6,max=72"`
 }
 
 type loginResponse struct {
@@ -81,38 +83,42 @@ type loginResponse struct {
 	User                  userResponse `json:"user"`
 }
 
+// errInvalidCredentials answers a login with an unknown username or a wrong password alike
+var errInvalidCredentials = apperr.Unauthenticated("credentials")
+
 func (server *Server) login(ctx *gin.Context) {
 	var req loginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, apperr.Validation(err))
 		return
 	}
 
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
+		// неизвестное имя и неверный пароль неотличимы, чтобы по ответу нельзя было перебирать пользователей
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			respondError(ctx, errInvalidCredentials)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
 	err = util.CheckPassword(user.HashedPassword, req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid password"})
+		respondError(ctx, errInvalidCredentials)
 		return
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccesTokenDuration)
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.AccesTokenDuration)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.RefreshTokenDuration)
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.RefreshTokenDuration)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		internalError(ctx, err)
 		return
 	}
 
@@ -121,12 +127,19 @@ func (server *Server) login(ctx *gin.Context) {
 		Username:     user.Username,
 		RefreshToken: refreshToken,
 		UserAgent:    ctx.Request.UserAgent(),
-		ClientIp:     ctx.ClientIP(),
+		ClientIp:     clientip.FromContext(ctx),
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiredAt,
 	}
 
-	server.store.CreateSession(ctx, argSession)
+	err = server.store.AuditTx(server.auditContext(ctx, user.Username, "user.login"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		session, err := q.CreateSession(ctx, argSession)
+		return sqlc.SessionAuditChange(session), err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
 
 	rsp := loginResponse{
 		SessionID:             refreshPayload.ID,
@@ -17,6 +17,7 @@ import (
 	"github.com/google/uuid"
 	mockdb "github.com/hisshihi/simple-bank/db/mock"
 	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
 	"github.com/hisshihi/simple-bank/pkg/util"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
@@ -76,7 +77,7 @@ func TestRegister(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateUser(gomock.Any(), EqCreateUserParams(arg, password)).
+					CreateUserTx(gomock.Any(), EqCreateUserParams(arg, password)).
 					Times(1).
 					Return(user, nil)
 			},
@@ -95,13 +96,34 @@ func TestRegister(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "InvalidUsername",
+			body: gin.H{
+				"username":  "user name",
+				"password":  password,
+				"full_name": user.FullName,
+				"email":     user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var rsp apperr.Problem
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, []apperr.FieldViolation{{Field: "username", Message: "must contain only letters, digits, underscore, dot or hyphen"}}, rsp.Errors)
+			},
+		},
 		{
 			name: "InternalError",
 			body: gin.H{
@@ -112,7 +134,7 @@ func TestRegister(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(sqlc.User{}, sql.ErrConnDone)
 			},
@@ -121,7 +143,7 @@ func TestRegister(t *testing.T) {
 			},
 		},
 		{
-			name: "ForbiddenError (duplicate username)",
+			name: "AlreadyExists (duplicate username)",
 			body: gin.H{
 				"username":  user.Username,
 				"password":  password,
@@ -130,7 +152,7 @@ func TestRegister(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(sqlc.User{}, &pq.Error{
 						Code:    "23505",
@@ -138,7 +160,7 @@ func TestRegister(t *testing.T) {
 					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
 		},
 	}
@@ -191,14 +213,19 @@ func TestLogin(t *testing.T) {
 					Return(user, nil)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()). // Принимаем любые параметры
+					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(sqlc.Session{ // Возвращаем объект Session, а не CreateSessionParams
-						ID:           uuid.New(),
-						Username:     user.Username,
-						RefreshToken: "refresh_token",
-						ExpiresAt:    time.Now().Add(24 * time.Hour),
-					}, nil)
+					DoAndReturn(func(_ any, arg sqlc.CreateSessionParams) (sqlc.Session, error) {
+						// прокси не доверенные, поэтому X-Forwarded-For не подменяет адрес соединения
+						require.Equal(t, "203.0.113.7", arg.ClientIp)
+						require.Equal(t, "curl/8.5.0", arg.UserAgent)
+						return sqlc.Session{
+							ID:           uuid.New(),
+							Username:     user.Username,
+							RefreshToken: "refresh_token",
+							ExpiresAt:    time.Now().Add(24 * time.Hour),
+						}, nil
+					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -248,7 +275,29 @@ func TestLogin(t *testing.T) {
 					Return(sqlc.User{}, sql.ErrNoRows)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "invalid username or password")
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{
+				"username": user.Username,
+				"password": password + "x",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				// ответ тот же, что и для неизвестного имени
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "invalid username or password")
 			},
 		},
 	}
@@ -272,6 +321,9 @@ func TestLogin(t *testing.T) {
 			url := "/login"
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			require.NoError(t, err)
+			request.RemoteAddr = "203.0.113.7:5000"
+			request.Header.Set("User-Agent", "curl/8.5.0")
+			request.Header.Set("X-Forwarded-For", "198.51.100.1")
 
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(recorder)
@@ -1,7 +1,11 @@
 package api
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
+	"github.com/hisshihi/simple-bank/internal/validate"
 	"github.com/hisshihi/simple-bank/pkg/util"
 )
 
@@ -11,3 +15,35 @@ var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
 	}
 	return false
 }
+
+var validRecurrence validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if recurrence, ok := fieldLevel.Field().Interface().(string); ok {
+		_, err := util.ParseRecurrence(recurrence)
+		return err == nil
+	}
+	return false
+}
+
+// validUsername and validFullName apply the rules the gRPC handlers use, length is left to min and max
+var validUsername validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	return validate.Username(fieldLevel.Field().String()) == nil
+}
+
+var validFullName validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	return validate.FullName(fieldLevel.Field().String()) == nil
+}
+
+// fieldName reports a field under the name the client sent, so field violations
+// say "to_account_id" rather than "ToAccountID"
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,443 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/egress"
+	"github.com/hisshihi/simple-bank/pkg/util"
+)
+
+const webhookSecretPrefix = "whsec_"
+
+type webhookResponse struct {
+	ID           int64      `json:"id"`
+	Url          string     `json:"url"`
+	EventTypes   []string   `json:"event_types"`
+	Status       string     `json:"status"`
+	FailureCount int64      `json:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	// Secret is only returned when the subscription is created
+	Secret string `json:"secret,omitempty"`
+}
+
+func newWebhookResponse(subscription sqlc.WebhookSubscription) webhookResponse {
+	rsp := webhookResponse{
+		ID:           subscription.ID,
+		Url:          subscription.Url,
+		EventTypes:   subscription.EventTypes,
+		Status:       subscription.Status,
+		FailureCount: subscription.FailureCount,
+		CreatedAt:    subscription.CreatedAt,
+	}
+	if subscription.DisabledAt.Valid {
+		rsp.DisabledAt = &subscription.DisabledAt.Time
+	}
+	return rsp
+}
+
+// webhookDeliveryResponse leaves out the payload and the error kept for operators
+type webhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int64      `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ResponseStatus int64      `json:"response_status,omitempty"`
+	ReplayOf       *int64     `json:"replay_of,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func newWebhookDeliveryResponse(delivery sqlc.WebhookDelivery) webhookDeliveryResponse {
+	rsp := webhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.ReplayOf.Valid {
+		rsp.ReplayOf = &delivery.ReplayOf.Int64
+	}
+	if delivery.DeliveredAt.Valid {
+		rsp.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+	return rsp
+}
+
+// webhookAuditChange describes a change of the subscription without its secret
+func webhookAuditChange(before sqlc.WebhookSubscription, after sqlc.WebhookSubscription) sqlc.AuditChange {
+	change := sqlc.AuditChange{TargetType: sqlc.AuditTargetWebhook}
+	if before.ID != 0 {
+		change.TargetID = strconv.FormatInt(before.ID, 10)
+		change.Before = newWebhookResponse(before)
+	}
+	if after.ID != 0 {
+		change.TargetID = strconv.FormatInt(after.ID, 10)
+		change.After = newWebhookResponse(after)
+	}
+	return change
+}
+
+type createWebhookRequest struct {
+	Url        string   `json:"url" binding:"required,http_url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=transfer.received transfer.sent account.created"`
+}
+
+func (server *Server) createWebhook(ctx *gin.Context) {
+	var req createWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+
+	if err := server.checkWebhookURL(ctx, req.Url); err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	var subscription sqlc.WebhookSubscription
+	err = server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "webhook.create"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		subscription, err = q.CreateWebhookSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+			Owner:      authPayload.Username,
+			Url:        req.Url,
+			EventTypes: req.EventTypes,
+			Secret:     secret,
+		})
+		return webhookAuditChange(sqlc.WebhookSubscription{}, subscription), err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	rsp := newWebhookResponse(subscription)
+	rsp.Secret = subscription.Secret
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) listWebhooks(ctx *gin.Context) {
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return
+	}
+
+	subscriptions, err := server.store.ListWebhookSubscriptions(ctx, authPayload.Username)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	rsp := make([]webhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		rsp = append(rsp, newWebhookResponse(subscription))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type webhookURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	subscription, valid := server.ownWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+type updateWebhookRequest struct {
+	Url        *string  `json:"url" binding:"omitempty,http_url"`
+	EventTypes []string `json:"event_types" binding:"omitempty,min=1,dive,oneof=transfer.received transfer.sent account.created"`
+	// Status can only turn a disabled endpoint back on, which also resets its failure count
+	Status *string `json:"status" binding:"omitempty,oneof=active"`
+}
+
+func (server *Server) updateWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req updateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	if req.Url != nil {
+		if err := server.checkWebhookURL(ctx, *req.Url); err != nil {
+			respondError(ctx, err)
+			return
+		}
+	}
+
+	subscription, valid := server.ownWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	arg := sqlc.UpdateWebhookSubscriptionParams{
+		ID:         subscription.ID,
+		EventTypes: req.EventTypes,
+	}
+	if req.Url != nil {
+		arg.Url = sql.NullString{String: *req.Url, Valid: true}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	before := subscription
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "webhook.update"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		subscription, err = q.UpdateWebhookSubscription(ctx, arg)
+		if err != nil {
+			return sqlc.AuditChange{}, err
+		}
+
+		if req.Status != nil && subscription.Status != sqlc.WebhookActive {
+			subscription, err = q.EnableWebhookSubscription(ctx, subscription.ID)
+		}
+		return webhookAuditChange(before, subscription), err
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+func (server *Server) deleteWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	subscription, valid := server.ownWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+
+	err := server.store.AuditTx(server.auditContext(ctx, authPayload.Username, "webhook.delete"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		return webhookAuditChange(subscription, sqlc.WebhookSubscription{}), q.DeleteWebhookSubscription(ctx, subscription.ID)
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type listWebhookDeliveriesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	var req listWebhookDeliveriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	subscription, valid := server.ownWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          int64(req.PageSize),
+		Offset:         int64((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	rsp := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		rsp = append(rsp, newWebhookDeliveryResponse(delivery))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type webhookDeliveryURI struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// replayWebhookDelivery queues the same event again, the receiver sees the original event id
+func (server *Server) replayWebhookDelivery(ctx *gin.Context) {
+	var uri webhookDeliveryURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	subscription, valid := server.ownWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	original, err := server.store.GetWebhookDelivery(ctx, uri.DeliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("webhook_delivery"))
+			return
+		}
+		internalError(ctx, err)
+		return
+	}
+	if original.SubscriptionID != subscription.ID {
+		respondError(ctx, apperr.NotFound("delivery_webhook"))
+		return
+	}
+
+	delivery, err := server.store.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		SubscriptionID: subscription.ID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		ReplayOf:       sql.NullInt64{Int64: original.ID, Valid: true},
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newWebhookDeliveryResponse(delivery))
+}
+
+// testWebhook queues a webhook.test event, so integrators can check their endpoint and signature code
+func (server *Server) testWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, apperr.Validation(err))
+		return
+	}
+
+	subscription, valid := server.ownWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	event := sqlc.NewWebhookEvent(sqlc.WebhookTest, gin.H{"subscription_id": subscription.ID})
+	payload, err := json.Marshal(event)
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	delivery, err := server.store.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+	})
+	if err != nil {
+		internalError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newWebhookDeliveryResponse(delivery))
+}
+
+// ownWebhook loads the subscription and checks it belongs to the authenticated user
+func (server *Server) ownWebhook(ctx *gin.Context, id int64) (sqlc.WebhookSubscription, bool) {
+	subscription, err := server.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, apperr.NotFound("webhook"))
+			return subscription, false
+		}
+		internalError(ctx, err)
+		return subscription, false
+	}
+
+	authPayload, ok := ctx.MustGet(authorizationPayloadKey).(*util.Payload)
+	if !ok {
+		respondError(ctx, apperr.Unauthenticated("required"))
+		return subscription, false
+	}
+	if subscription.Owner != authPayload.Username {
+		respondError(ctx, apperr.Forbidden("webhook"))
+		return subscription, false
+	}
+
+	return subscription, true
+}
+
+// checkWebhookURL refuses endpoints inside the bank's network, the dispatcher checks the
+// address again when it connects
+func (server *Server) checkWebhookURL(ctx context.Context, raw string) error {
+	err := egress.CheckURL(ctx, server.resolver, raw)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, egress.ErrForbiddenAddress) {
+		return apperr.Invalid("webhook_not_public")
+	}
+	return apperr.Invalid("webhook_unresolvable")
+}
+
+func newWebhookSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(key), nil
+}
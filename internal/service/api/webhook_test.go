@@ -0,0 +1,420 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomWebhook(owner string) sqlc.WebhookSubscription {
+	return sqlc.WebhookSubscription{
+		ID:         7,
+		Owner:      owner,
+		Url:        "https://example.com/hooks",
+		EventTypes: []string{sqlc.WebhookTransferReceived},
+		Secret:     webhookSecretPrefix + "secret",
+		Status:     sqlc.WebhookActive,
+	}
+}
+
+func TestCreateWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	webhook := randomWebhook(user.Username)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"url":         webhook.Url,
+				"event_types": webhook.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg sqlc.CreateWebhookSubscriptionParams) (sqlc.WebhookSubscription, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, webhook.EventTypes, arg.EventTypes)
+						require.True(t, strings.HasPrefix(arg.Secret, webhookSecretPrefix))
+						webhook.Secret = arg.Secret
+						return webhook, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp webhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, webhook.ID, rsp.ID)
+				require.Equal(t, webhook.Secret, rsp.Secret)
+			},
+		},
+		{
+			name: "UnknownEventType",
+			body: gin.H{
+				"url":         webhook.Url,
+				"event_types": []string{"account.deleted"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PrivateURL",
+			body: gin.H{
+				"url":         "http://169.254.169.254/latest/meta-data",
+				"event_types": webhook.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "HostResolvesToPrivateAddress",
+			body: gin.H{
+				"url":         "https://internal.example.com/hooks",
+				"event_types": webhook.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: gin.H{
+				"url":         "ftp://example.com",
+				"event_types": webhook.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetWebhookHidesSecret(t *testing.T) {
+	user, _ := randomUser(t)
+	webhook := randomWebhook(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/webhooks/%d", webhook.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), webhook.Secret)
+}
+
+func TestUpdateWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	webhook := randomWebhook(user.Username)
+	disabled := webhook
+	disabled.Status = sqlc.WebhookDisabled
+	disabled.FailureCount = 20
+
+	testCases := []struct {
+		name          string
+		body          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "ChangeEventTypes",
+			body:     `{"event_types": ["transfer.sent"]}`,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+				arg := sqlc.UpdateWebhookSubscriptionParams{
+					ID:         webhook.ID,
+					EventTypes: []string{sqlc.WebhookTransferSent},
+				}
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), gomock.Eq(arg)).Times(1).Return(webhook, nil)
+				store.EXPECT().EnableWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Reenable",
+			body:     `{"status": "active"}`,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(disabled, nil)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).Return(disabled, nil)
+				store.EXPECT().EnableWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp webhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, sqlc.WebhookActive, rsp.Status)
+			},
+		},
+		{
+			name:     "CannotDisable",
+			body:     `{"status": "disabled"}`,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "PrivateURL",
+			body:     `{"url": "http://localhost:8080/hooks"}`,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherOwner",
+			body:     `{"event_types": ["transfer.sent"]}`,
+			username: "intruder",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+				store.EXPECT().UpdateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/webhooks/%d", webhook.ID)
+			request, err := http.NewRequest(http.MethodPatch, url, strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListWebhookDeliveriesHidesErrors(t *testing.T) {
+	user, _ := randomUser(t)
+	webhook := randomWebhook(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+	store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).
+		Return([]sqlc.WebhookDelivery{{
+			ID:             1,
+			SubscriptionID: webhook.ID,
+			EventID:        "event-1",
+			EventType:      sqlc.WebhookTransferReceived,
+			Payload:        json.RawMessage(`{"id":"event-1"}`),
+			Status:         sqlc.WebhookDeliveryPending,
+			ResponseStatus: http.StatusInternalServerError,
+			LastError:      "endpoint responded with status 500",
+		}}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/webhooks/%d/deliveries?page_id=1&page_size=5", webhook.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "last_error")
+	require.NotContains(t, recorder.Body.String(), "payload")
+
+	var rsp []webhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Len(t, rsp, 1)
+	require.Equal(t, int64(http.StatusInternalServerError), rsp[0].ResponseStatus)
+}
+
+func TestReplayWebhookDeliveryAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	webhook := randomWebhook(user.Username)
+
+	original := sqlc.WebhookDelivery{
+		ID:             3,
+		SubscriptionID: webhook.ID,
+		EventID:        "event-1",
+		EventType:      sqlc.WebhookTransferReceived,
+		Payload:        json.RawMessage(`{"id":"event-1"}`),
+		Status:         sqlc.WebhookDeliveryFailed,
+	}
+	foreign := original
+	foreign.SubscriptionID = webhook.ID + 1
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				arg := sqlc.CreateWebhookDeliveryParams{
+					SubscriptionID: webhook.ID,
+					EventID:        original.EventID,
+					EventType:      original.EventType,
+					Payload:        original.Payload,
+					ReplayOf:       sql.NullInt64{Int64: original.ID, Valid: true},
+				}
+				store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Eq(arg)).Times(1).Return(sqlc.WebhookDelivery{ID: 4}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "DeliveryOfOtherWebhook",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(foreign, nil)
+				store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/webhooks/%d/deliveries/%d/replay", webhook.ID, original.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTestWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	webhook := randomWebhook(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+	store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, arg sqlc.CreateWebhookDeliveryParams) (sqlc.WebhookDelivery, error) {
+			require.Equal(t, webhook.ID, arg.SubscriptionID)
+			require.Equal(t, sqlc.WebhookTest, arg.EventType)
+
+			var event sqlc.WebhookEvent
+			require.NoError(t, json.Unmarshal(arg.Payload, &event))
+			require.Equal(t, arg.EventID, event.ID)
+			return sqlc.WebhookDelivery{ID: 1}, nil
+		})
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/webhooks/%d/test", webhook.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+}
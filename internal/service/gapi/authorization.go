@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/logging"
+	"github.com/hisshihi/simple-bank/pkg/util"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+)
+
+// authorizeUser verifies the access token sent in the authorization metadata, the error is an *apperr.Error
+func (server *Server) authorizeUser(ctx context.Context) (*util.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, apperr.Unauthenticated("missing_metadata")
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, apperr.Unauthenticated("missing_authorization")
+	}
+
+	payload, err := server.verifyAuthorization(values[0])
+	if err != nil {
+		return nil, err
+	}
+
+	logging.SetUser(ctx, payload.Username)
+	return payload, nil
+}
+
+// verifyAuthorization checks a "Bearer <token>" header value
+func (server *Server) verifyAuthorization(header string) (*util.Payload, error) {
+	fields := strings.Fields(header)
+	if len(fields) != 2 {
+		return nil, apperr.Unauthenticated("authorization_format")
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != authorizationBearer {
+		return nil, apperr.Unauthenticated("authorization_type", authType)
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		// ошибка токена получает тот же код и текст, что и в Gin API
+		return nil, apperr.From(err)
+	}
+
+	return payload, nil
+}
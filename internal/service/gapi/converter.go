@@ -15,3 +15,24 @@ func convertUser(user sqlc.User) *pb.User {
 		CreatedAt:         timestamppb.New(user.CreatedAt),
 	}
 }
+
+func convertAccount(account sqlc.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance,
+		Currency:         account.Currency,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+	}
+}
+
+func convertEntry(entry sqlc.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:         entry.ID,
+		AccountId:  entry.AccountID,
+		Amount:     entry.Amount,
+		TransferId: entry.TransferID.Int64,
+		CreatedAt:  timestamppb.New(entry.CreatedAt),
+	}
+}
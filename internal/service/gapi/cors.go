@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/i18n"
+	"github.com/hisshihi/simple-bank/internal/logging"
+)
+
+// CORS answers the same preflights and sends the same headers as the Gin API
+var (
+	corsAllowMethods  = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsAllowHeaders  = []string{"Origin", "Content-Length", "Content-Type", "Authorization", i18n.AcceptLanguageHeader, logging.RequestIDHeader}
+	corsExposeHeaders = []string{"Content-Length", "Content-Language", "Retry-After", logging.RequestIDHeader}
+	corsMaxAge        = 12 * time.Hour
+)
+
+// CORSHandler lets browsers on CORS_ALLOWED_ORIGINS call the gateway, next is returned as is
+// when no origin is allowed
+func CORSHandler(config config.Config, next http.Handler) http.Handler {
+	origins := config.CORSOrigins()
+	if len(origins) == 0 {
+		return next
+	}
+	anyOrigin := config.AllowsAnyOrigin()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if !anyOrigin && !slices.Contains(origins, origin) {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			// без заголовков CORS браузер сам не отдаст ответ странице
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if anyOrigin {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if config.CORSAllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(corsAllowMethods, ","))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsAllowHeaders, ","))
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposeHeaders, ","))
+		next.ServeHTTP(w, r)
+	})
+}
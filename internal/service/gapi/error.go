@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/i18n"
+	"github.com/hisshihi/simple-bank/internal/logging"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts err into a gRPC status with error details, an internal error is logged
+// and the client only gets a generic message
+func statusError(ctx context.Context, err error) error {
+	e := apperr.From(err)
+	if e.Code == apperr.CodeInternal {
+		slog.ErrorContext(ctx, "call failed", "error", e.Err)
+	}
+	return e.Status(i18n.Locale(ctx), logging.RequestID(ctx)).Err()
+}
+
+// internalError logs err with msg and returns a generic internal status, database errors are not for the client
+func internalError(ctx context.Context, msg string, err error) error {
+	return statusError(ctx, apperr.Internal(fmt.Errorf("%s: %w", msg, err)))
+}
+
+// ProblemErrorHandler renders the gateway's errors as the problem details the Gin API returns
+func ProblemErrorHandler(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(w, r, apperr.FromStatus(status.Convert(err)))
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, e *apperr.Error) {
+	// ошибка из статуса уже залогирована в statusError
+	if e.Code == apperr.CodeInternal && e.Err != nil {
+		slog.ErrorContext(r.Context(), "request failed", "error", e.Err)
+	}
+
+	// маршрут мог не найтись, тогда middleware не запускались и язык берётся из заголовка
+	locale := i18n.Negotiate(r.Header.Get(i18n.AcceptLanguageHeader))
+	problem := e.Problem(locale, r.URL.Path, logging.RequestID(r.Context()))
+	w.Header().Set("Content-Type", apperr.ProblemContentType)
+	w.Header().Set("Content-Language", locale)
+	if seconds := e.RetryAfterSeconds(); seconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
@@ -3,41 +3,60 @@ package gapi
 import (
 	"context"
 
+	"github.com/google/uuid"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/clientip"
+	"github.com/hisshihi/simple-bank/internal/logging"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/peer"
 )
 
 const (
 	grpcGetwayUserAgentHeader = "grpcgateway-user-agent"
 	userAgentHeader           = "user-agent"
-	xFormatdedForHeader       = "x-forwarded-for"
+	requestIDHeader           = "x-request-id"
 )
 
 type Metadata struct {
 	UserAgent string
 	ClientIP  string
+	RequestID string
 }
 
 func (server *Server) extractMetadata(ctx context.Context) *Metadata {
-	mtdt := &Metadata{}
+	// id, присвоенный перехватчиком или middleware шлюза, уже есть в логах запроса
+	mtdt := &Metadata{RequestID: logging.RequestID(ctx)}
 
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// у вызова через шлюз user-agent браузера, у прямого вызова — клиента gRPC
 		if userAgents := md.Get(grpcGetwayUserAgentHeader); len(userAgents) > 0 {
 			mtdt.UserAgent = userAgents[0]
-		}
-
-		if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+		} else if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
 			mtdt.UserAgent = userAgents[0]
 		}
 
-		if clientIPs := md.Get(xFormatdedForHeader); len(clientIPs) > 0 {
-			mtdt.ClientIP = clientIPs[0]
+		if requestIDs := md.Get(requestIDHeader); len(requestIDs) > 0 && mtdt.RequestID == "" {
+			mtdt.RequestID = requestIDs[0]
 		}
 	}
 
-	if p, ok := peer.FromContext(ctx); ok {
-		mtdt.ClientIP = p.Addr.String()
+	if mtdt.RequestID == "" {
+		mtdt.RequestID = uuid.NewString()
 	}
 
+	// адрес уже разобран перехватчиком или middleware шлюза с учётом доверенных прокси
+	mtdt.ClientIP = clientip.FromContext(ctx)
+
 	return mtdt
 }
+
+// auditContext returns the context to pass to the store, so the change made by actor is
+// written to the audit log in the same transaction
+func (server *Server) auditContext(ctx context.Context, mtdt *Metadata, actor, action string) context.Context {
+	return sqlc.WithAudit(ctx, sqlc.AuditRecord{
+		Actor:     actor,
+		Action:    action,
+		RequestID: mtdt.RequestID,
+		ClientIP:  mtdt.ClientIP,
+		UserAgent: mtdt.UserAgent,
+	})
+}
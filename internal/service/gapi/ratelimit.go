@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/clientip"
+	"github.com/hisshihi/simple-bank/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RateLimitUnaryInterceptor limits calls per full method name, such as "/pb.SimpleBank/LoginUser".
+// It must run after the i18n and client IP interceptors, so the error is in the caller's locale
+// and anonymous callers are told apart
+func (server *Server) RateLimitUnaryInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		result := limiter.Allow(ctx, info.FullMethod, server.callIdentity(ctx))
+		if !result.Allowed {
+			return nil, statusError(ctx, apperr.RateLimited(result.RetryAfter))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor limits opening streams the way RateLimitUnaryInterceptor limits calls
+func (server *Server) RateLimitStreamInterceptor(limiter *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		result := limiter.Allow(ctx, info.FullMethod, server.callIdentity(ctx))
+		if !result.Allowed {
+			return statusError(ctx, apperr.RateLimited(result.RetryAfter))
+		}
+		return handler(srv, stream)
+	}
+}
+
+// RateLimitGatewayMiddleware limits gateway requests per method and path pattern, such as
+// "POST /v1/login_user", after the client IP middleware. The gateway calls the server in-process,
+// so the interceptors don't see them
+func (server *Server) RateLimitGatewayMiddleware(limiter *ratelimit.Limiter) runtime.Middleware {
+	return func(next runtime.HandlerFunc) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			// HTTPPathPattern выставляется уже внутри обработчика, а сам шаблон доступен раньше
+			pattern, _ := runtime.HTTPPattern(r.Context())
+			result := limiter.Allow(r.Context(), r.Method+" "+pattern.String(), server.requestIdentity(r))
+			if !result.Allowed {
+				writeProblem(w, r, apperr.RateLimited(result.RetryAfter))
+				return
+			}
+			next(w, r, pathParams)
+		}
+	}
+}
+
+// callIdentity is the user of a valid access token, or the client IP for anonymous calls
+func (server *Server) callIdentity(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationHeader); len(values) > 0 {
+			if payload, err := server.verifyAuthorization(values[0]); err == nil {
+				return "user:" + payload.Username
+			}
+		}
+	}
+	return "ip:" + clientip.FromContext(ctx)
+}
+
+// requestIdentity is callIdentity for a gateway request
+func (server *Server) requestIdentity(r *http.Request) string {
+	if payload, err := server.verifyAuthorization(r.Header.Get(authorizationHeader)); err == nil {
+		return "user:" + payload.Username
+	}
+	return "ip:" + clientip.FromContext(r.Context())
+}
@@ -4,17 +4,21 @@ import (
 	"context"
 
 	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/validate"
 	"github.com/hisshihi/simple-bank/pb"
 	"github.com/hisshihi/simple-bank/pkg/util"
 	"github.com/lib/pq"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	if err := validateCreateUserRequest(req); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
 	hashedPassword, err := util.HashPassword(req.GetPassword())
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
+		return nil, internalError(ctx, "failed to hash password", err)
 	}
 
 	arg := sqlc.CreateUserParams{
@@ -24,15 +28,17 @@ func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 		Email:          req.GetEmail(),
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	auditCtx := server.auditContext(ctx, server.extractMetadata(ctx), arg.Username, "user.register")
+
+	user, err := server.store.CreateUserTx(auditCtx, arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "unique_violation":
-				return nil, status.Errorf(codes.AlreadyExists, "user already exists: %v", err)
+				return nil, statusError(ctx, apperr.AlreadyExists("user"))
 			}
 		}
-		return nil, status.Errorf(codes.Internal, "failed to create user: %v", err)
+		return nil, internalError(ctx, "failed to create user", err)
 	}
 
 	rsp := &pb.CreateUserResponse{
@@ -41,3 +47,12 @@ func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 
 	return rsp, nil
 }
+
+func validateCreateUserRequest(req *pb.CreateUserRequest) error {
+	var violations apperr.Violations
+	violations.Check("username", validate.Username(req.GetUsername()))
+	violations.Check("full_name", validate.FullName(req.GetFullName()))
+	violations.Check("email", validate.Email(req.GetEmail()))
+	violations.Check("password", validate.Password(req.GetPassword()))
+	return violations.Err()
+}
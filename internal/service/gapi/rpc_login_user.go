@@ -5,35 +5,43 @@ import (
 	"database/sql"
 
 	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/validate"
 	"github.com/hisshihi/simple-bank/pb"
 	"github.com/hisshihi/simple-bank/pkg/util"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// errInvalidCredentials answers a login with an unknown username or a wrong password alike
+var errInvalidCredentials = apperr.Unauthenticated("credentials")
+
 func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	if err := validateLoginUserRequest(req); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
+		// неизвестное имя и неверный пароль неотличимы, чтобы по ответу нельзя было перебирать пользователей
 		if err == sql.ErrNoRows {
-			return nil, status.Errorf(codes.NotFound, "user not found: %v", err)
+			return nil, statusError(ctx, errInvalidCredentials)
 		}
-		return nil, status.Errorf(codes.Internal, "failed to load user from database: %v", err)
+		return nil, internalError(ctx, "failed to load user from database", err)
 	}
 
 	err = util.CheckPassword(user.HashedPassword, req.Password)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed password: %v", err)
+		return nil, statusError(ctx, errInvalidCredentials)
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccesTokenDuration)
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.AccesTokenDuration)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create access token: %v", err)
+		return nil, internalError(ctx, "failed to create access token", err)
 	}
 
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.RefreshTokenDuration)
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, server.config.RefreshTokenDuration)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %v", err)
+		return nil, internalError(ctx, "failed to create refresh token", err)
 	}
 
 	mtdt := server.extractMetadata(ctx)
@@ -47,9 +55,14 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		ExpiresAt:    refreshPayload.ExpiredAt,
 	}
 
-	session, err := server.store.CreateSession(ctx, argSession)
+	var session sqlc.Session
+	err = server.store.AuditTx(server.auditContext(ctx, mtdt, user.Username, "user.login"), func(q sqlc.Querier) (sqlc.AuditChange, error) {
+		var err error
+		session, err = q.CreateSession(ctx, argSession)
+		return sqlc.SessionAuditChange(session), err
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create session: %v", err)
+		return nil, internalError(ctx, "failed to create session", err)
 	}
 
 	rsp := &pb.LoginUserResponse{
@@ -63,3 +76,11 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 
 	return rsp, nil
 }
+
+// validateLoginUserRequest only checks the lengths, users registered before the rules keep logging in
+func validateLoginUserRequest(req *pb.LoginUserRequest) error {
+	var violations apperr.Violations
+	violations.Check("username", validate.Length(req.GetUsername(), 1, 100))
+	violations.Check("password", validate.Length(req.GetPassword(), 6, 72))
+	return violations.Err()
+}
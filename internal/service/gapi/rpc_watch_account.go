@@ -0,0 +1,228 @@
+package gapi
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/validate"
+	"github.com/hisshihi/simple-bank/pb"
+	"google.golang.org/grpc"
+)
+
+const (
+	// watchBatchSize is how many new entries of one account are read at a time
+	watchBatchSize = 100
+	// maxWatchedAccounts bounds the accounts listed when the caller asks for all of them
+	maxWatchedAccounts = 100
+)
+
+func (server *Server) WatchAccount(req *pb.WatchAccountRequest, stream grpc.ServerStreamingServer[pb.WatchAccountResponse]) error {
+	ctx := stream.Context()
+
+	payload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return statusError(ctx, err)
+	}
+
+	if err := validateWatchAccountRequest(req); err != nil {
+		return statusError(ctx, err)
+	}
+	resume, err := parseResumeToken(req.GetResumeToken())
+	if err != nil {
+		return statusError(ctx, apperr.Invalid("resume_token"))
+	}
+
+	accountIDs, err := server.watchedAccounts(ctx, payload.Username, req.GetAccountIds())
+	if err != nil {
+		return err
+	}
+
+	cursors := newWatchCursors(accountIDs, req.GetAfterEntryId(), resume)
+	return server.watchAccounts(ctx, accountIDs, cursors, stream.Send)
+}
+
+func validateWatchAccountRequest(req *pb.WatchAccountRequest) error {
+	var violations apperr.Violations
+	for i, id := range req.GetAccountIds() {
+		violations.Check(fmt.Sprintf("account_ids[%d]", i), validate.ID(id))
+	}
+	violations.Check("after_entry_id", validate.NotNegative(req.GetAfterEntryId()))
+	return violations.Err()
+}
+
+// watchedAccounts checks that the owner may watch the accounts, an empty list means all the owner's accounts
+func (server *Server) watchedAccounts(ctx context.Context, owner string, accountIDs []int64) ([]int64, error) {
+	if len(accountIDs) == 0 {
+		accounts, err := server.store.ListAccounts(ctx, sqlc.ListAccountsParams{
+			Owner: owner,
+			Limit: maxWatchedAccounts,
+		})
+		if err != nil {
+			return nil, internalError(ctx, "failed to list accounts", err)
+		}
+		if len(accounts) == 0 {
+			return nil, statusError(ctx, apperr.NotFound("user_accounts"))
+		}
+
+		for _, account := range accounts {
+			accountIDs = append(accountIDs, account.ID)
+		}
+		return accountIDs, nil
+	}
+
+	accountIDs = slices.Compact(slices.Sorted(slices.Values(accountIDs)))
+	if len(accountIDs) > maxWatchedAccounts {
+		return nil, statusError(ctx, apperr.Invalid("too_many_accounts", maxWatchedAccounts))
+	}
+
+	for _, id := range accountIDs {
+		account, err := server.store.GetAccount(ctx, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, statusError(ctx, apperr.NotFound("account_id", id))
+			}
+			return nil, internalError(ctx, "failed to get account", err)
+		}
+		if account.Owner != owner {
+			return nil, statusError(ctx, apperr.Forbidden("account_id", id))
+		}
+	}
+	return accountIDs, nil
+}
+
+// newWatchCursors starts every account after afterID unless the resume token has its own position
+func newWatchCursors(accountIDs []int64, afterID int64, resume map[int64]int64) map[int64]int64 {
+	cursors := make(map[int64]int64, len(accountIDs))
+	for _, id := range accountIDs {
+		cursor, ok := resume[id]
+		if !ok {
+			cursor = afterID
+		}
+		cursors[id] = cursor
+	}
+	return cursors
+}
+
+// encodeResumeToken writes the cursors as account_id:entry_id pairs ordered by account
+func encodeResumeToken(cursors map[int64]int64) string {
+	pairs := make([]string, 0, len(cursors))
+	for _, id := range slices.Sorted(maps.Keys(cursors)) {
+		pairs = append(pairs, fmt.Sprintf("%d:%d", id, cursors[id]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// parseResumeToken reads a token made by encodeResumeToken, an empty token has no positions
+func parseResumeToken(token string) (map[int64]int64, error) {
+	cursors := make(map[int64]int64)
+	if token == "" {
+		return cursors, nil
+	}
+
+	pairs := strings.Split(token, ",")
+	if len(pairs) > maxWatchedAccounts {
+		return nil, fmt.Errorf("more than %d accounts", maxWatchedAccounts)
+	}
+	for _, pair := range pairs {
+		account, entry, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid position %q", pair)
+		}
+		accountID, err := strconv.ParseInt(account, 10, 64)
+		if err != nil || accountID < 1 {
+			return nil, fmt.Errorf("invalid account id %q", account)
+		}
+		entryID, err := strconv.ParseInt(entry, 10, 64)
+		if err != nil || entryID < 0 {
+			return nil, fmt.Errorf("invalid entry id %q", entry)
+		}
+		cursors[accountID] = entryID
+	}
+	return cursors, nil
+}
+
+// watchAccounts sends the entries of the accounts past their cursors, then keeps sending new ones
+// as the hub reports changes, until ctx is cancelled or send fails. Every response carries the
+// cursors of all the accounts, a single entry id can't resume several accounts
+func (server *Server) watchAccounts(ctx context.Context, accountIDs []int64, cursors map[int64]int64, send func(*pb.WatchAccountResponse) error) error {
+	if server.hub == nil {
+		return statusError(ctx, apperr.New(apperr.CodeUnavailable, "notifications_disabled"))
+	}
+
+	// подписываемся до первого чтения, чтобы не пропустить записи между чтением и подпиской
+	changed, unsubscribe := server.hub.Subscribe(accountIDs)
+	defer unsubscribe()
+
+	for {
+		more, err := server.sendNewEntries(ctx, accountIDs, cursors, send)
+		if err != nil {
+			return err
+		}
+		if more {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-changed:
+			if !ok {
+				return statusError(ctx, apperr.New(apperr.CodeUnavailable, "shutting_down"))
+			}
+		}
+	}
+}
+
+// sendNewEntries sends the entries past the cursors in id order and moves the cursor of each
+// account as its entries are sent, more reports that an account had more entries than one batch
+func (server *Server) sendNewEntries(ctx context.Context, accountIDs []int64, cursors map[int64]int64, send func(*pb.WatchAccountResponse) error) (more bool, err error) {
+	var updates []*pb.WatchAccountResponse
+	for _, id := range accountIDs {
+		entries, err := server.store.ListEntriesAfter(ctx, sqlc.ListEntriesAfterParams{
+			AccountID: id,
+			AfterID:   cursors[id],
+			RowLimit:  watchBatchSize,
+		})
+		if err != nil {
+			return false, internalError(ctx, "failed to list entries", err)
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		more = more || len(entries) == watchBatchSize
+
+		account, err := server.store.GetAccount(ctx, id)
+		if err != nil {
+			return false, internalError(ctx, "failed to get account", err)
+		}
+
+		for _, entry := range entries {
+			updates = append(updates, &pb.WatchAccountResponse{
+				Entry:   convertEntry(entry),
+				Account: convertAccount(account),
+			})
+		}
+	}
+
+	slices.SortFunc(updates, func(a, b *pb.WatchAccountResponse) int {
+		return cmp.Compare(a.GetEntry().GetId(), b.GetEntry().GetId())
+	})
+	// id записей растут в порядке коммитов только в пределах счёта: запись другого счёта с меньшим id
+	// может появиться позже, поэтому позиция своя у каждого счёта
+	for _, update := range updates {
+		cursors[update.GetEntry().GetAccountId()] = update.GetEntry().GetId()
+		update.ResumeToken = encodeResumeToken(cursors)
+		if err := send(update); err != nil {
+			return false, err
+		}
+	}
+	return more, nil
+}
@@ -0,0 +1,121 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/watch"
+	"github.com/hisshihi/simple-bank/pb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestServer(t *testing.T, store sqlc.Store, hub *watch.Hub) *Server {
+	config := config.Config{
+		TokenSymmetricKey: gofakeit.Password(true, true, true, true, false, 32),
+	}
+
+	server, err := NewServer(config, store, hub)
+	require.NoError(t, err)
+	return server
+}
+
+func expectEntries(store *mockdb.MockStore, accountID int64, afterID int64, entries ...sqlc.Entry) *gomock.Call {
+	arg := sqlc.ListEntriesAfterParams{
+		AccountID: accountID,
+		AfterID:   afterID,
+		RowLimit:  watchBatchSize,
+	}
+	return store.EXPECT().ListEntriesAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+}
+
+// Entry ids only grow in commit order within an account: entry 9 of account 2 commits after
+// entry 10 of account 1. A stream resumed from a single entry id would skip it.
+func TestWatchAccountsResumesEveryAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	hub := watch.NewHub()
+	defer hub.Close()
+	server := newTestServer(t, store, hub)
+
+	accountIDs := []int64{1, 2}
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, id int64) (sqlc.Account, error) {
+			return sqlc.Account{ID: id}, nil
+		})
+
+	gomock.InOrder(
+		expectEntries(store, 1, 0, sqlc.Entry{ID: 10, AccountID: 1}),
+		expectEntries(store, 2, 0),
+		expectEntries(store, 1, 10),
+		expectEntries(store, 2, 0, sqlc.Entry{ID: 9, AccountID: 2}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var tokens []string
+	err := server.watchAccounts(ctx, accountIDs, newWatchCursors(accountIDs, 0, nil), func(rsp *pb.WatchAccountResponse) error {
+		tokens = append(tokens, rsp.GetResumeToken())
+		switch len(tokens) {
+		case 1:
+			require.Equal(t, int64(10), rsp.GetEntry().GetId())
+			hub.Notify(2)
+		case 2:
+			require.Equal(t, int64(9), rsp.GetEntry().GetId())
+			cancel()
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"1:10,2:0", "1:10,2:9"}, tokens)
+
+	// клиент отключился после первой записи: запись 9 придёт после переподключения
+	resume, err := parseResumeToken(tokens[0])
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		expectEntries(store, 1, 10),
+		expectEntries(store, 2, 0, sqlc.Entry{ID: 9, AccountID: 2}),
+	)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	var resumed []int64
+	err = server.watchAccounts(ctx, accountIDs, newWatchCursors(accountIDs, 0, resume), func(rsp *pb.WatchAccountResponse) error {
+		resumed = append(resumed, rsp.GetEntry().GetId())
+		cancel()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{9}, resumed)
+}
+
+func TestResumeToken(t *testing.T) {
+	cursors := map[int64]int64{12: 40, 3: 0, 7: 15}
+	token := encodeResumeToken(cursors)
+	require.Equal(t, "3:0,7:15,12:40", token)
+
+	parsed, err := parseResumeToken(token)
+	require.NoError(t, err)
+	require.Equal(t, cursors, parsed)
+
+	// счёт без позиции в токене начинается с after_entry_id, чужие счета не добавляются
+	require.Equal(t, map[int64]int64{3: 0, 5: 20}, newWatchCursors([]int64{3, 5}, 20, parsed))
+
+	parsed, err = parseResumeToken("")
+	require.NoError(t, err)
+	require.Empty(t, parsed)
+
+	for _, invalid := range []string{"12", "0:5", "3:-1", "a:1", "3:1,"} {
+		_, err := parseResumeToken(invalid)
+		require.Error(t, err, invalid)
+	}
+}
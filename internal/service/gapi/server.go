@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/hisshihi/simple-bank/db/sqlc"
 	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/watch"
 	"github.com/hisshihi/simple-bank/pb"
 	"github.com/hisshihi/simple-bank/pkg/util"
 )
@@ -16,10 +17,11 @@ type Server struct {
 	store      sqlc.Store
 	tokenMaker util.Maker
 	router     *gin.Engine
+	hub        *watch.Hub
 }
 
-// NewServer creates a new gRPC server
-func NewServer(config config.Config, store sqlc.Store) (*Server, error) {
+// NewServer creates a new gRPC server, WatchAccount streams the changes reported by hub
+func NewServer(config config.Config, store sqlc.Store, hub *watch.Hub) (*Server, error) {
 	tokenMaker, err := util.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
@@ -28,6 +30,7 @@ func NewServer(config config.Config, store sqlc.Store) (*Server, error) {
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		hub:        hub,
 	}
 
 	return server, nil
@@ -0,0 +1,126 @@
+package gapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hisshihi/simple-bank/internal/apperr"
+	"github.com/hisshihi/simple-bank/internal/logging"
+	"github.com/hisshihi/simple-bank/pb"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// sseKeepAliveInterval keeps proxies from closing an idle event stream
+const sseKeepAliveInterval = 30 * time.Second
+
+// WatchAccountSSE serves WatchAccount to HTTP clients as server-sent events.
+// The in-process gateway can't proxy streaming calls, so it is mounted on the gateway mux by hand.
+// Query: account_id (repeated), after_entry_id and resume_token. Event ids are resume tokens,
+// so a reconnecting EventSource resumes every account from its Last-Event-ID.
+func (server *Server) WatchAccountSSE(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	payload, err := server.verifyAuthorization(r.Header.Get(authorizationHeader))
+	if err != nil {
+		writeProblem(w, r, apperr.From(err))
+		return
+	}
+	logging.SetUser(r.Context(), payload.Username)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeProblem(w, r, apperr.Internal(errors.New("response writer does not support flushing")))
+		return
+	}
+
+	query := r.URL.Query()
+	var accountIDs []int64
+	for _, value := range query["account_id"] {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 1 {
+			writeProblem(w, r, apperr.Invalid("account_id", value))
+			return
+		}
+		accountIDs = append(accountIDs, id)
+	}
+
+	var afterID int64
+	if value := query.Get("after_entry_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 0 {
+			writeProblem(w, r, apperr.Invalid("entry_id", value))
+			return
+		}
+		afterID = id
+	}
+
+	// id событий — токены возобновления, переподключившийся EventSource присылает последний
+	token := query.Get("resume_token")
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		token = lastEventID
+	}
+	resume, err := parseResumeToken(token)
+	if err != nil {
+		writeProblem(w, r, apperr.Invalid("resume_token"))
+		return
+	}
+
+	ctx := r.Context()
+	accountIDs, err = server.watchedAccounts(ctx, payload.Username, accountIDs)
+	if err != nil {
+		writeProblem(w, r, apperr.FromStatus(status.Convert(err)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// записи и keep-alive пишутся из разных горутин
+	var mu sync.Mutex
+	write := func(format string, args ...any) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(sseKeepAliveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := write(": keep-alive\n\n"); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	marshaler := protojson.MarshalOptions{UseProtoNames: true}
+	err = server.watchAccounts(ctx, accountIDs, newWatchCursors(accountIDs, afterID, resume), func(rsp *pb.WatchAccountResponse) error {
+		data, err := marshaler.Marshal(rsp)
+		if err != nil {
+			return err
+		}
+		return write("id: %s\nevent: entry\ndata: %s\n\n", rsp.GetResumeToken(), data)
+	})
+	if err != nil {
+		write("event: error\ndata: %s\n\n", status.Convert(err).Message())
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+)
+
+const (
+	defaultHoldSweepInterval = time.Minute
+	holdSweepBatchSize       = 100
+)
+
+// HoldSweeper periodically releases holds that expired without being captured or voided
+type HoldSweeper struct {
+	config config.Config
+	store  sqlc.Store
+}
+
+func NewHoldSweeper(config config.Config, store sqlc.Store) *HoldSweeper {
+	if config.HoldSweepInterval <= 0 {
+		config.HoldSweepInterval = defaultHoldSweepInterval
+	}
+	return &HoldSweeper{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start sweeps expired holds until ctx is cancelled
+func (sweeper *HoldSweeper) Start(ctx context.Context) {
+	every(ctx, sweeper.config.HoldSweepInterval, func(now time.Time) {
+		if _, err := sweeper.Sweep(ctx, now); err != nil {
+			slog.Error("cannot sweep expired holds", "error", err)
+		}
+	})
+}
+
+// Sweep expires every hold that is past its expiry at now and returns how many were released
+func (sweeper *HoldSweeper) Sweep(ctx context.Context, now time.Time) (int, error) {
+	holds, err := sweeper.store.ListExpiredHolds(ctx, sqlc.ListExpiredHoldsParams{
+		ExpiredBefore: now,
+		RowLimit:      holdSweepBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, hold := range holds {
+		_, err := sweeper.store.ExpireHoldTx(ctx, sqlc.ExpireHoldTxParams{
+			HoldID: hold.ID,
+			Now:    now,
+		})
+		if err != nil {
+			// удержание могли списать или отменить после выборки
+			if !errors.Is(err, sqlc.ErrHoldNotAuthorized) {
+				slog.Error("cannot expire hold", "hold_id", hold.ID, "error", err)
+			}
+			continue
+		}
+		released++
+	}
+
+	return released, nil
+}
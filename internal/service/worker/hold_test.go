@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSweep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	now := time.Now()
+
+	expired := []sqlc.Hold{{ID: 1}, {ID: 2}}
+	store.EXPECT().
+		ListExpiredHolds(gomock.Any(), gomock.Eq(sqlc.ListExpiredHoldsParams{
+			ExpiredBefore: now,
+			RowLimit:      holdSweepBatchSize,
+		})).
+		Times(1).
+		Return(expired, nil)
+
+	store.EXPECT().
+		ExpireHoldTx(gomock.Any(), gomock.Eq(sqlc.ExpireHoldTxParams{HoldID: 1, Now: now})).
+		Times(1).
+		Return(sqlc.HoldTxResult{}, nil)
+	store.EXPECT().
+		ExpireHoldTx(gomock.Any(), gomock.Eq(sqlc.ExpireHoldTxParams{HoldID: 2, Now: now})).
+		Times(1).
+		Return(sqlc.HoldTxResult{}, sqlc.ErrHoldNotAuthorized)
+
+	sweeper := NewHoldSweeper(config.Config{}, store)
+
+	released, err := sweeper.Sweep(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, released)
+}
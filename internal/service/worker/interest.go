@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+)
+
+const (
+	defaultInterestAccrualInterval = time.Hour
+	interestBatchSize              = 100
+)
+
+// InterestAccrualJob accrues daily interest on interest-bearing accounts
+// and pays out the previous month's interest once the month is over
+type InterestAccrualJob struct {
+	config config.Config
+	store  sqlc.Store
+}
+
+func NewInterestAccrualJob(config config.Config, store sqlc.Store) *InterestAccrualJob {
+	if config.InterestAccrualInterval <= 0 {
+		config.InterestAccrualInterval = defaultInterestAccrualInterval
+	}
+	return &InterestAccrualJob{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start posts and accrues interest until ctx is cancelled
+func (job *InterestAccrualJob) Start(ctx context.Context) {
+	every(ctx, job.config.InterestAccrualInterval, func(now time.Time) {
+		// сначала выплачиваем прошлый месяц, чтобы в выплату не попали начисления текущего
+		period := sqlc.InterestPeriod(now).AddDate(0, -1, 0)
+		if _, err := job.Post(ctx, period); err != nil {
+			slog.Error("cannot post interest", "error", err)
+		}
+		if _, err := job.Accrue(ctx, now.UTC().Truncate(24*time.Hour)); err != nil {
+			slog.Error("cannot accrue interest", "error", err)
+		}
+	})
+}
+
+// Accrue accrues one day of interest for date on every interest-bearing account
+// and returns how many accounts accrued
+func (job *InterestAccrualJob) Accrue(ctx context.Context, date time.Time) (int, error) {
+	accrued := 0
+
+	var afterID int64
+	for {
+		accounts, err := job.store.ListInterestBearingAccounts(ctx, sqlc.ListInterestBearingAccountsParams{
+			AfterID:  afterID,
+			RowLimit: interestBatchSize,
+		})
+		if err != nil {
+			return accrued, err
+		}
+
+		for _, account := range accounts {
+			result, err := job.store.AccrueInterestTx(ctx, sqlc.AccrueInterestTxParams{
+				AccountID: account.ID,
+				Date:      date,
+			})
+			if err != nil {
+				if !errors.Is(err, sqlc.ErrInterestAlreadyAccrued) {
+					slog.Error("cannot accrue interest for account", "account_id", account.ID, "error", err)
+				}
+				continue
+			}
+			if result.Accrual.AmountMicros > 0 {
+				accrued++
+			}
+		}
+
+		if len(accounts) < interestBatchSize {
+			return accrued, nil
+		}
+		afterID = accounts[len(accounts)-1].ID
+	}
+}
+
+// Post pays out the interest accrued until the end of period and returns how many accounts were paid
+func (job *InterestAccrualJob) Post(ctx context.Context, period time.Time) (int, error) {
+	posted := 0
+
+	var afterID int64
+	for {
+		accounts, err := job.store.ListAccountsDueInterestPosting(ctx, sqlc.ListAccountsDueInterestPostingParams{
+			MinMicros: sqlc.MicrosPerUnit,
+			Period:    period,
+			AfterID:   afterID,
+			RowLimit:  interestBatchSize,
+		})
+		if err != nil {
+			return posted, err
+		}
+
+		for _, account := range accounts {
+			result, err := job.store.PostInterestTx(ctx, sqlc.PostInterestTxParams{
+				AccountID: account.ID,
+				Period:    period,
+			})
+			if err != nil {
+				if !errors.Is(err, sqlc.ErrInterestPeriodPosted) {
+					slog.Error("cannot post interest for account", "account_id", account.ID, "error", err)
+				}
+				continue
+			}
+			if result.Posting.Amount > 0 {
+				posted++
+			}
+		}
+
+		if len(accounts) < interestBatchSize {
+			return posted, nil
+		}
+		afterID = accounts[len(accounts)-1].ID
+	}
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccrue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	date := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListInterestBearingAccounts(gomock.Any(), gomock.Eq(sqlc.ListInterestBearingAccountsParams{
+			AfterID:  0,
+			RowLimit: interestBatchSize,
+		})).
+		Times(1).
+		Return([]sqlc.Account{{ID: 1}, {ID: 2}}, nil)
+
+	store.EXPECT().
+		AccrueInterestTx(gomock.Any(), gomock.Eq(sqlc.AccrueInterestTxParams{AccountID: 1, Date: date})).
+		Times(1).
+		Return(sqlc.AccrueInterestTxResult{Accrual: sqlc.InterestAccrual{AmountMicros: 82192}}, nil)
+	store.EXPECT().
+		AccrueInterestTx(gomock.Any(), gomock.Eq(sqlc.AccrueInterestTxParams{AccountID: 2, Date: date})).
+		Times(1).
+		Return(sqlc.AccrueInterestTxResult{}, sqlc.ErrInterestAlreadyAccrued)
+
+	job := NewInterestAccrualJob(config.Config{}, store)
+
+	accrued, err := job.Accrue(context.Background(), date)
+	require.NoError(t, err)
+	require.Equal(t, 1, accrued)
+}
+
+func TestPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	period := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListAccountsDueInterestPosting(gomock.Any(), gomock.Eq(sqlc.ListAccountsDueInterestPostingParams{
+			MinMicros: sqlc.MicrosPerUnit,
+			Period:    period,
+			AfterID:   0,
+			RowLimit:  interestBatchSize,
+		})).
+		Times(1).
+		Return([]sqlc.Account{{ID: 1}}, nil)
+
+	store.EXPECT().
+		PostInterestTx(gomock.Any(), gomock.Eq(sqlc.PostInterestTxParams{AccountID: 1, Period: period})).
+		Times(1).
+		Return(sqlc.PostInterestTxResult{Posting: sqlc.InterestPosting{Amount: 12}}, nil)
+
+	job := NewInterestAccrualJob(config.Config{}, store)
+
+	posted, err := job.Post(context.Background(), period)
+	require.NoError(t, err)
+	require.Equal(t, 1, posted)
+}
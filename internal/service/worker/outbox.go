@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/event"
+)
+
+const (
+	defaultOutboxRelayInterval = 5 * time.Second
+	outboxBatchSize            = 100
+	outboxRetryBaseDelay       = 5 * time.Second
+	outboxRetryMaxDelay        = time.Hour
+)
+
+// OutboxRelay publishes committed outbox events. Events of one aggregate are published
+// in the order they were written, a failed event holds back the later ones until it goes through.
+// Every instance runs a relay, but a batch is published under a Postgres advisory lock,
+// so only one of them publishes at a time and the order holds across instances.
+type OutboxRelay struct {
+	config    config.Config
+	store     sqlc.Store
+	publisher event.Publisher
+}
+
+func NewOutboxRelay(config config.Config, store sqlc.Store, publisher event.Publisher) *OutboxRelay {
+	if config.OutboxRelayInterval <= 0 {
+		config.OutboxRelayInterval = defaultOutboxRelayInterval
+	}
+	return &OutboxRelay{
+		config:    config,
+		store:     store,
+		publisher: publisher,
+	}
+}
+
+// Start relays events until ctx is cancelled
+func (relay *OutboxRelay) Start(ctx context.Context) {
+	every(ctx, relay.config.OutboxRelayInterval, func(now time.Time) {
+		for {
+			fetched, err := relay.Relay(ctx, now)
+			if err != nil {
+				slog.Error("cannot relay outbox events", "error", err)
+				return
+			}
+			// полная пачка — в outbox могли остаться события, забираем их сразу
+			if fetched < outboxBatchSize || ctx.Err() != nil {
+				return
+			}
+			now = time.Now()
+		}
+	})
+}
+
+// Relay publishes one batch of events due at now and returns how many events it fetched,
+// none when the relay of another instance is publishing
+func (relay *OutboxRelay) Relay(ctx context.Context, now time.Time) (int, error) {
+	var fetched int
+	locked, err := relay.store.OutboxRelayTx(ctx, func(q sqlc.Querier) error {
+		var err error
+		fetched, err = relay.relay(ctx, q, now)
+		return err
+	})
+	if err == nil && !locked {
+		slog.Debug("outbox is relayed by another instance")
+	}
+	return fetched, err
+}
+
+// relay publishes the batch within the transaction holding the relay lock. If the transaction
+// rolls back, the events it published are published again — delivery is at least once
+func (relay *OutboxRelay) relay(ctx context.Context, q sqlc.Querier, now time.Time) (int, error) {
+	events, err := q.ListDueOutboxEvents(ctx, sqlc.ListDueOutboxEventsParams{
+		Now:      now,
+		RowLimit: outboxBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	blocked := make(map[[2]string]bool)
+	for _, outbox := range events {
+		aggregate := [2]string{outbox.AggregateType, outbox.AggregateID}
+		if blocked[aggregate] {
+			continue
+		}
+
+		err := relay.publisher.Publish(ctx, event.Message{
+			ID:            outbox.ID,
+			AggregateType: outbox.AggregateType,
+			AggregateID:   outbox.AggregateID,
+			Type:          outbox.EventType,
+			Payload:       outbox.Payload,
+			CreatedAt:     outbox.CreatedAt,
+		})
+		if err != nil {
+			blocked[aggregate] = true
+			slog.Error("cannot publish outbox event", "event_id", outbox.ID, "error", err)
+
+			err = q.MarkOutboxEventFailed(ctx, sqlc.MarkOutboxEventFailedParams{
+				ID:            outbox.ID,
+				LastError:     err.Error(),
+				NextAttemptAt: now.Add(backoff(outboxRetryBaseDelay, outboxRetryMaxDelay, outbox.Attempts+1)),
+			})
+			if err != nil {
+				return len(events), err
+			}
+			continue
+		}
+
+		// если отметка не сохранится, событие опубликуется повторно — доставка «хотя бы один раз»
+		if err := q.MarkOutboxEventPublished(ctx, outbox.ID); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
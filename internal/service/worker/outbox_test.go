@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/event"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// failingPublisher fails the events with the given ids and remembers the order of the rest
+type failingPublisher struct {
+	fail      map[int64]bool
+	published []int64
+}
+
+func (publisher *failingPublisher) Publish(ctx context.Context, msg event.Message) error {
+	if publisher.fail[msg.ID] {
+		return errors.New("broker is down")
+	}
+	publisher.published = append(publisher.published, msg.ID)
+	return nil
+}
+
+// expectRelayLock makes OutboxRelayTx run fn on the mock, as if the relay lock was taken
+func expectRelayLock(store *mockdb.MockStore) {
+	store.EXPECT().
+		OutboxRelayTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, fn func(q sqlc.Querier) error) (bool, error) {
+			return true, fn(store)
+		})
+}
+
+func TestRelayKeepsAggregateOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectRelayLock(store)
+	now := time.Now()
+
+	events := []sqlc.Outbox{
+		{ID: 1, AggregateType: sqlc.AggregateTransfer, AggregateID: "10", EventType: "pb.TransferCreated"},
+		{ID: 2, AggregateType: sqlc.AggregateAccount, AggregateID: "7", EventType: "pb.AccountCreated"},
+		{ID: 3, AggregateType: sqlc.AggregateTransfer, AggregateID: "10", EventType: "pb.TransferCreated"},
+		{ID: 4, AggregateType: sqlc.AggregateTransfer, AggregateID: "11", EventType: "pb.TransferCreated"},
+	}
+	store.EXPECT().
+		ListDueOutboxEvents(gomock.Any(), gomock.Eq(sqlc.ListDueOutboxEventsParams{
+			Now:      now,
+			RowLimit: outboxBatchSize,
+		})).
+		Times(1).
+		Return(events, nil)
+
+	store.EXPECT().
+		MarkOutboxEventFailed(gomock.Any(), gomock.Eq(sqlc.MarkOutboxEventFailedParams{
+			ID:            1,
+			LastError:     "broker is down",
+			NextAttemptAt: now.Add(outboxRetryBaseDelay),
+		})).
+		Times(1).
+		Return(nil)
+	store.EXPECT().MarkOutboxEventPublished(gomock.Any(), gomock.Eq(int64(2))).Times(1).Return(nil)
+	store.EXPECT().MarkOutboxEventPublished(gomock.Any(), gomock.Eq(int64(3))).Times(0)
+	store.EXPECT().MarkOutboxEventPublished(gomock.Any(), gomock.Eq(int64(4))).Times(1).Return(nil)
+
+	publisher := &failingPublisher{fail: map[int64]bool{1: true}}
+	relay := NewOutboxRelay(config.Config{}, store, publisher)
+
+	fetched, err := relay.Relay(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, len(events), fetched)
+	// событие 3 ждёт, пока не опубликуется более раннее событие 1 того же перевода
+	require.Equal(t, []int64{2, 4}, publisher.published)
+}
+
+func TestRelayStopsWhenMarkFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectRelayLock(store)
+
+	store.EXPECT().
+		ListDueOutboxEvents(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]sqlc.Outbox{{ID: 1}, {ID: 2}}, nil)
+	store.EXPECT().MarkOutboxEventPublished(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(errors.New("connection lost"))
+	store.EXPECT().MarkOutboxEventPublished(gomock.Any(), gomock.Eq(int64(2))).Times(0)
+
+	publisher := &failingPublisher{}
+	relay := NewOutboxRelay(config.Config{}, store, publisher)
+
+	_, err := relay.Relay(context.Background(), time.Now())
+	require.Error(t, err)
+	require.Equal(t, []int64{1}, publisher.published)
+}
+
+func TestRelaySkipsWhenLockIsTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	// реле другого экземпляра держит блокировку, fn не вызывается
+	store.EXPECT().OutboxRelayTx(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+	store.EXPECT().ListDueOutboxEvents(gomock.Any(), gomock.Any()).Times(0)
+
+	publisher := &failingPublisher{}
+	relay := NewOutboxRelay(config.Config{}, store, publisher)
+
+	fetched, err := relay.Relay(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Zero(t, fetched)
+	require.Empty(t, publisher.published)
+}
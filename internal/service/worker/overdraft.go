@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+)
+
+const (
+	defaultOverdraftInterestInterval = time.Hour
+	overdraftInterestBatchSize       = 100
+)
+
+// OverdraftInterestJob charges daily interest on accounts with a negative balance.
+// It runs more often than once a day; each account is charged at most once per date.
+type OverdraftInterestJob struct {
+	config config.Config
+	store  sqlc.Store
+}
+
+func NewOverdraftInterestJob(config config.Config, store sqlc.Store) *OverdraftInterestJob {
+	if config.OverdraftInterestInterval <= 0 {
+		config.OverdraftInterestInterval = defaultOverdraftInterestInterval
+	}
+	return &OverdraftInterestJob{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start posts overdraft interest until ctx is cancelled
+func (job *OverdraftInterestJob) Start(ctx context.Context) {
+	every(ctx, job.config.OverdraftInterestInterval, func(now time.Time) {
+		if _, err := job.PostInterest(ctx, now); err != nil {
+			slog.Error("cannot post overdraft interest", "error", err)
+		}
+	})
+}
+
+// PostInterest charges interest for the UTC day of now on every overdrawn account
+// and returns how many accounts were charged
+func (job *OverdraftInterestJob) PostInterest(ctx context.Context, now time.Time) (int, error) {
+	if job.config.OverdraftInterestRateBps <= 0 {
+		return 0, nil
+	}
+
+	date := now.UTC().Truncate(24 * time.Hour)
+	posted := 0
+
+	var afterID int64
+	for {
+		accounts, err := job.store.ListOverdrawnAccounts(ctx, sqlc.ListOverdrawnAccountsParams{
+			AfterID:  afterID,
+			RowLimit: overdraftInterestBatchSize,
+		})
+		if err != nil {
+			return posted, err
+		}
+
+		for _, account := range accounts {
+			result, err := job.store.PostOverdraftInterestTx(ctx, sqlc.PostOverdraftInterestTxParams{
+				AccountID:     account.ID,
+				Date:          date,
+				AnnualRateBps: job.config.OverdraftInterestRateBps,
+			})
+			if err != nil {
+				if !errors.Is(err, sqlc.ErrInterestAlreadyPosted) {
+					slog.Error("cannot post overdraft interest for account", "account_id", account.ID, "error", err)
+				}
+				continue
+			}
+			if result.Posting.Amount > 0 {
+				posted++
+			}
+		}
+
+		if len(accounts) < overdraftInterestBatchSize {
+			return posted, nil
+		}
+		afterID = accounts[len(accounts)-1].ID
+	}
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPostInterest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	date := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListOverdrawnAccounts(gomock.Any(), gomock.Eq(sqlc.ListOverdrawnAccountsParams{
+			AfterID:  0,
+			RowLimit: overdraftInterestBatchSize,
+		})).
+		Times(1).
+		Return([]sqlc.Account{{ID: 1}, {ID: 2}}, nil)
+
+	store.EXPECT().
+		PostOverdraftInterestTx(gomock.Any(), gomock.Eq(sqlc.PostOverdraftInterestTxParams{
+			AccountID:     1,
+			Date:          date,
+			AnnualRateBps: 2000,
+		})).
+		Times(1).
+		Return(sqlc.PostOverdraftInterestTxResult{Posting: sqlc.OverdraftInterestPosting{Amount: 1}}, nil)
+	store.EXPECT().
+		PostOverdraftInterestTx(gomock.Any(), gomock.Eq(sqlc.PostOverdraftInterestTxParams{
+			AccountID:     2,
+			Date:          date,
+			AnnualRateBps: 2000,
+		})).
+		Times(1).
+		Return(sqlc.PostOverdraftInterestTxResult{}, sqlc.ErrInterestAlreadyPosted)
+
+	job := NewOverdraftInterestJob(config.Config{OverdraftInterestRateBps: 2000}, store)
+
+	posted, err := job.PostInterest(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, posted)
+}
+
+func TestPostInterestDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListOverdrawnAccounts(gomock.Any(), gomock.Any()).Times(0)
+
+	job := NewOverdraftInterestJob(config.Config{}, store)
+
+	posted, err := job.PostInterest(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Zero(t, posted)
+}
@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/task"
+)
+
+const defaultReconciliationInterval = time.Hour
+
+// Reconciler checks the ledger invariants and records every violation it finds
+type Reconciler struct {
+	config config.Config
+	store  sqlc.Store
+	// observe gets the discrepancy count of every completed run
+	observe func(discrepancies int64)
+}
+
+type ReconcilerOption func(*Reconciler)
+
+// WithDiscrepancyObserver calls observe with the number of discrepancies found by each completed run
+func WithDiscrepancyObserver(observe func(discrepancies int64)) ReconcilerOption {
+	return func(reconciler *Reconciler) {
+		reconciler.observe = observe
+	}
+}
+
+func NewReconciler(config config.Config, store sqlc.Store, opts ...ReconcilerOption) *Reconciler {
+	if config.ReconciliationInterval <= 0 {
+		config.ReconciliationInterval = defaultReconciliationInterval
+	}
+	reconciler := &Reconciler{
+		config: config,
+		store:  store,
+	}
+	for _, opt := range opts {
+		opt(reconciler)
+	}
+	return reconciler
+}
+
+// Start reconciles the ledger until ctx is cancelled
+func (reconciler *Reconciler) Start(ctx context.Context) {
+	every(ctx, reconciler.config.ReconciliationInterval, func(now time.Time) {
+		run, err := reconciler.Run(ctx)
+		if err != nil {
+			slog.Error("cannot reconcile ledger", "error", err)
+			return
+		}
+		if run.DiscrepancyCount > 0 {
+			slog.Warn("reconciliation found discrepancies", "run_id", run.ID, "discrepancies", run.DiscrepancyCount)
+		}
+	})
+}
+
+// RegisterTasks handles task.ReconcileLedger with this reconciler
+func (reconciler *Reconciler) RegisterTasks(registry *task.Registry) {
+	task.Register(registry, task.ReconcileLedger, func(ctx context.Context, _ struct{}) error {
+		run, err := reconciler.Run(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("requested reconciliation finished", "run_id", run.ID, "discrepancies", run.DiscrepancyCount)
+		return nil
+	})
+}
+
+// Run performs one reconciliation and returns the finished run
+func (reconciler *Reconciler) Run(ctx context.Context) (sqlc.ReconciliationRun, error) {
+	run, err := reconciler.store.CreateReconciliationRun(ctx)
+	if err != nil {
+		return run, err
+	}
+
+	discrepancies, err := reconciler.check(ctx)
+	if err != nil {
+		return reconciler.finish(ctx, run, 0, err)
+	}
+
+	for _, discrepancy := range discrepancies {
+		discrepancy.RunID = run.ID
+		if _, err := reconciler.store.CreateReconciliationDiscrepancy(ctx, discrepancy); err != nil {
+			return reconciler.finish(ctx, run, 0, err)
+		}
+	}
+
+	if reconciler.observe != nil {
+		reconciler.observe(int64(len(discrepancies)))
+	}
+	return reconciler.finish(ctx, run, int64(len(discrepancies)), nil)
+}
+
+func (reconciler *Reconciler) finish(ctx context.Context, run sqlc.ReconciliationRun, count int64, runErr error) (sqlc.ReconciliationRun, error) {
+	arg := sqlc.FinishReconciliationRunParams{
+		ID:               run.ID,
+		Status:           sqlc.ReconciliationCompleted,
+		DiscrepancyCount: count,
+	}
+	if runErr != nil {
+		arg.Status = sqlc.ReconciliationFailed
+		arg.Error = runErr.Error()
+	}
+
+	run, err := reconciler.store.FinishReconciliationRun(ctx, arg)
+	if runErr != nil {
+		return run, runErr
+	}
+	return run, err
+}
+
+// check runs every invariant query. Each query reads a single snapshot,
+// so transfers committed meanwhile can't show up as half-booked.
+func (reconciler *Reconciler) check(ctx context.Context) ([]sqlc.CreateReconciliationDiscrepancyParams, error) {
+	var discrepancies []sqlc.CreateReconciliationDiscrepancyParams
+
+	mismatches, err := reconciler.store.ListBalanceMismatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, mismatch := range mismatches {
+		discrepancies = append(discrepancies, sqlc.CreateReconciliationDiscrepancyParams{
+			Kind:      sqlc.DiscrepancyBalanceMismatch,
+			AccountID: sql.NullInt64{Int64: mismatch.AccountID, Valid: true},
+			Currency:  mismatch.Currency,
+			Expected:  mismatch.EntriesTotal,
+			Actual:    mismatch.Balance,
+		})
+	}
+
+	transfers, err := reconciler.store.ListTransfersMissingEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range transfers {
+		discrepancies = append(discrepancies, sqlc.CreateReconciliationDiscrepancyParams{
+			Kind:       sqlc.DiscrepancyMissingTransferEntry,
+			TransferID: sql.NullInt64{Int64: transfer.TransferID, Valid: true},
+			Currency:   transfer.Currency,
+			Expected:   2,
+			Actual:     transfer.MatchingEntries,
+		})
+	}
+
+	unbalanced, err := reconciler.store.ListUnbalancedTransfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range unbalanced {
+		discrepancies = append(discrepancies, sqlc.CreateReconciliationDiscrepancyParams{
+			Kind:       sqlc.DiscrepancyUnbalancedTransfer,
+			TransferID: transfer.TransferID,
+			Currency:   transfer.Currency,
+			Expected:   0,
+			Actual:     transfer.Total,
+		})
+	}
+
+	entries, err := reconciler.store.ListOrphanedEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		discrepancies = append(discrepancies, sqlc.CreateReconciliationDiscrepancyParams{
+			Kind:      sqlc.DiscrepancyOrphanedEntry,
+			AccountID: sql.NullInt64{Int64: entry.AccountID, Valid: true},
+			EntryID:   sql.NullInt64{Int64: entry.EntryID, Valid: true},
+			Currency:  entry.Currency,
+			Expected:  0,
+			Actual:    entry.Amount,
+		})
+	}
+
+	return discrepancies, nil
+}
@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/task"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconcilerRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	run := sqlc.ReconciliationRun{ID: 7, Status: sqlc.ReconciliationRunning}
+
+	store.EXPECT().CreateReconciliationRun(gomock.Any()).Times(1).Return(run, nil)
+	store.EXPECT().ListBalanceMismatches(gomock.Any()).Times(1).
+		Return([]sqlc.ListBalanceMismatchesRow{{AccountID: 1, Currency: "USD", Balance: 100, EntriesTotal: 90}}, nil)
+	store.EXPECT().ListTransfersMissingEntries(gomock.Any()).Times(1).
+		Return([]sqlc.ListTransfersMissingEntriesRow{}, nil)
+	store.EXPECT().ListUnbalancedTransfers(gomock.Any()).Times(1).
+		Return([]sqlc.ListUnbalancedTransfersRow{}, nil)
+	store.EXPECT().ListOrphanedEntries(gomock.Any()).Times(1).
+		Return([]sqlc.ListOrphanedEntriesRow{{EntryID: 3, AccountID: 1, Currency: "USD", Amount: 10}}, nil)
+
+	store.EXPECT().
+		CreateReconciliationDiscrepancy(gomock.Any(), gomock.Eq(sqlc.CreateReconciliationDiscrepancyParams{
+			RunID:     run.ID,
+			Kind:      sqlc.DiscrepancyBalanceMismatch,
+			AccountID: sql.NullInt64{Int64: 1, Valid: true},
+			Currency:  "USD",
+			Expected:  90,
+			Actual:    100,
+		})).
+		Times(1)
+	store.EXPECT().
+		CreateReconciliationDiscrepancy(gomock.Any(), gomock.Eq(sqlc.CreateReconciliationDiscrepancyParams{
+			RunID:     run.ID,
+			Kind:      sqlc.DiscrepancyOrphanedEntry,
+			AccountID: sql.NullInt64{Int64: 1, Valid: true},
+			EntryID:   sql.NullInt64{Int64: 3, Valid: true},
+			Currency:  "USD",
+			Actual:    10,
+		})).
+		Times(1)
+
+	finished := run
+	finished.Status = sqlc.ReconciliationCompleted
+	finished.DiscrepancyCount = 2
+	store.EXPECT().
+		FinishReconciliationRun(gomock.Any(), gomock.Eq(sqlc.FinishReconciliationRunParams{
+			ID:               run.ID,
+			Status:           sqlc.ReconciliationCompleted,
+			DiscrepancyCount: 2,
+		})).
+		Times(1).
+		Return(finished, nil)
+
+	var observed int64
+	reconciler := NewReconciler(config.Config{}, store, WithDiscrepancyObserver(func(discrepancies int64) {
+		observed = discrepancies
+	}))
+
+	got, err := reconciler.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), got.DiscrepancyCount)
+	require.Equal(t, int64(2), observed)
+}
+
+func TestReconcilerRunFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	run := sqlc.ReconciliationRun{ID: 8}
+	checkErr := errors.New("connection reset")
+
+	store.EXPECT().CreateReconciliationRun(gomock.Any()).Times(1).Return(run, nil)
+	store.EXPECT().ListBalanceMismatches(gomock.Any()).Times(1).Return(nil, checkErr)
+	store.EXPECT().CreateReconciliationDiscrepancy(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().
+		FinishReconciliationRun(gomock.Any(), gomock.Eq(sqlc.FinishReconciliationRunParams{
+			ID:     run.ID,
+			Status: sqlc.ReconciliationFailed,
+			Error:  checkErr.Error(),
+		})).
+		Times(1)
+
+	_, err := NewReconciler(config.Config{}, store).Run(context.Background())
+	require.ErrorIs(t, err, checkErr)
+}
+
+func TestReconcileLedgerTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	queued := sqlc.Task{ID: 4, Type: task.ReconcileLedger.Name, Payload: json.RawMessage(`{}`), Attempts: 1, MaxAttempts: 3}
+
+	store.EXPECT().ClaimTasks(gomock.Any(), gomock.Any()).Times(1).Return([]sqlc.Task{queued}, nil)
+	store.EXPECT().CreateReconciliationRun(gomock.Any()).Times(1).Return(sqlc.ReconciliationRun{ID: 9}, nil)
+	store.EXPECT().ListBalanceMismatches(gomock.Any()).Times(1)
+	store.EXPECT().ListTransfersMissingEntries(gomock.Any()).Times(1)
+	store.EXPECT().ListUnbalancedTransfers(gomock.Any()).Times(1)
+	store.EXPECT().ListOrphanedEntries(gomock.Any()).Times(1)
+	store.EXPECT().FinishReconciliationRun(gomock.Any(), gomock.Any()).Times(1).
+		Return(sqlc.ReconciliationRun{ID: 9, Status: sqlc.ReconciliationCompleted}, nil)
+	store.EXPECT().CompleteTask(gomock.Any(), gomock.Eq(sqlc.CompleteTaskParams{ID: queued.ID, Attempts: 1})).Times(1).Return(int64(1), nil)
+
+	registry := task.NewRegistry()
+	NewReconciler(config.Config{}, store).RegisterTasks(registry)
+
+	pool, err := NewTaskPool(config.Config{}, store, registry)
+	require.NoError(t, err)
+
+	ran, err := pool.RunNext(context.Background(), sqlc.DefaultTaskQueue, time.Now())
+	require.NoError(t, err)
+	require.True(t, ran)
+}
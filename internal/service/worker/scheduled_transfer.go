@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+)
+
+const (
+	defaultScheduledTransferInterval = time.Minute
+	scheduledTransferBatchSize       = 100
+)
+
+// ScheduledTransferRunner periodically executes the scheduled transfers that are due
+type ScheduledTransferRunner struct {
+	config config.Config
+	store  sqlc.Store
+}
+
+func NewScheduledTransferRunner(config config.Config, store sqlc.Store) *ScheduledTransferRunner {
+	if config.ScheduledTransferInterval <= 0 {
+		config.ScheduledTransferInterval = defaultScheduledTransferInterval
+	}
+	if config.ScheduledTransferRetryDelay <= 0 {
+		config.ScheduledTransferRetryDelay = config.ScheduledTransferInterval
+	}
+	return &ScheduledTransferRunner{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start polls for due scheduled transfers until ctx is cancelled
+func (runner *ScheduledTransferRunner) Start(ctx context.Context) {
+	every(ctx, runner.config.ScheduledTransferInterval, func(now time.Time) {
+		if _, err := runner.RunDue(ctx, now); err != nil {
+			slog.Error("cannot run scheduled transfers", "error", err)
+		}
+	})
+}
+
+// RunDue executes every scheduled transfer due at now and returns the number of processed occurrences.
+// A schedule that fails is logged and skipped so it doesn't block the rest of the batch.
+func (runner *ScheduledTransferRunner) RunDue(ctx context.Context, now time.Time) (int, error) {
+	due, err := runner.store.ListDueScheduledTransfers(ctx, sqlc.ListDueScheduledTransfersParams{
+		DueBefore: now,
+		RowLimit:  scheduledTransferBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, scheduled := range due {
+		result, err := runner.store.ExecuteScheduledTransferTx(ctx, sqlc.ExecuteScheduledTransferTxParams{
+			ScheduledTransferID: scheduled.ID,
+			Now:                 now,
+			MaxAttempts:         runner.config.ScheduledTransferMaxAttempts,
+			RetryDelay:          runner.config.ScheduledTransferRetryDelay,
+		})
+		if err != nil {
+			if !errors.Is(err, sqlc.ErrScheduledTransferNotDue) {
+				slog.Error("cannot execute scheduled transfer", "scheduled_transfer_id", scheduled.ID, "error", err)
+			}
+			continue
+		}
+
+		processed++
+		slog.Info("scheduled transfer run",
+			"scheduled_transfer_id", scheduled.ID,
+			"occurrence_at", result.Run.OccurrenceAt,
+			"status", result.Run.Status,
+			"attempt", result.Run.Attempts,
+		)
+	}
+
+	return processed, nil
+}
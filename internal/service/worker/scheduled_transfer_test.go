@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRunDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	now := time.Now()
+
+	due := []sqlc.ScheduledTransfer{{ID: 1}, {ID: 2}, {ID: 3}}
+	store.EXPECT().
+		ListDueScheduledTransfers(gomock.Any(), gomock.Eq(sqlc.ListDueScheduledTransfersParams{
+			DueBefore: now,
+			RowLimit:  scheduledTransferBatchSize,
+		})).
+		Times(1).
+		Return(due, nil)
+
+	store.EXPECT().
+		ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+		Times(len(due)).
+		DoAndReturn(func(_ context.Context, arg sqlc.ExecuteScheduledTransferTxParams) (sqlc.ExecuteScheduledTransferTxResult, error) {
+			require.Equal(t, int64(3), arg.MaxAttempts)
+			require.Equal(t, time.Minute, arg.RetryDelay)
+
+			switch arg.ScheduledTransferID {
+			case 2:
+				return sqlc.ExecuteScheduledTransferTxResult{}, sqlc.ErrScheduledTransferNotDue
+			case 3:
+				return sqlc.ExecuteScheduledTransferTxResult{}, errors.New("connection reset")
+			}
+			return sqlc.ExecuteScheduledTransferTxResult{
+				Run: sqlc.ScheduledTransferRun{Status: sqlc.ScheduledRunSucceeded, Attempts: 1},
+			}, nil
+		})
+
+	runner := NewScheduledTransferRunner(config.Config{
+		ScheduledTransferMaxAttempts: 3,
+		ScheduledTransferRetryDelay:  time.Minute,
+	}, store)
+
+	processed, err := runner.RunDue(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+}
@@ -0,0 +1,185 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/health"
+	"github.com/hisshihi/simple-bank/internal/task"
+)
+
+const (
+	defaultTaskQueues       = sqlc.DefaultTaskQueue + "=4"
+	defaultTaskPollInterval = time.Second
+	defaultTaskLease        = 5 * time.Minute
+	taskRetryBaseDelay      = 5 * time.Second
+	taskRetryMaxDelay       = time.Hour
+)
+
+// TaskPool runs queued tasks with a fixed number of workers per queue.
+// Tasks are claimed with FOR UPDATE SKIP LOCKED, so any number of pools can share the table.
+type TaskPool struct {
+	config   config.Config
+	store    sqlc.Store
+	registry *task.Registry
+	queues   map[string]int
+	health   *health.Component
+}
+
+func NewTaskPool(config config.Config, store sqlc.Store, registry *task.Registry) (*TaskPool, error) {
+	if config.TaskQueues == "" {
+		config.TaskQueues = defaultTaskQueues
+	}
+	if config.TaskPollInterval <= 0 {
+		config.TaskPollInterval = defaultTaskPollInterval
+	}
+	if config.TaskLease <= 0 {
+		config.TaskLease = defaultTaskLease
+	}
+
+	queues, err := config.TaskQueueConcurrency()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskPool{
+		config:   config,
+		store:    store,
+		registry: registry,
+		queues:   queues,
+	}, nil
+}
+
+// ReportTo makes the pool report whether it can reach the task table
+func (pool *TaskPool) ReportTo(component *health.Component) {
+	pool.health = component
+}
+
+// Start runs the workers until ctx is cancelled and waits for the running tasks to finish
+func (pool *TaskPool) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for queue, concurrency := range pool.queues {
+		for range concurrency {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pool.work(ctx, queue)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func (pool *TaskPool) work(ctx context.Context, queue string) {
+	for {
+		ran, err := pool.RunNext(ctx, queue, time.Now())
+		if err != nil {
+			slog.Error("cannot run task", "queue", queue, "error", err)
+		}
+		// пока в очереди есть задачи, берём следующую сразу
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pool.config.TaskPollInterval):
+		}
+	}
+}
+
+// RunNext claims one task of the queue due at now and runs it, ran is false when none was due
+func (pool *TaskPool) RunNext(ctx context.Context, queue string, now time.Time) (ran bool, err error) {
+	tasks, err := pool.store.ClaimTasks(ctx, sqlc.ClaimTasksParams{
+		Queue:       queue,
+		Now:         now,
+		LockedUntil: sql.NullTime{Time: now.Add(pool.config.TaskLease), Valid: true},
+		RowLimit:    1,
+	})
+	if err != nil {
+		pool.health.Unhealthy(fmt.Errorf("cannot claim tasks: %w", err))
+		return false, err
+	}
+	pool.health.Healthy()
+
+	if len(tasks) == 0 {
+		return false, nil
+	}
+
+	return true, pool.run(ctx, tasks[0])
+}
+
+// run executes the task and records the outcome: done, retried with backoff or dead.
+// The outcome is only written while the task is still ours: running with the attempt we
+// claimed. Once the lease expires another worker may claim it, and its outcome wins
+func (pool *TaskPool) run(ctx context.Context, t sqlc.Task) error {
+	runErr := pool.execute(ctx, t)
+
+	// итог записываем и при остановке пула, иначе задача ждала бы истечения аренды
+	ctx = context.WithoutCancel(ctx)
+
+	var (
+		rows int64
+		err  error
+	)
+	switch {
+	case runErr == nil:
+		rows, err = pool.store.CompleteTask(ctx, sqlc.CompleteTaskParams{
+			ID:       t.ID,
+			Attempts: t.Attempts,
+		})
+	case errors.Is(runErr, task.ErrSkipRetry) || t.Attempts >= t.MaxAttempts:
+		slog.Error("task is dead", "task_id", t.ID, "type", t.Type, "attempts", t.Attempts, "error", runErr)
+		rows, err = pool.store.KillTask(ctx, sqlc.KillTaskParams{
+			ID:        t.ID,
+			Attempts:  t.Attempts,
+			LastError: runErr.Error(),
+		})
+	default:
+		rows, err = pool.store.RetryTask(ctx, sqlc.RetryTaskParams{
+			ID:        t.ID,
+			Attempts:  t.Attempts,
+			RunAt:     time.Now().Add(backoff(taskRetryBaseDelay, taskRetryMaxDelay, t.Attempts)),
+			LastError: runErr.Error(),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		slog.Warn("task lease lost, outcome discarded", "task_id", t.ID, "type", t.Type, "attempts", t.Attempts, "error", runErr)
+	}
+	return nil
+}
+
+func (pool *TaskPool) execute(ctx context.Context, t sqlc.Task) (err error) {
+	// задача, пережившая падение воркера, приходит снова с уже исчерпанными попытками
+	if t.Attempts > t.MaxAttempts {
+		return fmt.Errorf("%w: worker lease expired on the last attempt", task.ErrSkipRetry)
+	}
+
+	handler, ok := pool.registry.Handler(t.Type)
+	if !ok {
+		return fmt.Errorf("%w: no handler for task type %q", task.ErrSkipRetry, t.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+
+	// задача должна закончиться до конца аренды, иначе её возьмёт другой воркер
+	ctx, cancel := context.WithTimeout(ctx, pool.config.TaskLease)
+	defer cancel()
+
+	return handler(ctx, t.Payload)
+}
@@ -0,0 +1,169 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/task"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type echo struct {
+	Fail string `json:"fail"`
+}
+
+var echoTask = task.Type[echo]{Name: "echo"}
+
+func TestRunNext(t *testing.T) {
+	registry := task.NewRegistry()
+	task.Register(registry, echoTask, func(ctx context.Context, payload echo) error {
+		switch payload.Fail {
+		case "retry":
+			return errors.New("try later")
+		case "skip":
+			return fmt.Errorf("bad input: %w", task.ErrSkipRetry)
+		case "panic":
+			panic("boom")
+		}
+		return nil
+	})
+
+	newTask := func(typ, fail string, attempts int64) sqlc.Task {
+		payload, err := json.Marshal(echo{Fail: fail})
+		require.NoError(t, err)
+		return sqlc.Task{ID: 1, Type: typ, Payload: payload, Attempts: attempts, MaxAttempts: 3}
+	}
+
+	testCases := []struct {
+		name       string
+		task       sqlc.Task
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Succeeded",
+			task: newTask("echo", "", 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CompleteTask(gomock.Any(), gomock.Eq(sqlc.CompleteTaskParams{ID: 1, Attempts: 1})).Times(1).Return(int64(1), nil)
+			},
+		},
+		{
+			name: "Retried",
+			task: newTask("echo", "retry", 2),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RetryTask(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg sqlc.RetryTaskParams) (int64, error) {
+						require.Equal(t, int64(2), arg.Attempts)
+						require.Equal(t, "try later", arg.LastError)
+						require.WithinDuration(t, time.Now().Add(2*taskRetryBaseDelay), arg.RunAt, time.Second)
+						return 1, nil
+					})
+			},
+		},
+		{
+			name: "PanicRetried",
+			task: newTask("echo", "panic", 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RetryTask(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg sqlc.RetryTaskParams) (int64, error) {
+						require.Contains(t, arg.LastError, "boom")
+						return 1, nil
+					})
+			},
+		},
+		{
+			name: "DeadAfterLastAttempt",
+			task: newTask("echo", "retry", 3),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().KillTask(gomock.Any(), gomock.Eq(sqlc.KillTaskParams{ID: 1, Attempts: 3, LastError: "try later"})).Times(1).Return(int64(1), nil)
+			},
+		},
+		{
+			name: "SkipRetry",
+			task: newTask("echo", "skip", 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().KillTask(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+		},
+		{
+			name: "UnknownType",
+			task: newTask("unknown", "", 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().KillTask(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+		},
+		{
+			// аренда истекла посреди работы, задачу уже взял другой воркер
+			name: "LeaseLost",
+			task: newTask("echo", "", 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CompleteTask(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+			},
+		},
+		{
+			name: "LeaseExpiredOnLastAttempt",
+			task: newTask("echo", "", 4),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CompleteTask(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().KillTask(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			now := time.Now()
+
+			store.EXPECT().
+				ClaimTasks(gomock.Any(), gomock.Eq(sqlc.ClaimTasksParams{
+					Queue:       sqlc.DefaultTaskQueue,
+					Now:         now,
+					LockedUntil: sql.NullTime{Time: now.Add(defaultTaskLease), Valid: true},
+					RowLimit:    1,
+				})).
+				Times(1).
+				Return([]sqlc.Task{tc.task}, nil)
+			tc.buildStubs(store)
+
+			pool, err := NewTaskPool(config.Config{}, store, registry)
+			require.NoError(t, err)
+
+			ran, err := pool.RunNext(context.Background(), sqlc.DefaultTaskQueue, now)
+			require.NoError(t, err)
+			require.True(t, ran)
+		})
+	}
+}
+
+func TestRunNextEmptyQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ClaimTasks(gomock.Any(), gomock.Any()).Times(1).Return([]sqlc.Task{}, nil)
+
+	pool, err := NewTaskPool(config.Config{}, store, task.NewRegistry())
+	require.NoError(t, err)
+
+	ran, err := pool.RunNext(context.Background(), sqlc.DefaultTaskQueue, time.Now())
+	require.NoError(t, err)
+	require.False(t, ran)
+}
+
+func TestNewTaskPoolRejectsBadQueues(t *testing.T) {
+	_, err := NewTaskPool(config.Config{TaskQueues: "default=0"}, nil, task.NewRegistry())
+	require.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package worker
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/egress"
+	"github.com/hisshihi/simple-bank/internal/event"
+)
+
+const (
+	defaultWebhookDeliveryInterval     = 10 * time.Second
+	defaultWebhookMaxAttempts          = 8
+	defaultWebhookDisableAfterFailures = 20
+	webhookBatchSize                   = 100
+	webhookTimeout                     = 10 * time.Second
+	webhookRetryBaseDelay              = 30 * time.Second
+	webhookRetryMaxDelay               = 6 * time.Hour
+	// webhookClaimLease hides claimed deliveries from other dispatchers, it outlasts a batch
+	// in which every endpoint times out
+	webhookClaimLease = 2 * webhookBatchSize * webhookTimeout
+)
+
+// WebhookDispatcher posts queued webhook deliveries to the subscribers' endpoints
+type WebhookDispatcher struct {
+	config config.Config
+	store  sqlc.Store
+	client *http.Client
+}
+
+func NewWebhookDispatcher(config config.Config, store sqlc.Store) *WebhookDispatcher {
+	if config.WebhookDeliveryInterval <= 0 {
+		config.WebhookDeliveryInterval = defaultWebhookDeliveryInterval
+	}
+	if config.WebhookMaxAttempts <= 0 {
+		config.WebhookMaxAttempts = defaultWebhookMaxAttempts
+	}
+	if config.WebhookDisableAfterFailures <= 0 {
+		config.WebhookDisableAfterFailures = defaultWebhookDisableAfterFailures
+	}
+	return &WebhookDispatcher{
+		config: config,
+		store:  store,
+		client: egress.NewClient(webhookTimeout),
+	}
+}
+
+// Start delivers webhooks until ctx is cancelled
+func (dispatcher *WebhookDispatcher) Start(ctx context.Context) {
+	every(ctx, dispatcher.config.WebhookDeliveryInterval, func(now time.Time) {
+		if _, err := dispatcher.Deliver(ctx, now); err != nil {
+			slog.Error("cannot deliver webhooks", "error", err)
+		}
+	})
+}
+
+// Deliver claims the deliveries due at now, attempts them and returns how many succeeded.
+// Several dispatchers can run at once, each claims its own rows; a delivery claimed by a
+// dispatcher that died becomes due again when the lease runs out
+func (dispatcher *WebhookDispatcher) Deliver(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := dispatcher.store.ClaimDueWebhookDeliveries(ctx, sqlc.ClaimDueWebhookDeliveriesParams{
+		Now:        now,
+		RowLimit:   webhookBatchSize,
+		LeaseUntil: now.Add(webhookClaimLease),
+	})
+	if err != nil {
+		return 0, err
+	}
+	// UPDATE ... RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(deliveries, func(a, b sqlc.ClaimDueWebhookDeliveriesRow) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	// подписка могла отключиться на предыдущей доставке этой же пачки
+	disabled := make(map[int64]bool)
+	delivered := 0
+	for _, delivery := range deliveries {
+		if disabled[delivery.SubscriptionID] {
+			continue
+		}
+
+		status, err := dispatcher.post(ctx, delivery, now)
+		if err == nil {
+			delivered++
+			if err := dispatcher.succeed(ctx, delivery, status, now); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		subscription, err := dispatcher.fail(ctx, delivery, status, err, now)
+		if err != nil {
+			return delivered, err
+		}
+		if subscription.Status == sqlc.WebhookDisabled {
+			disabled[subscription.ID] = true
+			slog.Warn("webhook disabled after failures in a row", "webhook_id", subscription.ID, "failures", subscription.FailureCount)
+		}
+	}
+
+	return delivered, nil
+}
+
+// post sends the signed payload and returns the response status, zero when there was no response.
+// The response body is never read: the endpoint is chosen by the user and the error ends up in
+// the delivery log
+func (dispatcher *WebhookDispatcher) post(ctx context.Context, delivery sqlc.ClaimDueWebhookDeliveriesRow, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Event-ID", delivery.EventID)
+	req.Header.Set(event.WebhookSignatureHeader, event.SignWebhook(delivery.Secret, now, delivery.Payload))
+
+	rsp, err := dispatcher.client.Do(req)
+	if err != nil {
+		return 0, deliveryError(err)
+	}
+	rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return rsp.StatusCode, fmt.Errorf("endpoint responded with status %d", rsp.StatusCode)
+	}
+	return rsp.StatusCode, nil
+}
+
+// deliveryError keeps only the kind of a transport error, its text names the addresses and
+// ports the dispatcher tried
+func deliveryError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, egress.ErrForbiddenAddress):
+		return egress.ErrForbiddenAddress
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return errors.New("request timed out")
+	default:
+		return errors.New("cannot connect to endpoint")
+	}
+}
+
+func (dispatcher *WebhookDispatcher) succeed(ctx context.Context, delivery sqlc.ClaimDueWebhookDeliveriesRow, status int, now time.Time) error {
+	_, err := dispatcher.store.UpdateWebhookDelivery(ctx, sqlc.UpdateWebhookDeliveryParams{
+		ID:             delivery.ID,
+		Status:         sqlc.WebhookDeliverySucceeded,
+		NextAttemptAt:  now,
+		ResponseStatus: int64(status),
+		DeliveredAt:    sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return dispatcher.store.RecordWebhookSuccess(ctx, delivery.SubscriptionID)
+}
+
+// fail schedules a retry with exponential backoff or gives up after the last attempt,
+// and counts the failure against the subscription
+func (dispatcher *WebhookDispatcher) fail(ctx context.Context, delivery sqlc.ClaimDueWebhookDeliveriesRow, status int, deliveryErr error, now time.Time) (sqlc.WebhookSubscription, error) {
+	attempts := delivery.Attempts + 1
+
+	arg := sqlc.UpdateWebhookDeliveryParams{
+		ID:             delivery.ID,
+		Status:         sqlc.WebhookDeliveryPending,
+		NextAttemptAt:  now.Add(backoff(webhookRetryBaseDelay, webhookRetryMaxDelay, attempts)),
+		ResponseStatus: int64(status),
+		LastError:      deliveryErr.Error(),
+	}
+	if attempts >= dispatcher.config.WebhookMaxAttempts {
+		arg.Status = sqlc.WebhookDeliveryFailed
+	}
+
+	if _, err := dispatcher.store.UpdateWebhookDelivery(ctx, arg); err != nil {
+		return sqlc.WebhookSubscription{}, err
+	}
+
+	return dispatcher.store.RecordWebhookFailure(ctx, sqlc.RecordWebhookFailureParams{
+		ID:           delivery.SubscriptionID,
+		DisableAfter: dispatcher.config.WebhookDisableAfterFailures,
+	})
+}
@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/hisshihi/simple-bank/db/mock"
+	"github.com/hisshihi/simple-bank/db/sqlc"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/hisshihi/simple-bank/internal/egress"
+	"github.com/hisshihi/simple-bank/internal/event"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeliverSignsPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	now := time.Now()
+	payload := json.RawMessage(`{"id":"event-1","type":"transfer.received"}`)
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, string(payload), string(body))
+		require.Equal(t, sqlc.WebhookTransferReceived, r.Header.Get("X-Webhook-Event"))
+		require.NoError(t, event.VerifyWebhookSignature("whsec_1", r.Header.Get(event.WebhookSignatureHeader), body, time.Minute, now))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer endpoint.Close()
+
+	claim := sqlc.ClaimDueWebhookDeliveriesParams{
+		Now:        now,
+		RowLimit:   webhookBatchSize,
+		LeaseUntil: now.Add(webhookClaimLease),
+	}
+	store.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Eq(claim)).Times(1).
+		Return([]sqlc.ClaimDueWebhookDeliveriesRow{{
+			ID:             1,
+			SubscriptionID: 5,
+			EventID:        "event-1",
+			EventType:      sqlc.WebhookTransferReceived,
+			Payload:        payload,
+			Url:            endpoint.URL,
+			Secret:         "whsec_1",
+		}}, nil)
+	store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg sqlc.UpdateWebhookDeliveryParams) (sqlc.WebhookDelivery, error) {
+			require.Equal(t, sqlc.WebhookDeliverySucceeded, arg.Status)
+			require.Equal(t, int64(http.StatusNoContent), arg.ResponseStatus)
+			require.True(t, arg.DeliveredAt.Valid)
+			return sqlc.WebhookDelivery{}, nil
+		})
+	store.EXPECT().RecordWebhookSuccess(gomock.Any(), gomock.Eq(int64(5))).Times(1).Return(nil)
+
+	dispatcher := NewWebhookDispatcher(config.Config{}, store)
+	// httptest слушает loopback, который настоящий клиент не пропускает
+	dispatcher.client = endpoint.Client()
+	delivered, err := dispatcher.Deliver(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+}
+
+func TestDeliverRetriesAndDisables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	now := time.Now()
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer endpoint.Close()
+
+	// пачка приходит не по порядку, как из UPDATE ... RETURNING
+	store.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).
+		Return([]sqlc.ClaimDueWebhookDeliveriesRow{
+			// подписка 6 отключится на доставке 2, доставка 3 ждёт повторного включения
+			{ID: 3, SubscriptionID: 6, Payload: json.RawMessage(`{}`), Url: endpoint.URL},
+			{ID: 1, SubscriptionID: 5, Attempts: 1, Payload: json.RawMessage(`{}`), Url: endpoint.URL},
+			{ID: 2, SubscriptionID: 6, Attempts: 2, Payload: json.RawMessage(`{}`), Url: endpoint.URL},
+		}, nil)
+
+	store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg sqlc.UpdateWebhookDeliveryParams) (sqlc.WebhookDelivery, error) {
+			require.Equal(t, int64(1), arg.ID)
+			require.Equal(t, sqlc.WebhookDeliveryPending, arg.Status)
+			require.Equal(t, now.Add(2*webhookRetryBaseDelay), arg.NextAttemptAt)
+			require.Equal(t, int64(http.StatusInternalServerError), arg.ResponseStatus)
+			// тело ответа не сохраняется
+			require.Equal(t, "endpoint responded with status 500", arg.LastError)
+			return sqlc.WebhookDelivery{}, nil
+		})
+	store.EXPECT().RecordWebhookFailure(gomock.Any(), gomock.Eq(sqlc.RecordWebhookFailureParams{ID: 5, DisableAfter: 2})).
+		Times(1).
+		Return(sqlc.WebhookSubscription{ID: 5, Status: sqlc.WebhookActive, FailureCount: 1}, nil)
+
+	store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg sqlc.UpdateWebhookDeliveryParams) (sqlc.WebhookDelivery, error) {
+			require.Equal(t, int64(2), arg.ID)
+			// третья попытка последняя
+			require.Equal(t, sqlc.WebhookDeliveryFailed, arg.Status)
+			return sqlc.WebhookDelivery{}, nil
+		})
+	store.EXPECT().RecordWebhookFailure(gomock.Any(), gomock.Eq(sqlc.RecordWebhookFailureParams{ID: 6, DisableAfter: 2})).
+		Times(1).
+		Return(sqlc.WebhookSubscription{ID: 6, Status: sqlc.WebhookDisabled, FailureCount: 2}, nil)
+
+	dispatcher := NewWebhookDispatcher(config.Config{WebhookMaxAttempts: 3, WebhookDisableAfterFailures: 2}, store)
+	dispatcher.client = endpoint.Client()
+	delivered, err := dispatcher.Deliver(context.Background(), now)
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+}
+
+func TestDeliverRefusesPrivateAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	now := time.Now()
+
+	called := false
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer endpoint.Close()
+
+	// адрес мог пройти проверку при создании, а потом DNS стал указывать внутрь
+	store.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).
+		Return([]sqlc.ClaimDueWebhookDeliveriesRow{{ID: 1, SubscriptionID: 5, Payload: json.RawMessage(`{}`), Url: endpoint.URL}}, nil)
+	store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg sqlc.UpdateWebhookDeliveryParams) (sqlc.WebhookDelivery, error) {
+			require.Zero(t, arg.ResponseStatus)
+			require.Equal(t, egress.ErrForbiddenAddress.Error(), arg.LastError)
+			return sqlc.WebhookDelivery{}, nil
+		})
+	store.EXPECT().RecordWebhookFailure(gomock.Any(), gomock.Any()).Times(1).
+		Return(sqlc.WebhookSubscription{ID: 5, Status: sqlc.WebhookActive, FailureCount: 1}, nil)
+
+	delivered, err := NewWebhookDispatcher(config.Config{}, store).Deliver(context.Background(), now)
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+	require.False(t, called)
+}
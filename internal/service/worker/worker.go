@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"context"
+	"time"
+)
+
+// every calls fn right away and then on each tick of interval until ctx is cancelled
+func every(ctx context.Context, interval time.Duration, fn func(now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// backoff doubles base with every failed attempt after the first one, up to max
+func backoff(base, max time.Duration, attempts int64) time.Duration {
+	delay := base
+	for i := int64(1); i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
@@ -0,0 +1,15 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Second, backoff(time.Second, time.Minute, 1))
+	require.Equal(t, 2*time.Second, backoff(time.Second, time.Minute, 2))
+	require.Equal(t, 8*time.Second, backoff(time.Second, time.Minute, 4))
+	require.Equal(t, time.Minute, backoff(time.Second, time.Minute, 100))
+}
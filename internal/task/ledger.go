@@ -0,0 +1,5 @@
+package task
+
+// ReconcileLedger runs one ledger reconciliation, so an admin can check the ledger without
+// waiting for the next scheduled run. The worker registers its handler
+var ReconcileLedger = Type[struct{}]{Name: "ledger.reconcile", MaxAttempts: 3}
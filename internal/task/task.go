@@ -0,0 +1,83 @@
+// Package task defines the background tasks run by the worker pool. A task type is declared once
+// with the payload it carries, enqueued with Type.Enqueue and handled by the function registered for it.
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hisshihi/simple-bank/db/sqlc"
+)
+
+// ErrSkipRetry makes a failed task dead right away, wrap it for errors no retry can fix
+var ErrSkipRetry = errors.New("skip retry")
+
+// Type is a task type with the payload T
+type Type[T any] struct {
+	Name string
+	// Queue defaults to sqlc.DefaultTaskQueue
+	Queue string
+	// MaxAttempts defaults to 10
+	MaxAttempts int64
+}
+
+// Enqueue adds the task through q, which may be the Queries of an open transaction
+func (t Type[T]) Enqueue(ctx context.Context, q sqlc.Querier, payload T) (sqlc.Task, error) {
+	return t.EnqueueAt(ctx, q, payload, time.Time{})
+}
+
+// EnqueueAt adds the task to run no earlier than runAt
+func (t Type[T]) EnqueueAt(ctx context.Context, q sqlc.Querier, payload T, runAt time.Time) (sqlc.Task, error) {
+	return sqlc.EnqueueTask(ctx, q, sqlc.EnqueueTaskParams{
+		Queue:       t.Queue,
+		Type:        t.Name,
+		Payload:     payload,
+		MaxAttempts: t.MaxAttempts,
+		RunAt:       runAt,
+	})
+}
+
+// Handler runs a task from its JSON payload
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Registry maps task type names to their handlers
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+// Register sets the handler of the task type, it panics when the type already has one
+func Register[T any](registry *Registry, t Type[T], fn func(ctx context.Context, payload T) error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.handlers[t.Name]; ok {
+		panic(fmt.Sprintf("task: handler for %q registered twice", t.Name))
+	}
+
+	registry.handlers[t.Name] = func(ctx context.Context, raw json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			// повтор не исправит payload, который не разбирается
+			return fmt.Errorf("%w: cannot decode payload: %v", ErrSkipRetry, err)
+		}
+		return fn(ctx, payload)
+	}
+}
+
+// Handler returns the handler of the task type
+func (registry *Registry) Handler(name string) (Handler, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	handler, ok := registry.handlers[name]
+	return handler, ok
+}
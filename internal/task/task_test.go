@@ -0,0 +1,40 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+var greet = Type[greeting]{Name: "greet"}
+
+func TestRegister(t *testing.T) {
+	registry := NewRegistry()
+
+	var got greeting
+	Register(registry, greet, func(ctx context.Context, payload greeting) error {
+		got = payload
+		return nil
+	})
+
+	handler, ok := registry.Handler(greet.Name)
+	require.True(t, ok)
+	require.NoError(t, handler(context.Background(), json.RawMessage(`{"name":"bob"}`)))
+	require.Equal(t, "bob", got.Name)
+
+	err := handler(context.Background(), json.RawMessage(`{"name":1}`))
+	require.ErrorIs(t, err, ErrSkipRetry)
+
+	_, ok = registry.Handler("unknown")
+	require.False(t, ok)
+
+	require.Panics(t, func() {
+		Register(registry, greet, func(ctx context.Context, payload greeting) error { return nil })
+	})
+}
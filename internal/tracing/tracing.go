@@ -0,0 +1,139 @@
+// Package tracing sets up OpenTelemetry tracing. A request is traced from the HTTP or gRPC
+// server through the handler down to every database query, and the trace context of the
+// caller is continued, so the spans join the caller's trace.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+const serviceName = "simple-bank"
+
+// Setup installs the global tracer provider and propagator for the configured exporter.
+// The returned function flushes the spans still buffered and must be called before exit
+func Setup(ctx context.Context, config config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		// глобальный провайдер по умолчанию ничего не записывает
+		return func(context.Context) error { return nil }, nil
+	}
+
+	ratio := config.TracingSampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			semconv.DeploymentEnvironment(config.ENV),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, config config.Config) (sdktrace.SpanExporter, error) {
+	switch config.TracingExporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		if config.TracingFile == "" {
+			return nil, fmt.Errorf("TRACING_FILE is required for the file exporter")
+		}
+		file, err := os.OpenFile(config.TracingFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open trace file: %w", err)
+		}
+		// по одному JSON-спану на строку
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &closingExporter{SpanExporter: exporter, closer: file}, nil
+	case ExporterOTLP:
+		// без TRACING_OTLP_ENDPOINT экспортер читает стандартные переменные OTEL_EXPORTER_OTLP_*
+		var opts []otlptracegrpc.Option
+		if config.TracingOTLPEndpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(config.TracingOTLPEndpoint))
+		}
+		if config.TracingOTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.TracingExporter)
+	}
+}
+
+// closingExporter closes the trace file after the last spans are written to it
+type closingExporter struct {
+	sdktrace.SpanExporter
+	closer io.Closer
+}
+
+func (exporter *closingExporter) Shutdown(ctx context.Context) error {
+	err := exporter.SpanExporter.Shutdown(ctx)
+	if closeErr := exporter.closer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// GatewayMethodAnnotator names the gateway's HTTP span after the gRPC method the request is routed to.
+// The gateway calls the gRPC server in process, so the span covers the method and its database queries
+func GatewayMethodAnnotator(ctx context.Context, r *http.Request) metadata.MD {
+	fullMethod, ok := runtime.RPCMethod(ctx)
+	if !ok {
+		return nil
+	}
+
+	// "/pb.SimpleBank/LoginUser" называется так же, как спан otelgrpc
+	name := strings.TrimPrefix(fullMethod, "/")
+	span := trace.SpanFromContext(ctx)
+	span.SetName(name)
+
+	if service, method, ok := strings.Cut(name, "/"); ok {
+		span.SetAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(method))
+	}
+	return nil
+}
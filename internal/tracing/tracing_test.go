@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/hisshihi/simple-bank/internal/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewExporter(t *testing.T) {
+	exporter, err := newExporter(context.Background(), config.Config{})
+	require.NoError(t, err)
+	require.Nil(t, exporter)
+
+	_, err = newExporter(context.Background(), config.Config{TracingExporter: ExporterFile})
+	require.EqualError(t, err, "TRACING_FILE is required for the file exporter")
+
+	_, err = newExporter(context.Background(), config.Config{TracingExporter: "jaeger"})
+	require.EqualError(t, err, `unknown tracing exporter "jaeger"`)
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.jsonl")
+	t.Cleanup(func() { otel.SetTracerProvider(sdktrace.NewTracerProvider()) })
+
+	shutdown, err := Setup(context.Background(), config.Config{
+		TracingExporter: ExporterFile,
+		TracingFile:     file,
+	})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "TransferTx")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	var exported struct{ Name string }
+	require.NoError(t, json.Unmarshal(data, &exported))
+	require.Equal(t, "TransferTx", exported.Name)
+}
+
+func TestGatewayMethodAnnotator(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	mux := runtime.NewServeMux(runtime.WithMetadata(GatewayMethodAnnotator))
+	err := mux.HandlePath(http.MethodPost, "/v1/login_user", func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		// так же вызывает аннотаторы сгенерированный обработчик шлюза
+		_, err := runtime.AnnotateIncomingContext(r.Context(), mux, r, "/pb.SimpleBank/LoginUser")
+		require.NoError(t, err)
+	})
+	require.NoError(t, err)
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "gateway")
+	request := httptest.NewRequest(http.MethodPost, "/v1/login_user", nil).WithContext(ctx)
+	mux.ServeHTTP(httptest.NewRecorder(), request)
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "pb.SimpleBank/LoginUser", spans[0].Name())
+}
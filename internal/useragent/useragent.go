@@ -0,0 +1,118 @@
+// Package useragent turns a User-Agent header into what a person recognises in a session
+// list: the kind of device, the OS and the browser. It knows the common browsers and API
+// clients and leaves the fields it can't tell empty rather than guessing.
+package useragent
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceOther   = "other"
+)
+
+// UserAgent is the parsed header, the versions are major versions only
+type UserAgent struct {
+	Device         string `json:"device"`
+	OS             string `json:"os"`
+	OSVersion      string `json:"os_version"`
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version"`
+}
+
+type product struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// browsers are checked in order: Edge, Opera and the others also send Chrome/ and Safari/,
+// so the more specific tokens come first
+var browsers = []product{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)},
+	{"Opera", regexp.MustCompile(`OPR/(\d+)`)},
+	{"Yandex Browser", regexp.MustCompile(`YaBrowser/(\d+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/(\d+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`)},
+	{"Safari", regexp.MustCompile(`Version/(\d+).*Safari/`)},
+	{"curl", regexp.MustCompile(`^curl/(\d+)`)},
+	{"Postman", regexp.MustCompile(`PostmanRuntime/(\d+)`)},
+	{"gRPC", regexp.MustCompile(`grpc-[a-z-]+/(\d+)`)},
+	{"Go HTTP client", regexp.MustCompile(`^Go-http-client/(\d+)`)},
+}
+
+var (
+	windowsPattern = regexp.MustCompile(`Windows NT (\d+\.\d+)`)
+	iosPattern     = regexp.MustCompile(`(?:iPhone|CPU) OS (\d+)`)
+	macPattern     = regexp.MustCompile(`Mac OS X (\d+)[_.](\d+)`)
+	androidPattern = regexp.MustCompile(`Android (\d+)`)
+	botPattern     = regexp.MustCompile(`(?i)bot\b|crawler|spider|slurp|headless`)
+)
+
+// windowsVersions maps NT versions to marketing names, Windows 11 still reports 10.0
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+}
+
+// Parse reads header, an empty header gives DeviceOther and empty fields
+func Parse(header string) UserAgent {
+	ua := UserAgent{}
+
+	for _, browser := range browsers {
+		if match := browser.pattern.FindStringSubmatch(header); match != nil {
+			ua.Browser, ua.BrowserVersion = browser.name, match[1]
+			break
+		}
+	}
+
+	// iOS проверяется раньше macOS: iPad и iPhone пишут «like Mac OS X»
+	switch {
+	case strings.Contains(header, "Windows"):
+		ua.OS = "Windows"
+		if match := windowsPattern.FindStringSubmatch(header); match != nil {
+			ua.OSVersion = windowsVersions[match[1]]
+		}
+	case strings.Contains(header, "iPhone") || strings.Contains(header, "iPad"):
+		ua.OS = "iOS"
+		if match := iosPattern.FindStringSubmatch(header); match != nil {
+			ua.OSVersion = match[1]
+		}
+	case strings.Contains(header, "Android"):
+		ua.OS = "Android"
+		if match := androidPattern.FindStringSubmatch(header); match != nil {
+			ua.OSVersion = match[1]
+		}
+	case strings.Contains(header, "Macintosh"):
+		ua.OS = "macOS"
+		if match := macPattern.FindStringSubmatch(header); match != nil {
+			ua.OSVersion = match[1] + "." + match[2]
+		}
+	case strings.Contains(header, "CrOS"):
+		ua.OS = "ChromeOS"
+	case strings.Contains(header, "Linux"):
+		ua.OS = "Linux"
+	}
+
+	switch {
+	case botPattern.MatchString(header):
+		ua.Device = DeviceBot
+	case strings.Contains(header, "iPad"), ua.OS == "Android" && !strings.Contains(header, "Mobile"):
+		ua.Device = DeviceTablet
+	case ua.OS == "iOS", ua.OS == "Android", strings.Contains(header, "Mobile"):
+		ua.Device = DeviceMobile
+	case ua.OS != "":
+		ua.Device = DeviceDesktop
+	default:
+		ua.Device = DeviceOther
+	}
+
+	return ua
+}
@@ -0,0 +1,70 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+		ua     UserAgent
+	}{
+		{
+			"ChromeWindows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.129 Safari/537.36",
+			UserAgent{DeviceDesktop, "Windows", "10", "Chrome", "120"},
+		},
+		{
+			"EdgeWindows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			UserAgent{DeviceDesktop, "Windows", "10", "Edge", "120"},
+		},
+		{
+			"SafariMac",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			UserAgent{DeviceDesktop, "macOS", "10.15", "Safari", "17"},
+		},
+		{
+			"FirefoxLinux",
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			UserAgent{DeviceDesktop, "Linux", "", "Firefox", "121"},
+		},
+		{
+			"SafariIPhone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			UserAgent{DeviceMobile, "iOS", "17", "Safari", "17"},
+		},
+		{
+			"ChromeIPad",
+			"Mozilla/5.0 (iPad; CPU OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			UserAgent{DeviceTablet, "iOS", "17", "Chrome", "120"},
+		},
+		{
+			"YandexAndroidPhone",
+			"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.5993.117 YaBrowser/23.11.4.117.00 SA/3 Mobile Safari/537.36",
+			UserAgent{DeviceMobile, "Android", "14", "Yandex Browser", "23"},
+		},
+		{
+			"AndroidTablet",
+			"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			UserAgent{DeviceTablet, "Android", "13", "Chrome", "120"},
+		},
+		{
+			"Googlebot",
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			UserAgent{DeviceBot, "", "", "", ""},
+		},
+		{"Curl", "curl/8.5.0", UserAgent{DeviceOther, "", "", "curl", "8"}},
+		{"GRPC", "grpc-go/1.71.0", UserAgent{DeviceOther, "", "", "gRPC", "1"}},
+		{"Empty", "", UserAgent{DeviceOther, "", "", "", ""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.ua, Parse(tc.header))
+		})
+	}
+}
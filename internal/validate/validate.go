@@ -0,0 +1,107 @@
+// Package validate holds the field rules shared by the Gin binding tags and the gRPC handlers,
+// so both APIs accept the same requests and report violations in the same shape.
+package validate
+
+import (
+	"net/mail"
+	"regexp"
+
+	"github.com/hisshihi/simple-bank/internal/i18n"
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+	// имена бывают с дефисом, апострофом и не только латиницей
+	fullNamePattern = regexp.MustCompile(`^[\p{L}][\p{L}\s'.\-]*$`)
+)
+
+// fieldMessages is the catalog section of the field messages, apperr reports a RuleError
+// as a field violation of its validation_failed code
+const fieldMessages = "validation_failed.field."
+
+// RuleError is a broken rule, Message is the id of a field message, "min_length",
+// and Args fill in the verbs of its text
+type RuleError struct {
+	Message string
+	Args    []any
+}
+
+// Error is the English text of the message
+func (e *RuleError) Error() string {
+	return i18n.Translate(i18n.English, fieldMessages+e.Message, e.Args...)
+}
+
+func ruleError(message string, args ...any) error {
+	return &RuleError{Message: message, Args: args}
+}
+
+// Length checks that value has between min and max characters
+func Length(value string, min, max int) error {
+	n := len([]rune(value))
+	switch {
+	case n == 0:
+		return ruleError("required")
+	case n < min:
+		return ruleError("min_length", min)
+	case n > max:
+		return ruleError("max_length", max)
+	}
+	return nil
+}
+
+func Username(value string) error {
+	if err := Length(value, 3, 100); err != nil {
+		return err
+	}
+	if !usernamePattern.MatchString(value) {
+		return ruleError("username")
+	}
+	return nil
+}
+
+func FullName(value string) error {
+	if err := Length(value, 1, 100); err != nil {
+		return err
+	}
+	if !fullNamePattern.MatchString(value) {
+		return ruleError("full_name")
+	}
+	return nil
+}
+
+func Email(value string) error {
+	if err := Length(value, 3, 200); err != nil {
+		return err
+	}
+	if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+		return ruleError("email")
+	}
+	return nil
+}
+
+// Password checks a new password, bcrypt ignores everything past 72 bytes
+func Password(value string) error {
+	if err := Length(value, 6, 72); err != nil {
+		return err
+	}
+	if len(value) > 72 {
+		return ruleError("max_bytes", 72)
+	}
+	return nil
+}
+
+// ID checks a database id
+func ID(value int64) error {
+	if value < 1 {
+		return ruleError("positive")
+	}
+	return nil
+}
+
+// NotNegative checks a position or count that starts at zero
+func NotNegative(value int64) error {
+	if value < 0 {
+		return ruleError("not_negative")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{"Username", Username("john_doe-1.2"), ""},
+		{"UsernameEmpty", Username(""), "is required"},
+		{"UsernameShort", Username("jo"), "must be at least 3 characters"},
+		{"UsernameSpace", Username("john doe"), "must contain only letters, digits, underscore, dot or hyphen"},
+		{"FullName", FullName("Анна-Мария O'Neil"), ""},
+		{"FullNameDigits", FullName("R2D2"), "must contain only letters, spaces, apostrophe, dot or hyphen"},
+		{"FullNameLong", FullName(strings.Repeat("a", 101)), "must be at most 100 characters"},
+		{"Email", Email("john@example.com"), ""},
+		{"EmailWithName", Email("John <john@example.com>"), "must be a valid email"},
+		{"EmailInvalid", Email("john"), "must be a valid email"},
+		{"Password", Password("secret"), ""},
+		{"PasswordShort", Password("12345"), "must be at least 6 characters"},
+		{"PasswordBytes", Password(strings.Repeat("я", 40)), "must be at most 72 bytes"},
+		{"ID", ID(1), ""},
+		{"IDZero", ID(0), "must be greater than 0"},
+		{"NotNegative", NotNegative(0), ""},
+		{"Negative", NotNegative(-1), "must not be negative"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.message == "" {
+				require.NoError(t, tc.err)
+				return
+			}
+			require.EqualError(t, tc.err, tc.message)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package watch
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AccountChangedChannel is the Postgres channel TransferTx notifies with the id of every changed account
+const AccountChangedChannel = "account_changed"
+
+const (
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval also bounds how late a missed notification is noticed
+	listenerPingInterval = 90 * time.Second
+)
+
+// Hub fans account change notifications out to the streams watching the accounts.
+// A notification only wakes a stream up, the stream reads the new entries itself,
+// so a notification lost during a reconnect costs nothing but latency.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[int64]map[chan struct{}]struct{}
+	closed      bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[int64]map[chan struct{}]struct{})}
+}
+
+// Subscribe returns a channel signalled when any of the accounts changes and a function to unsubscribe.
+// Signals are coalesced, a stream that is busy sending gets one signal for all changes meanwhile.
+// The channel is closed when the hub is closed.
+func (hub *Hub) Subscribe(accountIDs []int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	hub.mu.Lock()
+	if hub.closed {
+		hub.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	for _, id := range accountIDs {
+		if hub.subscribers[id] == nil {
+			hub.subscribers[id] = make(map[chan struct{}]struct{})
+		}
+		hub.subscribers[id][ch] = struct{}{}
+	}
+	hub.mu.Unlock()
+
+	return ch, func() {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+
+		if hub.closed {
+			return
+		}
+		for _, id := range accountIDs {
+			delete(hub.subscribers[id], ch)
+			if len(hub.subscribers[id]) == 0 {
+				delete(hub.subscribers, id)
+			}
+		}
+	}
+}
+
+// Notify wakes up the streams watching the account
+func (hub *Hub) Notify(accountID int64) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for ch := range hub.subscribers[accountID] {
+		signal(ch)
+	}
+}
+
+// notifyAll wakes up every stream, used when notifications may have been missed
+func (hub *Hub) notifyAll() {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for _, subscribers := range hub.subscribers {
+		for ch := range subscribers {
+			signal(ch)
+		}
+	}
+}
+
+// Close ends every subscription, so the streams return and servers can stop gracefully
+func (hub *Hub) Close() {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if hub.closed {
+		return
+	}
+	hub.closed = true
+
+	closed := make(map[chan struct{}]bool)
+	for _, subscribers := range hub.subscribers {
+		for ch := range subscribers {
+			if !closed[ch] {
+				close(ch)
+				closed[ch] = true
+			}
+		}
+	}
+	hub.subscribers = nil
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Listen feeds the hub from LISTEN account_changed on its own connection until ctx is cancelled,
+// then closes the hub
+func (hub *Hub) Listen(ctx context.Context, dbSource string) error {
+	defer hub.Close()
+
+	listener := pq.NewListener(dbSource, listenerMinReconnect, listenerMaxReconnect, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Error("account listener failed", "error", err)
+		}
+	})
+	defer listener.Close()
+
+	// Listen ждёт первого подключения, закрытие слушателя прерывает ожидание
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+
+	if err := listener.Listen(AccountChangedChannel); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-listener.Notify:
+			if !ok {
+				return nil
+			}
+			// nil приходит после переподключения, уведомления за это время потеряны
+			if n == nil {
+				hub.notifyAll()
+				continue
+			}
+
+			accountID, err := strconv.ParseInt(n.Extra, 10, 64)
+			if err != nil {
+				slog.Warn("account listener got a bad payload", "payload", n.Extra)
+				continue
+			}
+			hub.Notify(accountID)
+		case <-ticker.C:
+			if err := listener.Ping(); err != nil {
+				slog.Warn("account listener ping failed", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func received(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHubNotify(t *testing.T) {
+	hub := NewHub()
+
+	ch1, unsubscribe1 := hub.Subscribe([]int64{1, 2})
+	ch2, unsubscribe2 := hub.Subscribe([]int64{2})
+	defer unsubscribe2()
+
+	hub.Notify(1)
+	require.True(t, received(ch1))
+	require.False(t, received(ch2))
+
+	// несколько уведомлений подряд схлопываются в один сигнал
+	hub.Notify(2)
+	hub.Notify(2)
+	require.True(t, received(ch1))
+	require.False(t, received(ch1))
+	require.True(t, received(ch2))
+
+	unsubscribe1()
+	hub.Notify(1)
+	hub.Notify(2)
+	require.False(t, received(ch1))
+	require.True(t, received(ch2))
+
+	hub.notifyAll()
+	require.True(t, received(ch2))
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub()
+
+	ch, unsubscribe := hub.Subscribe([]int64{1, 2})
+	hub.Close()
+	unsubscribe()
+
+	_, ok := <-ch
+	require.False(t, ok)
+
+	// после закрытия новые подписки сразу закрыты
+	ch, _ = hub.Subscribe([]int64{1})
+	_, ok = <-ch
+	require.False(t, ok)
+
+	hub.Notify(1)
+	hub.notifyAll()
+	hub.Close()
+}
@@ -0,0 +1,218 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: rpc_watch_account.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WatchAccountRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// accounts to watch, all accounts of the caller when empty
+	AccountIds []int64 `protobuf:"varint,1,rep,packed,name=account_ids,json=accountIds,proto3" json:"account_ids,omitempty"`
+	// entries up to and including this id are not sent again, in every watched account
+	AfterEntryId int64 `protobuf:"varint,2,opt,name=after_entry_id,json=afterEntryId,proto3" json:"after_entry_id,omitempty"`
+	// resume_token of the last response received, it overrides after_entry_id
+	// for the accounts it lists
+	ResumeToken   string `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAccountRequest) Reset() {
+	*x = WatchAccountRequest{}
+	mi := &file_rpc_watch_account_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAccountRequest) ProtoMessage() {}
+
+func (x *WatchAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_watch_account_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAccountRequest.ProtoReflect.Descriptor instead.
+func (*WatchAccountRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_watch_account_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WatchAccountRequest) GetAccountIds() []int64 {
+	if x != nil {
+		return x.AccountIds
+	}
+	return nil
+}
+
+func (x *WatchAccountRequest) GetAfterEntryId() int64 {
+	if x != nil {
+		return x.AfterEntryId
+	}
+	return 0
+}
+
+func (x *WatchAccountRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+type WatchAccountResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Entry *Entry                 `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	// account state at the time the entry is sent
+	Account *Account `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+	// position of the stream in every watched account after this entry,
+	// pass it back to resume without missing entries
+	ResumeToken   string `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAccountResponse) Reset() {
+	*x = WatchAccountResponse{}
+	mi := &file_rpc_watch_account_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAccountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAccountResponse) ProtoMessage() {}
+
+func (x *WatchAccountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_watch_account_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAccountResponse.ProtoReflect.Descriptor instead.
+func (*WatchAccountResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_watch_account_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WatchAccountResponse) GetEntry() *Entry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *WatchAccountResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *WatchAccountResponse) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+var File_rpc_watch_account_proto protoreflect.FileDescriptor
+
+const file_rpc_watch_account_proto_rawDesc = "" +
+	"\n" +
+	"\x17rpc_watch_account.proto\x12\x02pb\x1a\raccount.proto\"\x7f\n" +
+	"\x13WatchAccountRequest\x12\x1f\n" +
+	"\vaccount_ids\x18\x01 \x03(\x03R\n" +
+	"accountIds\x12$\n" +
+	"\x0eafter_entry_id\x18\x02 \x01(\x03R\fafterEntryId\x12!\n" +
+	"\fresume_token\x18\x03 \x01(\tR\vresumeToken\"\x81\x01\n" +
+	"\x14WatchAccountResponse\x12\x1f\n" +
+	"\x05entry\x18\x01 \x01(\v2\t.pb.EntryR\x05entry\x12%\n" +
+	"\aaccount\x18\x02 \x01(\v2\v.pb.AccountR\aaccount\x12!\n" +
+	"\fresume_token\x18\x03 \x01(\tR\vresumeTokenB$Z\"github.com/hisshihi/simple-bank/pbb\x06proto3"
+
+var (
+	file_rpc_watch_account_proto_rawDescOnce sync.Once
+	file_rpc_watch_account_proto_rawDescData []byte
+)
+
+func file_rpc_watch_account_proto_rawDescGZIP() []byte {
+	file_rpc_watch_account_proto_rawDescOnce.Do(func() {
+		file_rpc_watch_account_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_watch_account_proto_rawDesc), len(file_rpc_watch_account_proto_rawDesc)))
+	})
+	return file_rpc_watch_account_proto_rawDescData
+}
+
+var file_rpc_watch_account_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_watch_account_proto_goTypes = []any{
+	(*WatchAccountRequest)(nil),  // 0: pb.WatchAccountRequest
+	(*WatchAccountResponse)(nil), // 1: pb.WatchAccountResponse
+	(*Entry)(nil),                // 2: pb.Entry
+	(*Account)(nil),              // 3: pb.Account
+}
+var file_rpc_watch_account_proto_depIdxs = []int32{
+	2, // 0: pb.WatchAccountResponse.entry:type_name -> pb.Entry
+	3, // 1: pb.WatchAccountResponse.account:type_name -> pb.Account
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_rpc_watch_account_proto_init() }
+func file_rpc_watch_account_proto_init() {
+	if File_rpc_watch_account_proto != nil {
+		return
+	}
+	file_account_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_watch_account_proto_rawDesc), len(file_rpc_watch_account_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_watch_account_proto_goTypes,
+		DependencyIndexes: file_rpc_watch_account_proto_depIdxs,
+		MessageInfos:      file_rpc_watch_account_proto_msgTypes,
+	}.Build()
+	File_rpc_watch_account_proto = out.File
+	file_rpc_watch_account_proto_goTypes = nil
+	file_rpc_watch_account_proto_depIdxs = nil
+}
@@ -26,28 +26,33 @@ var File_service_simple_bank_proto protoreflect.FileDescriptor
 
 const file_service_simple_bank_proto_rawDesc = "" +
 	"\n" +
-	"\x19service_simple_bank.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto2\xc7\x02\n" +
+	"\x19service_simple_bank.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto\x1a\x17rpc_watch_account.proto2\x8e\x03\n" +
 	"\n" +
 	"SimpleBank\x12\x90\x01\n" +
 	"\n" +
 	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"S\x92A6\x12\x10Create new user.\x1a\"Use this API to create a new user.\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/create_user\x12\xa5\x01\n" +
-	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"k\x92AO\x12\vLogin user.\x1a@Use this API to loign user and get access token & refresh token.\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_userB|\x92AU\x12S\n" +
+	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"k\x92AO\x12\vLogin user.\x1a@Use this API to loign user and get access token & refresh token.\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_user\x12E\n" +
+	"\fWatchAccount\x12\x17.pb.WatchAccountRequest\x1a\x18.pb.WatchAccountResponse\"\x000\x01B|\x92AU\x12S\n" +
 	"\x0fSimple Bank API\";\n" +
 	"\bHisshihi\x12\x1bhttps://github.com/hisshihi\x1a\x12hissoffc@gmail.com2\x031.1Z\"github.com/hisshihi/simple-bank/pbb\x06proto3"
 
 var file_service_simple_bank_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),  // 0: pb.CreateUserRequest
-	(*LoginUserRequest)(nil),   // 1: pb.LoginUserRequest
-	(*CreateUserResponse)(nil), // 2: pb.CreateUserResponse
-	(*LoginUserResponse)(nil),  // 3: pb.LoginUserResponse
+	(*CreateUserRequest)(nil),    // 0: pb.CreateUserRequest
+	(*LoginUserRequest)(nil),     // 1: pb.LoginUserRequest
+	(*WatchAccountRequest)(nil),  // 2: pb.WatchAccountRequest
+	(*CreateUserResponse)(nil),   // 3: pb.CreateUserResponse
+	(*LoginUserResponse)(nil),    // 4: pb.LoginUserResponse
+	(*WatchAccountResponse)(nil), // 5: pb.WatchAccountResponse
 }
 var file_service_simple_bank_proto_depIdxs = []int32{
 	0, // 0: pb.SimpleBank.CreateUser:input_type -> pb.CreateUserRequest
 	1, // 1: pb.SimpleBank.LoginUser:input_type -> pb.LoginUserRequest
-	2, // 2: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
-	3, // 3: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
+	2, // 2: pb.SimpleBank.WatchAccount:input_type -> pb.WatchAccountRequest
+	3, // 3: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
+	4, // 4: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
+	5, // 5: pb.SimpleBank.WatchAccount:output_type -> pb.WatchAccountResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -60,6 +65,7 @@ func file_service_simple_bank_proto_init() {
 	}
 	file_rpc_create_user_proto_init()
 	file_rpc_login_user_proto_init()
+	file_rpc_watch_account_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
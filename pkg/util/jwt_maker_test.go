@@ -14,12 +14,13 @@ func TestJWTMaker(t *testing.T) {
 	require.NoError(t, err)
 
 	username := gofakeit.Username()
+	role := DepositorRole
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, duration)
+	token, payload, err := maker.CreateToken(username, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -30,6 +31,7 @@ func TestJWTMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -39,7 +41,7 @@ func TestExpiredToken(t *testing.T) {
 	maker, err := NewJWTMaker(gofakeit.Sentence(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(gofakeit.Username(), -time.Minute)
+	token, payload, err := maker.CreateToken(gofakeit.Username(), DepositorRole, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -52,7 +54,7 @@ func TestExpiredToken(t *testing.T) {
 
 // TestInvalidToken checks if the token is invalid
 func TestInvalidJWTTokenAlgNone(t *testing.T) {
-	payload, err := NewPayload(gofakeit.Username(), time.Minute)
+	payload, err := NewPayload(gofakeit.Username(), DepositorRole, time.Minute)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ParseRecurrence parses a standard 5-field cron expression or a descriptor such as @monthly
+func ParseRecurrence(spec string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("неверное расписание %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// NextOccurrence returns the first occurrence of spec strictly after the given time, in UTC
+func NextOccurrence(spec string, after time.Time) (time.Time, error) {
+	schedule, err := ParseRecurrence(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(after.UTC()), nil
+}
+
+// FirstOccurrence returns the first occurrence of spec at or after start
+func FirstOccurrence(spec string, start time.Time) (time.Time, error) {
+	return NextOccurrence(spec, start.Add(-time.Nanosecond))
+}
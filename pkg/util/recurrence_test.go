@@ -0,0 +1,36 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	after := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC)
+
+	next, err := NextOccurrence("0 9 1 * *", after)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC), next)
+
+	next, err = NextOccurrence("@daily", after)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC), next)
+}
+
+func TestFirstOccurrence(t *testing.T) {
+	start := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	first, err := FirstOccurrence("0 9 1 * *", start)
+	require.NoError(t, err)
+	require.Equal(t, start, first)
+}
+
+func TestParseRecurrenceInvalid(t *testing.T) {
+	_, err := ParseRecurrence("every monday")
+	require.Error(t, err)
+
+	_, err = NextOccurrence("61 * * * *", time.Now())
+	require.Error(t, err)
+}
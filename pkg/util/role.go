@@ -0,0 +1,8 @@
+package util
+
+// Roles a user can have
+const (
+	DepositorRole = "depositor"
+	BankerRole    = "banker"
+	AdminRole     = "admin"
+)